@@ -8,13 +8,24 @@ import (
 	"github.com/vlasdash/redditclone/config"
 	"github.com/vlasdash/redditclone/init/db"
 	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/limiter"
+	"github.com/vlasdash/redditclone/internal/mail"
+	"github.com/vlasdash/redditclone/internal/message"
+	"github.com/vlasdash/redditclone/internal/notification"
+	"github.com/vlasdash/redditclone/internal/oauth"
 	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/search"
 	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/twofactor"
 	"github.com/vlasdash/redditclone/internal/user"
 	"github.com/vlasdash/redditclone/pkg/handlers"
 	"github.com/vlasdash/redditclone/pkg/middleware"
 	"html/template"
 	"net/http"
+	"os"
+	"time"
 )
 
 const ConfigPath = "./config/"
@@ -26,7 +37,7 @@ func main() {
 
 	err := config.LoadConfig(ConfigPath)
 	if err != nil {
-		contextLogger.Fatal("read config failed: %v\n", err)
+		contextLogger.Fatalf("read config failed: %v\n", err)
 		return
 	}
 
@@ -49,39 +60,253 @@ func main() {
 		}
 	}()
 
-	generator := &session.JWTGenerator{}
+	accessLifetime := time.Duration(config.C.App.AccessTokenLifetimeMinute) * time.Minute
+	refreshLifetime := time.Duration(config.C.App.RefreshTokenLifetimeHour) * time.Hour
+	signingKeys := make([]*session.SigningKey, 0, len(config.C.JWT.Keys))
+	for _, k := range config.C.JWT.Keys {
+		var activeFrom time.Time
+		if k.ActiveFrom != "" {
+			activeFrom, err = time.Parse(time.RFC3339, k.ActiveFrom)
+			if err != nil {
+				contextLogger.Fatalf("bad active_from of signing key %s: %v\n", k.ID, err)
+				return
+			}
+		}
+		key, err := session.LoadSigningKey(k.ID, k.Algorithm, k.Path, activeFrom)
+		if err != nil {
+			contextLogger.Fatalf("load signing key %s failed: %v\n", k.ID, err)
+			return
+		}
+		signingKeys = append(signingKeys, key)
+	}
+	if len(signingKeys) == 0 {
+		if !config.C.JWT.AllowTemporaryKey {
+			contextLogger.Fatal("no signing keys configured, set jwt.keys or jwt.allow_temporary_key for development")
+			return
+		}
+		contextLogger.Warn("no signing keys configured, tokens are signed with a temporary key and die on restart")
+		key, err := session.GenerateSigningKey("temporary")
+		if err != nil {
+			contextLogger.Fatal(err)
+			return
+		}
+		signingKeys = append(signingKeys, key)
+	}
+	keySet, err := session.NewKeySet(signingKeys, time.Duration(config.C.JWT.GracePeriodMinute)*time.Minute)
+	if err != nil {
+		contextLogger.Fatal(err)
+		return
+	}
+	generator := session.NewJWTGenerator(keySet, accessLifetime)
 	hasher := &user.BcryptHasher{}
 	userRepo := user.NewMySQLRepo(mysqlDB)
 	sessionRepo := session.NewMySQLRepo(mysqlDB, generator)
+	refreshRepo := session.NewMySQLRefreshRepo(mysqlDB, refreshLifetime)
+	resetRepo := user.NewMySQLResetRepo(mysqlDB, time.Duration(config.C.App.ResetTokenLifetimeMinute)*time.Minute)
+	twoFactorRepo := twofactor.NewMySQLTwoFactorRepo(mysqlDB)
+	challengeRepo := twofactor.NewMySQLChallengeRepo(mysqlDB, twofactor.DefaultChallengeLifetime)
 	postRepo := post.NewMongoRepo(mongoDB)
 	commentRepo := comment.NewMongoRepo(mongoDB)
-	sessionManager := session.NewManager(sessionRepo, userRepo)
+	communityRepo := community.NewMongoRepo(mongoDB)
+	modLogRepo := community.NewMongoModLogRepo(mongoDB)
+	reportRepo := community.NewMongoReportRepo(mongoDB)
+	subscriptionRepo := community.NewMongoSubscriptionRepo(mongoDB)
+	searcher := search.NewMongoSearcher(mongoDB)
+	messageRepo := message.NewMySQLRepo(mysqlDB)
+	notificationRepo := notification.NewMongoRepo(mongoDB)
+	hub := event.NewHub(event.DefaultBufferSize)
+	apiKeyRepo := session.NewMySQLAPIKeyRepo(mysqlDB)
+	clientRepo := oauth.NewMySQLClientRepo(mysqlDB)
+	codeRepo := oauth.NewMySQLCodeRepo(mysqlDB, oauth.DefaultCodeLifetime)
+	oauthTokenRepo := oauth.NewMySQLTokenRepo(mysqlDB, oauth.DefaultAccessTokenLifetime, refreshLifetime)
+	attemptRepo := limiter.NewMySQLRepo(mysqlDB)
+	loginLimiter := limiter.NewLimiter(attemptRepo, "login", limiter.LoginPolicies)
+	registerLimiter := limiter.NewLimiter(attemptRepo, "register", limiter.RegisterPolicies)
+	resetLimiter := limiter.NewLimiter(attemptRepo, "reset", limiter.ResetPolicies)
+	sessionManager := session.NewManager(sessionRepo, userRepo, apiKeyRepo, oauthTokenRepo)
+
+	var mailer mail.Mailer
+	if config.C.Mail.Host != "" {
+		mailer = mail.NewSMTPMailer(config.C.Mail.Host, config.C.Mail.Port, config.C.Mail.User, config.C.Mail.Password, config.C.Mail.From)
+	} else {
+		mailLog, err := os.OpenFile(config.C.Mail.LogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			contextLogger.Fatal(err)
+			return
+		}
+		defer mailLog.Close()
+		mailer = mail.NewLogMailer(mailLog)
+	}
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			err := attemptRepo.Prune(time.Now().Add(-limiter.Retention))
+			if err != nil {
+				contextLogger.Error("unable prune login attempts: ", err)
+			}
+		}
+	}()
+
+	err = community.EnsureDefaults(communityRepo)
+	if err != nil {
+		contextLogger.Fatal(err)
+		return
+	}
 
-	authorizationHandler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
-	postHandler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	missingAdmins, err := user.EnsureAdmins(userRepo, config.C.App.Admins)
+	if err != nil {
+		contextLogger.Fatal(err)
+		return
+	}
+	for _, username := range missingAdmins {
+		contextLogger.Warnf("admin %s isn't registered yet, restart after registration to promote", username)
+	}
+
+	err = searcher.EnsureIndex()
+	if err != nil {
+		contextLogger.Fatal(err)
+		return
+	}
+
+	err = postRepo.EnsureIndexes()
+	if err != nil {
+		contextLogger.Fatal(err)
+		return
+	}
+
+	authorizationHandler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, loginLimiter, registerLimiter)
+	postHandler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+	communityHandler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+	moderationHandler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, hub, contextLogger)
+	messageHandler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo, userRepo, contextLogger)
+	passwordResetHandler := handlers.NewPasswordResetHandler(userRepo, resetRepo, sessionRepo, refreshRepo, hasher, mailer, config.C.App.ResetURL, contextLogger, resetLimiter)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyRepo, contextLogger)
+	jwksHandler := handlers.NewJWKSHandler(keySet, contextLogger)
+	adminHandler := handlers.NewAdminHandler(userRepo, contextLogger)
+	oauthHandler := handlers.NewOAuthHandler(clientRepo, codeRepo, oauthTokenRepo, contextLogger)
+	twoFactorHandler := handlers.NewTwoFactorHandler(userRepo, twoFactorRepo, hasher, contextLogger)
+	userHandler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, apiKeyRepo, twoFactorRepo, clientRepo, oauthTokenRepo)
+	streamHandler := handlers.NewStreamHandler(hub, contextLogger)
+	searchHandler := handlers.NewSearchHandler(searcher, userRepo, contextLogger)
+	reportHandler := handlers.NewReportHandler(reportRepo, postRepo, contextLogger)
 	homepageHandler := handlers.NewHomepageHandler(tmpl, contextLogger)
 	authenticationMiddleware := middleware.NewAuthenticationMiddleware(sessionManager, contextLogger)
 
+	identify := func(h http.HandlerFunc) http.Handler {
+		return authenticationMiddleware.Identify(h)
+	}
+	scoped := func(scope session.Scope, h http.HandlerFunc) http.Handler {
+		return authenticationMiddleware.RequireScope(scope, h)
+	}
+
 	r := mux.NewRouter()
 	fileServer := http.StripPrefix("/static/", http.FileServer(http.Dir("static/")))
 	r.PathPrefix("/static/").Handler(fileServer).Methods("GET")
+	r.HandleFunc("/.well-known/jwks.json", jwksHandler.Get).Methods("GET")
 	r.HandleFunc("/api/login", authorizationHandler.Login).Methods("POST")
+	r.HandleFunc("/api/login/2fa", authorizationHandler.LoginTwoFactor).Methods("POST")
 	r.HandleFunc("/api/register", authorizationHandler.Register).Methods("POST")
-	r.HandleFunc("/api/posts/", postHandler.GetList).Methods("GET")
-	r.HandleFunc("/api/post/{id}", postHandler.GetPost).Methods("GET")
-	r.HandleFunc("/api/posts/{category}", postHandler.GetByCategory).Methods("GET")
-	r.HandleFunc("/api/user/{username}", postHandler.GetByUsername).Methods("GET")
+	r.HandleFunc("/api/token/refresh", authorizationHandler.Refresh).Methods("POST")
+	r.HandleFunc("/api/oauth/token", oauthHandler.Token).Methods("POST")
+	r.HandleFunc("/api/oauth/revoke", oauthHandler.Revoke).Methods("POST")
+	r.HandleFunc("/api/password/forgot", passwordResetHandler.Forgot).Methods("POST")
+	r.HandleFunc("/api/password/reset", passwordResetHandler.Reset).Methods("POST")
+	r.Handle("/api/posts/", identify(postHandler.GetList)).Methods("GET")
+	r.Handle("/api/feed", identify(postHandler.Feed)).Methods("GET")
+	r.HandleFunc("/api/search", searchHandler.Search).Methods("GET")
+	r.Handle("/api/stream", identify(streamHandler.Stream)).Methods("GET")
+	r.Handle("/api/post/{id}", identify(postHandler.GetPost)).Methods("GET")
+	r.Handle("/api/post/{id}/comments/{comment_id}", identify(postHandler.GetCommentThread)).Methods("GET")
+	r.HandleFunc("/api/post/{id}/revisions", postHandler.GetRevisions).Methods("GET")
+	r.HandleFunc("/api/post/{id}/{comment_id}/revisions", postHandler.GetCommentRevisions).Methods("GET")
+	r.Handle("/api/posts/{category}", identify(postHandler.GetByCategory)).Methods("GET")
+	r.Handle("/api/user/{username}", identify(postHandler.GetByUsername)).Methods("GET")
+	r.HandleFunc("/api/user/{username}/profile", userHandler.GetProfile).Methods("GET")
+	r.Handle("/api/user/{username}/comments", identify(postHandler.GetCommentsByUsername)).Methods("GET")
+	r.Handle("/api/user/{username}/overview", identify(postHandler.GetOverview)).Methods("GET")
+	r.HandleFunc("/api/communities", communityHandler.GetList).Methods("GET")
+	r.HandleFunc("/api/community/{name}", communityHandler.Get).Methods("GET")
 
 	s := r.PathPrefix("/api").Subrouter()
-	s.HandleFunc("/posts", postHandler.Add).Methods("POST")
-	s.HandleFunc("/post/{id}", postHandler.AddComment).Methods("POST")
-	s.HandleFunc("/post/{id}/{comment_id}", postHandler.DeleteComment).Methods("DELETE")
-	s.HandleFunc("/post/{id}", postHandler.Delete).Methods("DELETE")
-	s.HandleFunc("/post/{id}/upvote", postHandler.Upvote).Methods("GET")
-	s.HandleFunc("/post/{id}/downvote", postHandler.Downvote).Methods("GET")
-	s.HandleFunc("/post/{id}/unvote", postHandler.Unvote).Methods("GET")
+	s.HandleFunc("/logout", authorizationHandler.Logout).Methods("POST")
+	s.HandleFunc("/logout/all", authorizationHandler.LogoutAll).Methods("POST")
+	s.HandleFunc("/me", userHandler.DeleteAccount).Methods("DELETE")
+	s.HandleFunc("/me/profile", userHandler.UpdateProfile).Methods("PUT")
+	s.HandleFunc("/me/password", userHandler.ChangePassword).Methods("POST")
+	s.HandleFunc("/me/email", userHandler.UpdateEmail).Methods("PUT")
+	s.HandleFunc("/me/2fa/enroll", twoFactorHandler.Enroll).Methods("POST")
+	s.HandleFunc("/me/2fa/confirm", twoFactorHandler.Confirm).Methods("POST")
+	s.HandleFunc("/me/2fa", twoFactorHandler.Disable).Methods("DELETE")
+	s.HandleFunc("/sessions", authorizationHandler.GetSessions).Methods("GET")
+	s.HandleFunc("/keys", apiKeyHandler.Create).Methods("POST")
+	s.HandleFunc("/keys", apiKeyHandler.GetAll).Methods("GET")
+	s.HandleFunc("/keys/{id}", apiKeyHandler.Delete).Methods("DELETE")
+	s.HandleFunc("/oauth/clients", oauthHandler.RegisterClient).Methods("POST")
+	s.HandleFunc("/oauth/clients", oauthHandler.GetClients).Methods("GET")
+	s.HandleFunc("/oauth/clients/{id}", oauthHandler.DeleteClient).Methods("DELETE")
+	s.HandleFunc("/oauth/authorize", oauthHandler.GetAuthorize).Methods("GET")
+	s.HandleFunc("/oauth/authorize", oauthHandler.Authorize).Methods("POST")
+	s.HandleFunc("/oauth/authorizations/{client_id}", oauthHandler.RevokeAuthorization).Methods("DELETE")
+	s.HandleFunc("/communities", communityHandler.Create).Methods("POST")
+	s.HandleFunc("/community/{name}", communityHandler.UpdateSettings).Methods("PUT")
+	s.HandleFunc("/community/{name}/subscribe", communityHandler.Subscribe).Methods("POST")
+	s.HandleFunc("/community/{name}/subscribe", communityHandler.Unsubscribe).Methods("DELETE")
+	s.Handle("/subscriptions", scoped(session.ScopeRead, communityHandler.GetSubscriptions)).Methods("GET")
+	s.HandleFunc("/community/{name}/moderators", moderationHandler.AddModerator).Methods("POST")
+	s.HandleFunc("/community/{name}/moderators/{username}", moderationHandler.RemoveModerator).Methods("DELETE")
+	s.HandleFunc("/community/{name}/bans", moderationHandler.Ban).Methods("POST")
+	s.HandleFunc("/community/{name}/bans/{username}", moderationHandler.Unban).Methods("DELETE")
+	s.HandleFunc("/community/{name}/modlog", moderationHandler.GetModLog).Methods("GET")
+	s.HandleFunc("/community/{name}/reports", moderationHandler.GetReports).Methods("GET")
+	s.HandleFunc("/mod/post/{id}/approve", moderationHandler.ApprovePost).Methods("POST")
+	s.HandleFunc("/mod/post/{id}/{comment_id}/approve", moderationHandler.ApproveComment).Methods("POST")
+	s.HandleFunc("/mod/post/{id}/remove", moderationHandler.RemovePost).Methods("POST")
+	s.HandleFunc("/mod/post/{id}/lock", moderationHandler.Lock).Methods("POST")
+	s.HandleFunc("/mod/post/{id}/unlock", moderationHandler.Unlock).Methods("POST")
+	s.HandleFunc("/mod/post/{id}/pin", moderationHandler.Pin).Methods("POST")
+	s.HandleFunc("/mod/post/{id}/unpin", moderationHandler.Unpin).Methods("POST")
+	s.HandleFunc("/mod/post/{id}/{comment_id}/remove", moderationHandler.RemoveComment).Methods("POST")
+	s.HandleFunc("/messages", messageHandler.Send).Methods("POST")
+	s.HandleFunc("/messages/inbox", messageHandler.GetInbox).Methods("GET")
+	s.HandleFunc("/messages/sent", messageHandler.GetSent).Methods("GET")
+	s.HandleFunc("/messages/{id}", messageHandler.GetThread).Methods("GET")
+	s.HandleFunc("/messages/{id}", messageHandler.Delete).Methods("DELETE")
+	s.HandleFunc("/messages/{id}/reply", messageHandler.Reply).Methods("POST")
+	s.HandleFunc("/messages/{id}/read", messageHandler.MarkRead).Methods("POST")
+	s.Handle("/notifications", scoped(session.ScopeRead, notificationHandler.GetAll)).Methods("GET")
+	s.Handle("/notifications/unread", scoped(session.ScopeRead, notificationHandler.CountUnread)).Methods("GET")
+	s.HandleFunc("/notifications/read", notificationHandler.MarkAllRead).Methods("POST")
+	s.HandleFunc("/notifications/{id}/read", notificationHandler.MarkRead).Methods("POST")
+	s.Handle("/posts", scoped(session.ScopePost, postHandler.Add)).Methods("POST")
+	s.Handle("/post/{id}", scoped(session.ScopeComment, postHandler.AddComment)).Methods("POST")
+	s.Handle("/post/{id}/{comment_id}", scoped(session.ScopeComment, postHandler.DeleteComment)).Methods("DELETE")
+	s.Handle("/post/{id}/{comment_id}/reply", scoped(session.ScopeComment, postHandler.AddReply)).Methods("POST")
+	s.HandleFunc("/post/{id}/report", reportHandler.ReportPost).Methods("POST")
+	s.HandleFunc("/post/{id}/{comment_id}/report", reportHandler.ReportComment).Methods("POST")
+	s.Handle("/post/{id}", scoped(session.ScopePost, postHandler.Edit)).Methods("PUT")
+	s.Handle("/post/{id}/{comment_id}", scoped(session.ScopeComment, postHandler.EditComment)).Methods("PUT")
+	s.Handle("/post/{id}", scoped(session.ScopePost, postHandler.Delete)).Methods("DELETE")
+	s.Handle("/post/{id}/upvote", scoped(session.ScopeVote, postHandler.Upvote)).Methods("GET")
+	s.Handle("/post/{id}/downvote", scoped(session.ScopeVote, postHandler.Downvote)).Methods("GET")
+	s.Handle("/post/{id}/unvote", scoped(session.ScopeVote, postHandler.Unvote)).Methods("GET")
+	s.Handle("/post/{id}/{comment_id}/upvote", scoped(session.ScopeVote, postHandler.UpvoteComment)).Methods("GET")
+	s.Handle("/post/{id}/{comment_id}/downvote", scoped(session.ScopeVote, postHandler.DownvoteComment)).Methods("GET")
+	s.Handle("/post/{id}/{comment_id}/unvote", scoped(session.ScopeVote, postHandler.UnvoteComment)).Methods("GET")
 	s.Use(authenticationMiddleware.Authenticate)
 
+	// site staff routes, checked after Authenticate has filled in the role
+	adminUsers := s.PathPrefix("/admin/users").Subrouter()
+	adminUsers.HandleFunc("", adminHandler.GetUsers).Methods("GET")
+	adminUsers.HandleFunc("/{id}/ban", adminHandler.Ban).Methods("POST")
+	adminUsers.HandleFunc("/{id}/ban", adminHandler.Unban).Methods("DELETE")
+	adminUsers.HandleFunc("/{id}/role", adminHandler.SetRole).Methods("PUT")
+	adminUsers.Use(authenticationMiddleware.RequirePermission(user.PermManageUsers))
+	adminContent := s.PathPrefix("/admin/post").Subrouter()
+	adminContent.HandleFunc("/{id}/remove", moderationHandler.AdminRemovePost).Methods("POST")
+	adminContent.HandleFunc("/{id}/{comment_id}/remove", moderationHandler.AdminRemoveComment).Methods("POST")
+	adminContent.Use(authenticationMiddleware.RequirePermission(user.PermRemoveContent))
+
 	r.PathPrefix("/").Handler(homepageHandler)
 
 	h := middleware.CheckContentType(contextLogger, r)
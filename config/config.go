@@ -3,9 +3,11 @@ package config
 import "github.com/spf13/viper"
 
 type Config struct {
-	App   AppConfig `yaml:"app"`
-	MySQL DBConfig  `yaml:"mysql"`
-	Mongo DBConfig  `yaml:"mongodb"`
+	App   AppConfig  `yaml:"app"`
+	MySQL DBConfig   `yaml:"mysql"`
+	Mongo DBConfig   `yaml:"mongodb"`
+	Mail  MailConfig `yaml:"mail"`
+	JWT   JWTConfig  `yaml:"jwt"`
 }
 
 type DBConfig struct {
@@ -17,9 +19,43 @@ type DBConfig struct {
 }
 
 type AppConfig struct {
-	PasswordRetentionMinute int    `yaml:"password_retention_minute"`
-	Port                    int    `yaml:"port"`
-	SecretKey               string `yaml:"secret_key"`
+	PasswordRetentionMinute   int    `yaml:"password_retention_minute"`
+	Port                      int    `yaml:"port"`
+	AccessTokenLifetimeMinute int    `yaml:"access_token_lifetime_minute"`
+	RefreshTokenLifetimeHour  int    `yaml:"refresh_token_lifetime_hour"`
+	ResetTokenLifetimeMinute  int    `yaml:"reset_token_lifetime_minute"`
+	ResetURL                  string `yaml:"reset_url"`
+	// Admins are promoted to the admin role at startup once they have registered.
+	Admins []string `yaml:"admins"`
+}
+
+// MailConfig without a host makes the app write emails to LogPath instead of
+// sending them.
+type MailConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+	LogPath  string `yaml:"log_path"`
+}
+
+// JWTConfig lists the private keys tokens are signed with. A key starts
+// signing at its active_from (RFC 3339, empty for always) and the one it
+// replaces is still accepted for the grace period. Without keys the app
+// refuses to start unless AllowTemporaryKey is set, the temporary key lives
+// only as long as the process, for local development.
+type JWTConfig struct {
+	GracePeriodMinute int            `yaml:"grace_period_minute"`
+	Keys              []JWTKeyConfig `yaml:"keys"`
+	AllowTemporaryKey bool           `yaml:"allow_temporary_key"`
+}
+
+type JWTKeyConfig struct {
+	ID         string `yaml:"id" mapstructure:"id"`
+	Algorithm  string `yaml:"algorithm" mapstructure:"algorithm"`
+	Path       string `yaml:"path" mapstructure:"path"`
+	ActiveFrom string `yaml:"active_from" mapstructure:"active_from"`
 }
 
 var C Config
@@ -35,6 +71,11 @@ func LoadConfig(path string) error {
 	}
 	C.App.PasswordRetentionMinute = viper.GetStringMap("app")["password_retention_minute"].(int)
 	C.App.Port = viper.GetStringMap("app")["port"].(int)
+	C.App.AccessTokenLifetimeMinute = viper.GetStringMap("app")["access_token_lifetime_minute"].(int)
+	C.App.RefreshTokenLifetimeHour = viper.GetStringMap("app")["refresh_token_lifetime_hour"].(int)
+	C.App.ResetTokenLifetimeMinute = viper.GetStringMap("app")["reset_token_lifetime_minute"].(int)
+	C.App.ResetURL = viper.GetStringMap("app")["reset_url"].(string)
+	C.App.Admins = viper.GetStringSlice("app.admins")
 
 	C.MySQL.Port = viper.GetStringMap("mysql")["port"].(int)
 	C.MySQL.User = viper.GetStringMap("mysql")["user"].(string)
@@ -46,5 +87,19 @@ func LoadConfig(path string) error {
 	C.Mongo.Name = viper.GetStringMap("mongodb")["db_name"].(string)
 	C.Mongo.Port = viper.GetStringMap("mongodb")["port"].(int)
 
+	C.Mail.Host = viper.GetStringMap("mail")["host"].(string)
+	C.Mail.Port = viper.GetStringMap("mail")["port"].(int)
+	C.Mail.User = viper.GetStringMap("mail")["user"].(string)
+	C.Mail.Password = viper.GetStringMap("mail")["password"].(string)
+	C.Mail.From = viper.GetStringMap("mail")["from"].(string)
+	C.Mail.LogPath = viper.GetStringMap("mail")["log_path"].(string)
+
+	C.JWT.GracePeriodMinute = viper.GetStringMap("jwt")["grace_period_minute"].(int)
+	C.JWT.AllowTemporaryKey = viper.GetBool("jwt.allow_temporary_key")
+	err = viper.UnmarshalKey("jwt.keys", &C.JWT.Keys)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
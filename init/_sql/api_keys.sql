@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS `api_keys`;
+CREATE TABLE `api_keys` (
+                         `id` int(11) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+                         `token_hash` varchar(64) NOT NULL,
+                         `user_id` int(11) UNSIGNED NOT NULL,
+                         `username` varchar(100) NOT NULL,
+                         `name` varchar(64) NOT NULL,
+                         `scopes` varchar(100) NOT NULL,
+                         `create_date` varchar(100) NOT NULL,
+                         `last_used` varchar(100) NOT NULL DEFAULT '',
+                         UNIQUE KEY `token_hash` (`token_hash`),
+                         KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
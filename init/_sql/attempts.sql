@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS `attempts`;
+CREATE TABLE `attempts` (
+                         `key` varchar(255) NOT NULL PRIMARY KEY,
+                         `count` int NOT NULL DEFAULT 0,
+                         `last_attempt` bigint NOT NULL,
+                         `prev_attempt` bigint NOT NULL DEFAULT 0,
+                         KEY `last_attempt` (`last_attempt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS `messages`;
+CREATE TABLE `messages` (
+                         `id` int(11) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+                         `thread_id` int(11) UNSIGNED NOT NULL DEFAULT 0,
+                         `sender_id` int(11) UNSIGNED NOT NULL,
+                         `recipient_id` int(11) UNSIGNED NOT NULL,
+                         `subject` varchar(300) NOT NULL DEFAULT '',
+                         `body` text NOT NULL,
+                         `create_date` varchar(100) NOT NULL,
+                         `is_read` tinyint(1) NOT NULL DEFAULT 0,
+                         `deleted_by_sender` tinyint(1) NOT NULL DEFAULT 0,
+                         `deleted_by_recipient` tinyint(1) NOT NULL DEFAULT 0,
+                         KEY `thread_id` (`thread_id`),
+                         KEY `sender_id` (`sender_id`),
+                         KEY `recipient_id` (`recipient_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- Sessions now remember when and from where they were created, rows that
+-- already exist get empty values.
+ALTER TABLE `sessions`
+    ADD COLUMN `create_date` varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN `user_agent` varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN `ip` varchar(100) NOT NULL DEFAULT '',
+    ADD KEY `user_id` (`user_id`);
\ No newline at end of file
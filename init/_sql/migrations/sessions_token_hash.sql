@@ -0,0 +1,10 @@
+-- Sessions created before the signing keys were introduced are keyed on the
+-- token itself, signed tokens no longer fit varchar(200), so the table is
+-- keyed on the sha256 of the token instead.
+ALTER TABLE `sessions` ADD COLUMN `token_hash` char(64) NULL FIRST;
+UPDATE `sessions` SET `token_hash` = SHA2(`token`, 256);
+ALTER TABLE `sessions`
+    DROP PRIMARY KEY,
+    DROP COLUMN `token`,
+    MODIFY `token_hash` char(64) NOT NULL,
+    ADD PRIMARY KEY (`token_hash`);
\ No newline at end of file
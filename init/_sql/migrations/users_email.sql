@@ -0,0 +1,5 @@
+-- Users may set an email to reset their password with, existing accounts
+-- have none until they set it.
+ALTER TABLE `users`
+    ADD COLUMN `email` varchar(254) NOT NULL DEFAULT '',
+    ADD KEY `email` (`email`);
\ No newline at end of file
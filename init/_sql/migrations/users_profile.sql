@@ -0,0 +1,6 @@
+-- Users get a profile, accounts created before it have an empty one and no
+-- known registration date.
+ALTER TABLE `users`
+    ADD COLUMN `bio` varchar(500) NOT NULL DEFAULT '',
+    ADD COLUMN `avatar_url` varchar(500) NOT NULL DEFAULT '',
+    ADD COLUMN `create_date` varchar(100) NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Site roles and bans, every existing account becomes a regular user. The
+-- first admins are promoted from app.admins in the config.
+ALTER TABLE `users`
+    ADD COLUMN `role` varchar(20) NOT NULL DEFAULT 'user',
+    ADD COLUMN `banned` tinyint(1) NOT NULL DEFAULT 0;
\ No newline at end of file
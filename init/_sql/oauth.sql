@@ -0,0 +1,37 @@
+DROP TABLE IF EXISTS `oauth_clients`;
+CREATE TABLE `oauth_clients` (
+                         `id` varchar(32) NOT NULL PRIMARY KEY,
+                         `secret_hash` varchar(64) NOT NULL DEFAULT '',
+                         `name` varchar(64) NOT NULL,
+                         `redirect_uris` text NOT NULL,
+                         `owner_id` int(11) UNSIGNED NOT NULL,
+                         `create_date` varchar(100) NOT NULL,
+                         KEY `owner_id` (`owner_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `oauth_codes`;
+CREATE TABLE `oauth_codes` (
+                         `code_hash` varchar(64) NOT NULL PRIMARY KEY,
+                         `client_id` varchar(32) NOT NULL,
+                         `user_id` int(11) UNSIGNED NOT NULL,
+                         `username` varchar(100) NOT NULL,
+                         `redirect_uri` varchar(2048) NOT NULL,
+                         `scopes` varchar(100) NOT NULL,
+                         `challenge` varchar(64) NOT NULL,
+                         `expiration_date` bigint NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `oauth_tokens`;
+CREATE TABLE `oauth_tokens` (
+                         `token_hash` varchar(64) NOT NULL PRIMARY KEY,
+                         `refresh` tinyint(1) NOT NULL DEFAULT 0,
+                         `grant_id` varchar(32) NOT NULL,
+                         `client_id` varchar(32) NOT NULL,
+                         `user_id` int(11) UNSIGNED NOT NULL,
+                         `username` varchar(100) NOT NULL,
+                         `scopes` varchar(100) NOT NULL,
+                         `expiration_date` bigint NOT NULL,
+                         KEY `grant_id` (`grant_id`),
+                         KEY `client_id` (`client_id`),
+                         KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
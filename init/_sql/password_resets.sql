@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `password_resets`;
+CREATE TABLE `password_resets` (
+                         `token_hash` varchar(64) NOT NULL PRIMARY KEY,
+                         `user_id` int(11) UNSIGNED NOT NULL,
+                         `expiration_date` bigint NOT NULL,
+                         KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS `refresh_tokens`;
+CREATE TABLE `refresh_tokens` (
+                         `token_hash` varchar(64) NOT NULL PRIMARY KEY,
+                         `family_id` varchar(32) NOT NULL,
+                         `user_id` int(11) UNSIGNED NOT NULL,
+                         `username` varchar(100) NOT NULL,
+                         `expiration_date` bigint NOT NULL,
+                         `used` tinyint(1) NOT NULL DEFAULT 0,
+                         KEY `family_id` (`family_id`),
+                         KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
@@ -1,7 +1,11 @@
 DROP TABLE IF EXISTS `sessions`;
 CREATE TABLE `sessions` (
-                         `token` varchar(200) NOT NULL PRIMARY KEY,
+                         `token_hash` char(64) NOT NULL PRIMARY KEY,
                          `username` varchar(100) NOT NULL,
                          `user_id` int(11) UNSIGNED NOT NULL,
-                         `expiration_date` varchar(100) NOT NULL
+                         `expiration_date` varchar(100) NOT NULL,
+                         `create_date` varchar(100) NOT NULL,
+                         `user_agent` varchar(255) NOT NULL DEFAULT '',
+                         `ip` varchar(100) NOT NULL DEFAULT '',
+                         KEY `user_id` (`user_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS `two_factor`;
+CREATE TABLE `two_factor` (
+                         `user_id` int(11) UNSIGNED NOT NULL PRIMARY KEY,
+                         `secret` varchar(64) NOT NULL,
+                         `enabled` boolean NOT NULL DEFAULT FALSE,
+                         `last_step` bigint NOT NULL DEFAULT 0
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `recovery_codes`;
+CREATE TABLE `recovery_codes` (
+                         `user_id` int(11) UNSIGNED NOT NULL,
+                         `code_hash` varchar(64) NOT NULL,
+                         PRIMARY KEY (`user_id`, `code_hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+DROP TABLE IF EXISTS `login_challenges`;
+CREATE TABLE `login_challenges` (
+                         `token_hash` varchar(64) NOT NULL PRIMARY KEY,
+                         `user_id` int(11) UNSIGNED NOT NULL,
+                         `username` varchar(100) NOT NULL,
+                         `expiration_date` bigint NOT NULL,
+                         `attempts` int NOT NULL DEFAULT 0
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
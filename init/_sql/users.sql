@@ -2,5 +2,12 @@ DROP TABLE IF EXISTS `users`;
 CREATE TABLE `users` (
                          `id` int(11) UNSIGNED NOT NULL PRIMARY KEY AUTO_INCREMENT,
                          `username` varchar(100) NOT NULL,
-                         `password` varchar(100) NOT NULL
+                         `password` varchar(100) NOT NULL,
+                         `bio` varchar(500) NOT NULL DEFAULT '',
+                         `avatar_url` varchar(500) NOT NULL DEFAULT '',
+                         `create_date` varchar(100) NOT NULL DEFAULT '',
+                         `email` varchar(254) NOT NULL DEFAULT '',
+                         `role` varchar(20) NOT NULL DEFAULT 'user',
+                         `banned` tinyint(1) NOT NULL DEFAULT 0,
+                         KEY `email` (`email`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
\ No newline at end of file
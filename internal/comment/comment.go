@@ -4,21 +4,65 @@ import (
 	"errors"
 )
 
+const (
+	Like   = 1
+	Unlike = -1
+
+	// DeletedAuthorID marks comments whose author has deleted the account.
+	DeletedAuthorID uint = 0
+)
+
 var (
-	ErrNotExist  = errors.New("comment with specified id not exist")
-	ErrNoAccess  = errors.New("hasn`t access to delete comment")
-	ErrInvalidID = errors.New("comment id is invalid")
+	ErrNotExist      = errors.New("comment with specified id not exist")
+	ErrVoteNotExist  = errors.New("user has not voted for comment")
+	ErrNoAccess      = errors.New("hasn`t access to delete comment")
+	ErrNoEditAccess  = errors.New("hasn`t access to edit comment")
+	ErrInvalidID     = errors.New("comment id is invalid")
+	ErrInvalidSort   = errors.New("unknown comment sort mode")
+	ErrInvalidCursor = errors.New("pagination cursor is invalid")
 )
 
+type Vote struct {
+	UserID uint `json:"user,string" bson:"user_id"`
+	Value  int  `json:"vote" bson:"value"`
+}
+
 type Comment struct {
-	ID         string
-	AuthorID   uint
-	CreateDate string
-	Body       string
+	ID             string
+	AuthorID       uint
+	CreateDate     string
+	Body           string
+	ParentID       string
+	Votes          []*Vote
+	UpvotesCount   int
+	DownvotesCount int
+	Edited         string
+}
+
+type Revision struct {
+	Body       string `json:"body" bson:"body"`
+	CreateDate string `json:"created" bson:"create_date"`
+}
+
+// AuthorStats sums up the comments of one author. Karma counts only the votes of
+// other users, an author voting on their own comment gains nothing.
+type AuthorStats struct {
+	Count int
+	Karma int
 }
 
 type CommentRepo interface {
 	GetByID(id string) (*Comment, error)
+	GetAuthorStats(id uint) (*AuthorStats, error)
+	GetPageByAuthor(id uint, opts PageOptions) (*Page, error)
+	Anonymize(authorID uint) error
 	Add(userID uint, body string) (string, error)
+	AddReply(userID uint, parentID string, body string) (string, error)
+	Update(id string, userID uint, body string) error
+	GetRevisions(id string) ([]*Revision, error)
 	Delete(id string, userID uint) error
+	Remove(id string) error
+	Upvote(id string, voter uint) error
+	Downvote(id string, voter uint) error
+	Unvote(id string, voter uint) error
 }
@@ -7,18 +7,20 @@ import (
 )
 
 type MemoryRepo struct {
-	idCount  uint
-	comments []*Comment
-	mu       *sync.RWMutex
+	idCount   uint
+	comments  []*Comment
+	revisions map[string][]*Revision
+	mu        *sync.RWMutex
 }
 
 var _ CommentRepo = (*MemoryRepo)(nil)
 
 func NewMemoryRepo() *MemoryRepo {
 	return &MemoryRepo{
-		idCount:  0,
-		comments: make([]*Comment, 0, 2),
-		mu:       &sync.RWMutex{},
+		idCount:   0,
+		comments:  make([]*Comment, 0, 2),
+		revisions: make(map[string][]*Revision),
+		mu:        &sync.RWMutex{},
 	}
 }
 
@@ -33,6 +35,25 @@ func (r *MemoryRepo) Add(userID uint, body string) (id string, err error) {
 		CreateDate: time.Now().Format(time.RFC3339),
 		Body:       body,
 		AuthorID:   userID,
+		Votes:      make([]*Vote, 0),
+	})
+
+	return id, nil
+}
+
+func (r *MemoryRepo) AddReply(userID uint, parentID string, body string) (id string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.idCount++
+	id = strconv.Itoa(int(r.idCount))
+	r.comments = append(r.comments, &Comment{
+		ID:         id,
+		CreateDate: time.Now().Format(time.RFC3339),
+		Body:       body,
+		AuthorID:   userID,
+		ParentID:   parentID,
+		Votes:      make([]*Vote, 0),
 	})
 
 	return id, nil
@@ -53,6 +74,54 @@ func (r *MemoryRepo) GetByID(id string) (*Comment, error) {
 	return nil, ErrNotExist
 }
 
+func (r *MemoryRepo) Update(id string, userID uint, body string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, comment := range r.comments {
+		if comment.ID != id {
+			continue
+		}
+		if comment.AuthorID != userID {
+			return ErrNoEditAccess
+		}
+
+		revision := &Revision{
+			Body:       comment.Body,
+			CreateDate: comment.CreateDate,
+		}
+		if comment.Edited != "" {
+			revision.CreateDate = comment.Edited
+		}
+		r.revisions[id] = append(r.revisions[id], revision)
+
+		comment.Body = body
+		comment.Edited = time.Now().Format(time.RFC3339)
+
+		return nil
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) GetRevisions(id string) ([]*Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, comment := range r.comments {
+		if comment.ID != id {
+			continue
+		}
+
+		revisions := make([]*Revision, 0, len(r.revisions[id]))
+		revisions = append(revisions, r.revisions[id]...)
+
+		return revisions, nil
+	}
+
+	return nil, ErrNotExist
+}
+
 func (r *MemoryRepo) Delete(id string, userID uint) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -65,6 +134,7 @@ func (r *MemoryRepo) Delete(id string, userID uint) error {
 			return ErrNoAccess
 		}
 
+		delete(r.revisions, id)
 		copy(r.comments[i:], r.comments[i+1:])
 		r.comments[len(r.comments)-1] = nil
 		r.comments = r.comments[:len(r.comments)-1]
@@ -74,3 +144,156 @@ func (r *MemoryRepo) Delete(id string, userID uint) error {
 
 	return ErrNotExist
 }
+
+func (r *MemoryRepo) vote(id string, voter uint, value int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, comment := range r.comments {
+		if comment.ID != id {
+			continue
+		}
+
+		for _, v := range comment.Votes {
+			if v.UserID != voter {
+				continue
+			}
+			if v.Value == value {
+				return nil
+			}
+
+			v.Value = value
+			if value == Like {
+				comment.UpvotesCount += 1
+				comment.DownvotesCount -= 1
+			} else {
+				comment.DownvotesCount += 1
+				comment.UpvotesCount -= 1
+			}
+
+			return nil
+		}
+
+		comment.Votes = append(comment.Votes, &Vote{
+			UserID: voter,
+			Value:  value,
+		})
+		if value == Like {
+			comment.UpvotesCount += 1
+		} else {
+			comment.DownvotesCount += 1
+		}
+
+		return nil
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) Upvote(id string, voter uint) error {
+	return r.vote(id, voter, Like)
+}
+
+func (r *MemoryRepo) Downvote(id string, voter uint) error {
+	return r.vote(id, voter, Unlike)
+}
+
+func (r *MemoryRepo) Unvote(id string, voter uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, comment := range r.comments {
+		if comment.ID != id {
+			continue
+		}
+
+		for j, v := range comment.Votes {
+			if v.UserID != voter {
+				continue
+			}
+
+			if v.Value == Like {
+				comment.UpvotesCount -= 1
+			} else {
+				comment.DownvotesCount -= 1
+			}
+			copy(comment.Votes[j:], comment.Votes[j+1:])
+			comment.Votes[len(comment.Votes)-1] = nil
+			comment.Votes = comment.Votes[:len(comment.Votes)-1]
+
+			return nil
+		}
+
+		return ErrVoteNotExist
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.comments {
+		if r.comments[i].ID != id {
+			continue
+		}
+
+		delete(r.revisions, id)
+		copy(r.comments[i:], r.comments[i+1:])
+		r.comments[len(r.comments)-1] = nil
+		r.comments = r.comments[:len(r.comments)-1]
+
+		return nil
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) GetAuthorStats(id uint) (*AuthorStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &AuthorStats{}
+	for _, item := range r.comments {
+		if item.AuthorID != id {
+			continue
+		}
+
+		stats.Count++
+		for _, v := range item.Votes {
+			if v.UserID != id {
+				stats.Karma += v.Value
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+func (r *MemoryRepo) GetPageByAuthor(id uint, opts PageOptions) (*Page, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := make([]*Comment, 0)
+	for _, c := range r.comments {
+		if c.AuthorID == id {
+			comments = append(comments, c)
+		}
+	}
+
+	return rank(comments, opts)
+}
+
+func (r *MemoryRepo) Anonymize(authorID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, comment := range r.comments {
+		if comment.AuthorID == authorID {
+			comment.AuthorID = DeletedAuthorID
+		}
+	}
+
+	return nil
+}
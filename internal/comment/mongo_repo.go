@@ -5,9 +5,15 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
+type StatsItem struct {
+	Count int `bson:"count"`
+	Karma int `bson:"karma"`
+}
+
 type MongoRepo struct {
 	Comments *mongo.Collection
 	DB       *mongo.Database
@@ -16,10 +22,16 @@ type MongoRepo struct {
 var _ CommentRepo = (*MongoRepo)(nil)
 
 type Item struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	AuthorID   uint               `bson:"author_id"`
-	CreateDate string             `bson:"create_date"`
-	Body       string             `bson:"body"`
+	ID             primitive.ObjectID `bson:"_id"`
+	AuthorID       uint               `bson:"author_id"`
+	CreateDate     string             `bson:"create_date"`
+	Body           string             `bson:"body"`
+	ParentID       string             `bson:"parent_id,omitempty"`
+	Votes          []*Vote            `bson:"votes"`
+	UpvotesCount   int                `bson:"upvotes_count"`
+	DownvotesCount int                `bson:"downvotes_count"`
+	Edited         string             `bson:"edited,omitempty"`
+	Revisions      []*Revision        `bson:"revisions,omitempty"`
 }
 
 func NewMongoRepo(db *mongo.Database) *MongoRepo {
@@ -37,6 +49,25 @@ func (r *MongoRepo) Add(userID uint, body string) (id string, err error) {
 		AuthorID:   userID,
 		CreateDate: time.Now().Format(time.RFC3339),
 		Body:       body,
+		Votes:      make([]*Vote, 0),
+	}
+
+	_, err = r.Comments.InsertOne(context.TODO(), comment)
+	if err != nil {
+		return "", err
+	}
+
+	return comment.ID.Hex(), nil
+}
+
+func (r *MongoRepo) AddReply(userID uint, parentID string, body string) (id string, err error) {
+	comment := Item{
+		ID:         primitive.NewObjectID(),
+		AuthorID:   userID,
+		CreateDate: time.Now().Format(time.RFC3339),
+		Body:       body,
+		ParentID:   parentID,
+		Votes:      make([]*Vote, 0),
 	}
 
 	_, err = r.Comments.InsertOne(context.TODO(), comment)
@@ -65,15 +96,85 @@ func (r *MongoRepo) GetByID(id string) (*Comment, error) {
 	}
 
 	comment := &Comment{
-		ID:         item.ID.Hex(),
-		AuthorID:   item.AuthorID,
-		CreateDate: item.CreateDate,
-		Body:       item.Body,
+		ID:             item.ID.Hex(),
+		AuthorID:       item.AuthorID,
+		CreateDate:     item.CreateDate,
+		Body:           item.Body,
+		ParentID:       item.ParentID,
+		Votes:          item.Votes,
+		UpvotesCount:   item.UpvotesCount,
+		DownvotesCount: item.DownvotesCount,
+		Edited:         item.Edited,
 	}
 
 	return comment, nil
 }
 
+func (r *MongoRepo) Update(id string, userID uint, body string) error {
+	item := &Item{}
+	itemID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+	filter := bson.M{"_id": itemID}
+
+	err = r.Comments.FindOne(context.TODO(), filter).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrNotExist
+		}
+
+		return err
+	}
+
+	if item.AuthorID != userID {
+		return ErrNoEditAccess
+	}
+
+	revision := &Revision{
+		Body:       item.Body,
+		CreateDate: item.CreateDate,
+	}
+	if item.Edited != "" {
+		revision.CreateDate = item.Edited
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"body":   body,
+			"edited": time.Now().Format(time.RFC3339),
+		},
+		"$push": bson.M{
+			"revisions": revision,
+		},
+	}
+
+	_, err = r.Comments.UpdateOne(context.TODO(), filter, update)
+	return err
+}
+
+func (r *MongoRepo) GetRevisions(id string) ([]*Revision, error) {
+	item := &Item{}
+	itemID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	option := options.FindOne().SetProjection(bson.M{"revisions": 1})
+	err = r.Comments.FindOne(context.TODO(), bson.M{"_id": itemID}, option).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotExist
+		}
+
+		return nil, err
+	}
+
+	if item.Revisions == nil {
+		return make([]*Revision, 0), nil
+	}
+	return item.Revisions, nil
+}
+
 func (r *MongoRepo) Delete(id string, userID uint) error {
 	item := &Item{}
 	itemID, err := primitive.ObjectIDFromHex(id)
@@ -98,3 +199,237 @@ func (r *MongoRepo) Delete(id string, userID uint) error {
 	_, err = r.Comments.DeleteOne(context.TODO(), filter)
 	return err
 }
+
+func (r *MongoRepo) vote(id string, voter uint, value int) error {
+	itemID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	inc := bson.M{"upvotes_count": 1, "downvotes_count": -1}
+	count := "upvotes_count"
+	if value == Unlike {
+		inc = bson.M{"upvotes_count": -1, "downvotes_count": 1}
+		count = "downvotes_count"
+	}
+
+	filter := bson.M{
+		"_id": itemID,
+		"votes": bson.M{
+			"$elemMatch": bson.M{"user_id": voter, "value": -value},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"votes.$.value": value},
+		"$inc": inc,
+	}
+	res := r.Comments.FindOneAndUpdate(context.TODO(), filter, update)
+	if res.Err() != mongo.ErrNoDocuments {
+		return res.Err()
+	}
+
+	filter = bson.M{"_id": itemID, "votes.user_id": bson.M{"$ne": voter}}
+	update = bson.M{
+		"$push": bson.M{
+			"votes": Vote{
+				UserID: voter,
+				Value:  value,
+			},
+		},
+		"$inc": bson.M{count: 1},
+	}
+	result, err := r.Comments.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount != 0 {
+		return nil
+	}
+
+	// either the comment is absent or the user has already voted the same way
+	n, err := r.Comments.CountDocuments(context.TODO(), bson.M{"_id": itemID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotExist
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) Upvote(id string, voter uint) error {
+	return r.vote(id, voter, Like)
+}
+
+func (r *MongoRepo) Downvote(id string, voter uint) error {
+	return r.vote(id, voter, Unlike)
+}
+
+func (r *MongoRepo) Unvote(id string, voter uint) error {
+	itemID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	item := &Item{}
+	err = r.Comments.FindOne(context.TODO(), bson.M{"_id": itemID}).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrNotExist
+		}
+
+		return err
+	}
+
+	var vote *Vote
+	for _, v := range item.Votes {
+		if v.UserID == voter {
+			vote = v
+			break
+		}
+	}
+	if vote == nil {
+		return ErrVoteNotExist
+	}
+
+	count := "upvotes_count"
+	if vote.Value == Unlike {
+		count = "downvotes_count"
+	}
+	update := bson.M{
+		"$pull": bson.M{
+			"votes": bson.M{"user_id": voter},
+		},
+		"$inc": bson.M{count: -1},
+	}
+	_, err = r.Comments.UpdateOne(context.TODO(), bson.M{"_id": itemID, "votes.user_id": voter}, update)
+
+	return err
+}
+
+func (r *MongoRepo) Remove(id string) error {
+	itemID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	res, err := r.Comments.DeleteOne(context.TODO(), bson.M{"_id": itemID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotExist
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) GetAuthorStats(id uint) (*AuthorStats, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"author_id": id}},
+		{"$group": bson.M{
+			"_id":   nil,
+			"count": bson.M{"$sum": 1},
+			"karma": bson.M{"$sum": bson.M{"$sum": bson.M{"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{
+					"input": "$votes",
+					"cond":  bson.M{"$ne": []interface{}{"$$this.user_id", id}},
+				}},
+				"in": "$$this.value",
+			}}}},
+		}},
+	}
+
+	cursor, err := r.Comments.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var items []*StatsItem
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &AuthorStats{}
+	if len(items) != 0 {
+		stats.Count = items[0].Count
+		stats.Karma = items[0].Karma
+	}
+
+	return stats, nil
+}
+
+func (r *MongoRepo) GetPageByAuthor(id uint, opts PageOptions) (*Page, error) {
+	if opts.Sort == "" {
+		opts.Sort = SortNew
+	}
+
+	match := bson.M{"author_id": id}
+	pipeline := []bson.M{
+		{"$addFields": bson.M{"score": bson.M{"$subtract": bson.A{"$upvotes_count", "$downvotes_count"}}}},
+	}
+	if opts.After != "" {
+		c, err := decodeCursor(opts.After, opts.Sort)
+		if err != nil {
+			return nil, err
+		}
+		afterID, err := primitive.ObjectIDFromHex(c.ID)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		if opts.Sort == SortTop {
+			pipeline = append(pipeline, bson.M{"$match": bson.M{"$or": bson.A{
+				bson.M{"score": bson.M{"$lt": c.Key}},
+				bson.M{"score": c.Key, "_id": bson.M{"$lt": afterID}},
+			}}})
+		} else {
+			match["_id"] = bson.M{"$lt": afterID}
+		}
+	}
+	pipeline = append([]bson.M{{"$match": match}}, pipeline...)
+
+	if opts.Sort == SortTop {
+		pipeline = append(pipeline, bson.M{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}})
+	} else {
+		pipeline = append(pipeline, bson.M{"$sort": bson.M{"_id": -1}})
+	}
+	if opts.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": opts.Limit + 1})
+	}
+
+	cursor, err := r.Comments.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var items []*Item
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]*Comment, 0, len(items))
+	for _, item := range items {
+		comments = append(comments, &Comment{
+			ID:             item.ID.Hex(),
+			AuthorID:       item.AuthorID,
+			CreateDate:     item.CreateDate,
+			Body:           item.Body,
+			ParentID:       item.ParentID,
+			Votes:          item.Votes,
+			UpvotesCount:   item.UpvotesCount,
+			DownvotesCount: item.DownvotesCount,
+			Edited:         item.Edited,
+		})
+	}
+
+	return newPage(comments, opts), nil
+}
+
+// Anonymize hands the comments of a removed account over to DeletedAuthorID.
+func (r *MongoRepo) Anonymize(authorID uint) error {
+	_, err := r.Comments.UpdateMany(context.TODO(), bson.M{"author_id": authorID}, bson.M{"$set": bson.M{"author_id": DeletedAuthorID}})
+
+	return err
+}
@@ -0,0 +1,125 @@
+package comment
+
+import (
+	"github.com/vlasdash/redditclone/internal/pagination"
+	"sort"
+)
+
+type Sort string
+
+const (
+	SortNew Sort = "new"
+	SortTop Sort = "top"
+
+	DefaultPageLimit = 25
+	MaxPageLimit     = 100
+)
+
+type PageOptions struct {
+	Limit int
+	After string
+	Sort  Sort
+}
+
+type Page struct {
+	Comments []*Comment
+	Next     string
+}
+
+type cursor struct {
+	ID   string `json:"id"`
+	Sort Sort   `json:"sort"`
+	Key  int    `json:"key"`
+}
+
+func ParseSort(s string) (Sort, error) {
+	switch Sort(s) {
+	case "":
+		return SortNew, nil
+	case SortNew, SortTop:
+		return Sort(s), nil
+	}
+
+	return "", ErrInvalidSort
+}
+
+func decodeCursor(s string, sortMode Sort) (*cursor, error) {
+	c := &cursor{}
+	err := pagination.DecodeCursor(s, c)
+	if err != nil || c.ID == "" || c.Sort != sortMode {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+func (c *Comment) score() int {
+	return c.UpvotesCount - c.DownvotesCount
+}
+
+// NextCursor returns the cursor of the page that starts right after c.
+func NextCursor(c *Comment, sortMode Sort) string {
+	return pagination.EncodeCursor(&cursor{
+		ID:   c.ID,
+		Sort: sortMode,
+		Key:  c.score(),
+	})
+}
+
+func ranksBefore(a, b *Comment, sortMode Sort) bool {
+	if sortMode == SortTop && a.score() != b.score() {
+		return a.score() > b.score()
+	}
+
+	return pagination.IDLess(b.ID, a.ID)
+}
+
+// newPage expects comments to hold up to opts.Limit+1 elements: the extra one
+// only signals that another page exists and is never returned to the caller.
+func newPage(comments []*Comment, opts PageOptions) *Page {
+	page := &Page{
+		Comments: comments,
+	}
+	if opts.Limit <= 0 || len(comments) <= opts.Limit {
+		return page
+	}
+
+	page.Comments = comments[:opts.Limit]
+	page.Next = NextCursor(page.Comments[opts.Limit-1], opts.Sort)
+
+	return page
+}
+
+// rank orders comments and cuts the page following opts.After, the memory
+// repository pages through it.
+func rank(comments []*Comment, opts PageOptions) (*Page, error) {
+	if opts.Sort == "" {
+		opts.Sort = SortNew
+	}
+
+	ranked := make([]*Comment, 0, len(comments))
+	if opts.After != "" {
+		c, err := decodeCursor(opts.After, opts.Sort)
+		if err != nil {
+			return nil, err
+		}
+
+		after := &Comment{ID: c.ID, UpvotesCount: c.Key}
+		for _, comment := range comments {
+			if ranksBefore(after, comment, opts.Sort) {
+				ranked = append(ranked, comment)
+			}
+		}
+	} else {
+		ranked = append(ranked, comments...)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranksBefore(ranked[i], ranked[j], opts.Sort)
+	})
+	if opts.Limit > 0 && len(ranked) > opts.Limit+1 {
+		ranked = ranked[:opts.Limit+1]
+	}
+
+	return newPage(ranked, opts), nil
+}
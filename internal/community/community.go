@@ -0,0 +1,130 @@
+package community
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	TypeText = "text"
+	TypeLink = "link"
+)
+
+var DefaultNames = []string{"music", "funny", "videos", "programming", "news", "fashion"}
+
+var (
+	ErrNotExist        = errors.New("community does not exist")
+	ErrAlreadyExist    = errors.New("community already exists")
+	ErrInvalidName     = errors.New("community name must be 2-21 letters, digits or underscores")
+	ErrInvalidPostType = errors.New("unknown post type")
+	ErrNoAccess        = errors.New("hasn`t access to edit community")
+	ErrRestricted      = errors.New("community is restricted")
+	ErrTypeNotAllowed  = errors.New("post type is not allowed in community")
+	ErrBanned          = errors.New("user is banned from community")
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-z0-9_]{2,21}$`)
+
+type Settings struct {
+	Description  string   `json:"description"`
+	Rules        []string `json:"rules"`
+	AllowedTypes []string `json:"allowedTypes"`
+	Restricted   bool     `json:"restricted"`
+}
+
+type Community struct {
+	Name       string   `json:"name"`
+	OwnerID    uint     `json:"owner,string"`
+	CreateDate string   `json:"created"`
+	Settings   Settings `json:"settings"`
+	Moderators []uint   `json:"moderators"`
+	Banned     []uint   `json:"-"`
+}
+
+type CommunityRepo interface {
+	GetAll() ([]*Community, error)
+	GetByName(name string) (*Community, error)
+	Create(c *Community) error
+	UpdateSettings(name string, userID uint, settings Settings) error
+	AddModerator(name string, ownerID uint, userID uint) error
+	RemoveModerator(name string, ownerID uint, userID uint) error
+	Ban(name string, moderatorID uint, userID uint) error
+	Unban(name string, moderatorID uint, userID uint) error
+}
+
+func NormalizeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !nameRegexp.MatchString(name) {
+		return "", ErrInvalidName
+	}
+
+	return name, nil
+}
+
+func (s *Settings) Validate() error {
+	for _, t := range s.AllowedTypes {
+		if t != TypeText && t != TypeLink {
+			return ErrInvalidPostType
+		}
+	}
+
+	return nil
+}
+
+func (c *Community) IsModerator(userID uint) bool {
+	if c.OwnerID == userID {
+		return true
+	}
+	for _, id := range c.Moderators {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Community) IsBanned(userID uint) bool {
+	for _, id := range c.Banned {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Community) CanPost(userID uint, postType string) error {
+	if postType != TypeText && postType != TypeLink {
+		return ErrInvalidPostType
+	}
+	if c.IsBanned(userID) {
+		return ErrBanned
+	}
+	if c.Settings.Restricted && !c.IsModerator(userID) {
+		return ErrRestricted
+	}
+	if len(c.Settings.AllowedTypes) == 0 {
+		return nil
+	}
+	for _, t := range c.Settings.AllowedTypes {
+		if t == postType {
+			return nil
+		}
+	}
+
+	return ErrTypeNotAllowed
+}
+
+// EnsureDefaults creates the communities the frontend has always offered as categories.
+func EnsureDefaults(repo CommunityRepo) error {
+	for _, name := range DefaultNames {
+		err := repo.Create(&Community{Name: name})
+		if err != nil && err != ErrAlreadyExist {
+			return err
+		}
+	}
+
+	return nil
+}
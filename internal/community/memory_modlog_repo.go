@@ -0,0 +1,59 @@
+package community
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type MemoryModLogRepo struct {
+	idCount uint
+	actions []*ModAction
+	mu      *sync.RWMutex
+}
+
+var _ ModLogRepo = (*MemoryModLogRepo)(nil)
+
+func NewMemoryModLogRepo() *MemoryModLogRepo {
+	return &MemoryModLogRepo{
+		actions: make([]*ModAction, 0),
+		mu:      &sync.RWMutex{},
+	}
+}
+
+func (r *MemoryModLogRepo) Add(action *ModAction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.idCount++
+	action.ID = strconv.Itoa(int(r.idCount))
+	action.CreateDate = time.Now().Format(time.RFC3339)
+	copyAction := *action
+	r.actions = append(r.actions, &copyAction)
+
+	return nil
+}
+
+func (r *MemoryModLogRepo) GetByCommunity(name string, filter ModLogFilter) ([]*ModAction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit := filter.limit()
+	actions := make([]*ModAction, 0)
+	for i := len(r.actions) - 1; i >= 0 && len(actions) < limit; i-- {
+		a := r.actions[i]
+		if a.Community != name {
+			continue
+		}
+		if filter.Action != "" && a.Action != filter.Action {
+			continue
+		}
+		if filter.ModeratorID != 0 && a.ModeratorID != filter.ModeratorID {
+			continue
+		}
+		copyAction := *a
+		actions = append(actions, &copyAction)
+	}
+
+	return actions, nil
+}
@@ -0,0 +1,170 @@
+package community
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type MemoryRepo struct {
+	communities map[string]*Community
+	mu          *sync.RWMutex
+}
+
+var _ CommunityRepo = (*MemoryRepo)(nil)
+
+func NewMemoryRepo() *MemoryRepo {
+	return &MemoryRepo{
+		communities: make(map[string]*Community),
+		mu:          &sync.RWMutex{},
+	}
+}
+
+func (r *MemoryRepo) GetAll() ([]*Community, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	communities := make([]*Community, 0, len(r.communities))
+	for _, c := range r.communities {
+		communities = append(communities, c.copy())
+	}
+	sort.Slice(communities, func(i, j int) bool {
+		return communities[i].Name < communities[j].Name
+	})
+
+	return communities, nil
+}
+
+func (r *MemoryRepo) GetByName(name string) (*Community, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.communities[name]
+	if !ok {
+		return nil, ErrNotExist
+	}
+
+	return c.copy(), nil
+}
+
+func (r *MemoryRepo) Create(c *Community) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.communities[c.Name]; ok {
+		return ErrAlreadyExist
+	}
+	c.CreateDate = time.Now().Format(time.RFC3339)
+	r.communities[c.Name] = c.copy()
+
+	return nil
+}
+
+func (r *MemoryRepo) UpdateSettings(name string, userID uint, settings Settings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.communities[name]
+	if !ok {
+		return ErrNotExist
+	}
+	if c.OwnerID != userID {
+		return ErrNoAccess
+	}
+	c.Settings = settings
+
+	return nil
+}
+
+func (r *MemoryRepo) AddModerator(name string, ownerID uint, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.communities[name]
+	if !ok {
+		return ErrNotExist
+	}
+	if c.OwnerID != ownerID {
+		return ErrNoAccess
+	}
+	c.Moderators = appendUnique(c.Moderators, userID)
+
+	return nil
+}
+
+func (r *MemoryRepo) RemoveModerator(name string, ownerID uint, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.communities[name]
+	if !ok {
+		return ErrNotExist
+	}
+	if c.OwnerID != ownerID {
+		return ErrNoAccess
+	}
+	c.Moderators = without(c.Moderators, userID)
+
+	return nil
+}
+
+func (r *MemoryRepo) Ban(name string, moderatorID uint, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.communities[name]
+	if !ok {
+		return ErrNotExist
+	}
+	if !c.IsModerator(moderatorID) || c.OwnerID == userID {
+		return ErrNoAccess
+	}
+	c.Banned = appendUnique(c.Banned, userID)
+
+	return nil
+}
+
+func (r *MemoryRepo) Unban(name string, moderatorID uint, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.communities[name]
+	if !ok {
+		return ErrNotExist
+	}
+	if !c.IsModerator(moderatorID) {
+		return ErrNoAccess
+	}
+	c.Banned = without(c.Banned, userID)
+
+	return nil
+}
+
+func (c *Community) copy() *Community {
+	copyCommunity := *c
+	copyCommunity.Moderators = append([]uint(nil), c.Moderators...)
+	copyCommunity.Banned = append([]uint(nil), c.Banned...)
+
+	return &copyCommunity
+}
+
+func appendUnique(ids []uint, id uint) []uint {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+
+	return append(ids, id)
+}
+
+func without(ids []uint, id uint) []uint {
+	result := make([]uint, 0, len(ids))
+	for _, existing := range ids {
+		if existing != id {
+			result = append(result, existing)
+		}
+	}
+
+	return result
+}
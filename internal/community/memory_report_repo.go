@@ -0,0 +1,89 @@
+package community
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type MemoryReportRepo struct {
+	items map[string]*ReportedItem
+	mu    *sync.RWMutex
+}
+
+var _ ReportRepo = (*MemoryReportRepo)(nil)
+
+func NewMemoryReportRepo() *MemoryReportRepo {
+	return &MemoryReportRepo{
+		items: make(map[string]*ReportedItem),
+		mu:    &sync.RWMutex{},
+	}
+}
+
+func (r *MemoryReportRepo) Add(target Target, userID uint, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := reportKey(target.Type, target.ID)
+	item, ok := r.items[key]
+	if !ok {
+		item = &ReportedItem{
+			Target:  target,
+			Reports: make([]*Report, 0, 1),
+		}
+		r.items[key] = item
+	}
+
+	for _, report := range item.Reports {
+		if report.UserID == userID {
+			return ErrAlreadyReported
+		}
+	}
+
+	item.Reports = append(item.Reports, &Report{
+		UserID:     userID,
+		Reason:     reason,
+		CreateDate: time.Now().Format(time.RFC3339),
+	})
+	item.Count++
+
+	return nil
+}
+
+func (r *MemoryReportRepo) GetQueue(name string) ([]*ReportedItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]*ReportedItem, 0)
+	for _, item := range r.items {
+		if item.Community != name {
+			continue
+		}
+
+		copyItem := *item
+		copyItem.Reports = append([]*Report(nil), item.Reports...)
+		items = append(items, &copyItem)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	return items, nil
+}
+
+func (r *MemoryReportRepo) Clear(targetType string, targetID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := reportKey(targetType, targetID)
+	if _, ok := r.items[key]; !ok {
+		return ErrReportNotExist
+	}
+	delete(r.items, key)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package community
+
+import (
+	"sync"
+)
+
+type MemorySubscriptionRepo struct {
+	subscriptions map[uint][]string
+	mu            *sync.RWMutex
+}
+
+var _ SubscriptionRepo = (*MemorySubscriptionRepo)(nil)
+
+func NewMemorySubscriptionRepo() *MemorySubscriptionRepo {
+	return &MemorySubscriptionRepo{
+		subscriptions: make(map[uint][]string),
+		mu:            &sync.RWMutex{},
+	}
+}
+
+func (r *MemorySubscriptionRepo) Subscribe(userID uint, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.subscriptions[userID] {
+		if n == name {
+			return nil
+		}
+	}
+	r.subscriptions[userID] = append(r.subscriptions[userID], name)
+
+	return nil
+}
+
+func (r *MemorySubscriptionRepo) Unsubscribe(userID uint, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := r.subscriptions[userID]
+	for i := range names {
+		if names[i] != name {
+			continue
+		}
+
+		r.subscriptions[userID] = append(names[:i:i], names[i+1:]...)
+		return nil
+	}
+
+	return ErrNotSubscribed
+}
+
+func (r *MemorySubscriptionRepo) GetByUser(userID uint) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.subscriptions[userID]))
+	names = append(names, r.subscriptions[userID]...)
+
+	return names, nil
+}
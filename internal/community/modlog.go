@@ -0,0 +1,52 @@
+package community
+
+const (
+	ActionRemovePost      = "remove_post"
+	ActionRemoveComment   = "remove_comment"
+	ActionLock            = "lock"
+	ActionUnlock          = "unlock"
+	ActionPin             = "pin"
+	ActionUnpin           = "unpin"
+	ActionBan             = "ban"
+	ActionUnban           = "unban"
+	ActionAddModerator    = "add_moderator"
+	ActionRemoveModerator = "remove_moderator"
+	ActionApprovePost     = "approve_post"
+	ActionApproveComment  = "approve_comment"
+
+	DefaultModLogLimit = 50
+	MaxModLogLimit     = 500
+)
+
+type ModAction struct {
+	ID          string `json:"id"`
+	Community   string `json:"community"`
+	ModeratorID uint   `json:"moderator,string"`
+	Action      string `json:"action"`
+	TargetID    string `json:"target"`
+	PostID      string `json:"post,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	CreateDate  string `json:"created"`
+}
+
+type ModLogFilter struct {
+	Action      string
+	ModeratorID uint
+	Limit       int
+}
+
+type ModLogRepo interface {
+	Add(action *ModAction) error
+	GetByCommunity(name string, filter ModLogFilter) ([]*ModAction, error)
+}
+
+func (f ModLogFilter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultModLogLimit
+	}
+	if f.Limit > MaxModLogLimit {
+		return MaxModLogLimit
+	}
+
+	return f.Limit
+}
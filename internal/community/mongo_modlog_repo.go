@@ -0,0 +1,97 @@
+package community
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type ModActionItem struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Community   string             `bson:"community"`
+	ModeratorID uint               `bson:"moderator_id"`
+	Action      string             `bson:"action"`
+	TargetID    string             `bson:"target_id"`
+	PostID      string             `bson:"post_id,omitempty"`
+	Reason      string             `bson:"reason,omitempty"`
+	CreateDate  string             `bson:"create_date"`
+}
+
+type MongoModLogRepo struct {
+	Actions *mongo.Collection
+	DB      *mongo.Database
+}
+
+var _ ModLogRepo = (*MongoModLogRepo)(nil)
+
+func NewMongoModLogRepo(db *mongo.Database) *MongoModLogRepo {
+	collection := db.Collection("modlog")
+
+	return &MongoModLogRepo{
+		Actions: collection,
+		DB:      db,
+	}
+}
+
+func (r *MongoModLogRepo) Add(action *ModAction) error {
+	item := &ModActionItem{
+		ID:          primitive.NewObjectID(),
+		Community:   action.Community,
+		ModeratorID: action.ModeratorID,
+		Action:      action.Action,
+		TargetID:    action.TargetID,
+		PostID:      action.PostID,
+		Reason:      action.Reason,
+		CreateDate:  time.Now().Format(time.RFC3339),
+	}
+
+	_, err := r.Actions.InsertOne(context.TODO(), item)
+	if err != nil {
+		return err
+	}
+	action.ID = item.ID.Hex()
+	action.CreateDate = item.CreateDate
+
+	return nil
+}
+
+func (r *MongoModLogRepo) GetByCommunity(name string, filter ModLogFilter) ([]*ModAction, error) {
+	var items []*ModActionItem
+
+	query := bson.M{"community": name}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.ModeratorID != 0 {
+		query["moderator_id"] = filter.ModeratorID
+	}
+
+	option := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(filter.limit()))
+	cursor, err := r.Actions.Find(context.TODO(), query, option)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]*ModAction, 0, len(items))
+	for _, item := range items {
+		actions = append(actions, &ModAction{
+			ID:          item.ID.Hex(),
+			Community:   item.Community,
+			ModeratorID: item.ModeratorID,
+			Action:      item.Action,
+			TargetID:    item.TargetID,
+			PostID:      item.PostID,
+			Reason:      item.Reason,
+			CreateDate:  item.CreateDate,
+		})
+	}
+
+	return actions, nil
+}
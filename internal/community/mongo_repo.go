@@ -0,0 +1,190 @@
+package community
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type Item struct {
+	Name         string   `bson:"_id"`
+	OwnerID      uint     `bson:"owner_id"`
+	CreateDate   string   `bson:"create_date"`
+	Description  string   `bson:"description"`
+	Rules        []string `bson:"rules"`
+	AllowedTypes []string `bson:"allowed_types"`
+	Restricted   bool     `bson:"restricted"`
+	Moderators   []uint   `bson:"moderators"`
+	Banned       []uint   `bson:"banned"`
+}
+
+type MongoRepo struct {
+	Communities *mongo.Collection
+	DB          *mongo.Database
+}
+
+var _ CommunityRepo = (*MongoRepo)(nil)
+
+func NewMongoRepo(db *mongo.Database) *MongoRepo {
+	collection := db.Collection("communities")
+
+	return &MongoRepo{
+		Communities: collection,
+		DB:          db,
+	}
+}
+
+func (item *Item) community() *Community {
+	return &Community{
+		Name:       item.Name,
+		OwnerID:    item.OwnerID,
+		CreateDate: item.CreateDate,
+		Settings: Settings{
+			Description:  item.Description,
+			Rules:        item.Rules,
+			AllowedTypes: item.AllowedTypes,
+			Restricted:   item.Restricted,
+		},
+		Moderators: item.Moderators,
+		Banned:     item.Banned,
+	}
+}
+
+func (r *MongoRepo) GetAll() ([]*Community, error) {
+	var items []*Item
+
+	option := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := r.Communities.Find(context.TODO(), bson.M{}, option)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	communities := make([]*Community, 0, len(items))
+	for _, item := range items {
+		communities = append(communities, item.community())
+	}
+
+	return communities, nil
+}
+
+func (r *MongoRepo) GetByName(name string) (*Community, error) {
+	item := &Item{}
+
+	err := r.Communities.FindOne(context.TODO(), bson.M{"_id": name}).Decode(item)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return item.community(), nil
+}
+
+func (r *MongoRepo) Create(c *Community) error {
+	c.CreateDate = time.Now().Format(time.RFC3339)
+	item := &Item{
+		Name:         c.Name,
+		OwnerID:      c.OwnerID,
+		CreateDate:   c.CreateDate,
+		Description:  c.Settings.Description,
+		Rules:        c.Settings.Rules,
+		AllowedTypes: c.Settings.AllowedTypes,
+		Restricted:   c.Settings.Restricted,
+	}
+
+	_, err := r.Communities.InsertOne(context.TODO(), item)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAlreadyExist
+	}
+
+	return err
+}
+
+func (r *MongoRepo) UpdateSettings(name string, userID uint, settings Settings) error {
+	c, err := r.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if c.OwnerID != userID {
+		return ErrNoAccess
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"description":   settings.Description,
+			"rules":         settings.Rules,
+			"allowed_types": settings.AllowedTypes,
+			"restricted":    settings.Restricted,
+		},
+	}
+	_, err = r.Communities.UpdateOne(context.TODO(), bson.M{"_id": name}, update)
+
+	return err
+}
+
+func (r *MongoRepo) AddModerator(name string, ownerID uint, userID uint) error {
+	c, err := r.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if c.OwnerID != ownerID {
+		return ErrNoAccess
+	}
+
+	update := bson.M{"$addToSet": bson.M{"moderators": userID}}
+	_, err = r.Communities.UpdateOne(context.TODO(), bson.M{"_id": name}, update)
+
+	return err
+}
+
+func (r *MongoRepo) RemoveModerator(name string, ownerID uint, userID uint) error {
+	c, err := r.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if c.OwnerID != ownerID {
+		return ErrNoAccess
+	}
+
+	update := bson.M{"$pull": bson.M{"moderators": userID}}
+	_, err = r.Communities.UpdateOne(context.TODO(), bson.M{"_id": name}, update)
+
+	return err
+}
+
+func (r *MongoRepo) Ban(name string, moderatorID uint, userID uint) error {
+	c, err := r.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if !c.IsModerator(moderatorID) || c.OwnerID == userID {
+		return ErrNoAccess
+	}
+
+	update := bson.M{"$addToSet": bson.M{"banned": userID}}
+	_, err = r.Communities.UpdateOne(context.TODO(), bson.M{"_id": name}, update)
+
+	return err
+}
+
+func (r *MongoRepo) Unban(name string, moderatorID uint, userID uint) error {
+	c, err := r.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if !c.IsModerator(moderatorID) {
+		return ErrNoAccess
+	}
+
+	update := bson.M{"$pull": bson.M{"banned": userID}}
+	_, err = r.Communities.UpdateOne(context.TODO(), bson.M{"_id": name}, update)
+
+	return err
+}
@@ -0,0 +1,121 @@
+package community
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type ReportEntry struct {
+	UserID     uint   `bson:"user_id"`
+	Reason     string `bson:"reason"`
+	CreateDate string `bson:"create_date"`
+}
+
+type ReportedItemEntry struct {
+	ID        string         `bson:"_id"`
+	Type      string         `bson:"type"`
+	TargetID  string         `bson:"target_id"`
+	PostID    string         `bson:"post_id"`
+	Community string         `bson:"community"`
+	Count     int            `bson:"count"`
+	Reports   []*ReportEntry `bson:"reports"`
+}
+
+type MongoReportRepo struct {
+	Reports *mongo.Collection
+	DB      *mongo.Database
+}
+
+var _ ReportRepo = (*MongoReportRepo)(nil)
+
+func NewMongoReportRepo(db *mongo.Database) *MongoReportRepo {
+	collection := db.Collection("reports")
+
+	return &MongoReportRepo{
+		Reports: collection,
+		DB:      db,
+	}
+}
+
+func (r *MongoReportRepo) Add(target Target, userID uint, reason string) error {
+	// a second report from the same user misses the filter, and the upsert
+	// then collides with the existing _id
+	filter := bson.M{
+		"_id":             reportKey(target.Type, target.ID),
+		"reports.user_id": bson.M{"$ne": userID},
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"type":      target.Type,
+			"target_id": target.ID,
+			"post_id":   target.PostID,
+			"community": target.Community,
+		},
+		"$push": bson.M{"reports": &ReportEntry{
+			UserID:     userID,
+			Reason:     reason,
+			CreateDate: time.Now().Format(time.RFC3339),
+		}},
+		"$inc": bson.M{"count": 1},
+	}
+
+	_, err := r.Reports.UpdateOne(context.TODO(), filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAlreadyReported
+	}
+
+	return err
+}
+
+func (r *MongoReportRepo) GetQueue(name string) ([]*ReportedItem, error) {
+	var entries []*ReportedItemEntry
+
+	option := options.Find().SetSort(bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := r.Reports.Find(context.TODO(), bson.M{"community": name}, option)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*ReportedItem, 0, len(entries))
+	for _, entry := range entries {
+		item := &ReportedItem{
+			Target: Target{
+				Type:      entry.Type,
+				ID:        entry.TargetID,
+				PostID:    entry.PostID,
+				Community: entry.Community,
+			},
+			Count:   entry.Count,
+			Reports: make([]*Report, 0, len(entry.Reports)),
+		}
+		for _, report := range entry.Reports {
+			item.Reports = append(item.Reports, &Report{
+				UserID:     report.UserID,
+				Reason:     report.Reason,
+				CreateDate: report.CreateDate,
+			})
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *MongoReportRepo) Clear(targetType string, targetID string) error {
+	res, err := r.Reports.DeleteOne(context.TODO(), bson.M{"_id": reportKey(targetType, targetID)})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrReportNotExist
+	}
+
+	return nil
+}
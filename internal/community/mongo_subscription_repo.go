@@ -0,0 +1,65 @@
+package community
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SubscriptionItem struct {
+	UserID      uint     `bson:"_id"`
+	Communities []string `bson:"communities"`
+}
+
+type MongoSubscriptionRepo struct {
+	Subscriptions *mongo.Collection
+	DB            *mongo.Database
+}
+
+var _ SubscriptionRepo = (*MongoSubscriptionRepo)(nil)
+
+func NewMongoSubscriptionRepo(db *mongo.Database) *MongoSubscriptionRepo {
+	collection := db.Collection("subscriptions")
+
+	return &MongoSubscriptionRepo{
+		Subscriptions: collection,
+		DB:            db,
+	}
+}
+
+func (r *MongoSubscriptionRepo) Subscribe(userID uint, name string) error {
+	update := bson.M{"$addToSet": bson.M{"communities": name}}
+	_, err := r.Subscriptions.UpdateOne(context.TODO(), bson.M{"_id": userID}, update, options.Update().SetUpsert(true))
+
+	return err
+}
+
+func (r *MongoSubscriptionRepo) Unsubscribe(userID uint, name string) error {
+	update := bson.M{"$pull": bson.M{"communities": name}}
+	res, err := r.Subscriptions.UpdateOne(context.TODO(), bson.M{"_id": userID, "communities": name}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotSubscribed
+	}
+
+	return nil
+}
+
+func (r *MongoSubscriptionRepo) GetByUser(userID uint) ([]string, error) {
+	item := &SubscriptionItem{}
+	err := r.Subscriptions.FindOne(context.TODO(), bson.M{"_id": userID}).Decode(item)
+	if err == mongo.ErrNoDocuments {
+		return make([]string, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(item.Communities))
+	names = append(names, item.Communities...)
+
+	return names, nil
+}
@@ -0,0 +1,57 @@
+package community
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+
+	MaxReportReasonLength = 300
+)
+
+var (
+	ErrReportNotExist      = errors.New("no reports for specified item")
+	ErrAlreadyReported     = errors.New("item already reported by user")
+	ErrInvalidReportReason = errors.New("report reason must be non-empty and shorter than 300 characters")
+)
+
+type Report struct {
+	UserID     uint   `json:"-"`
+	Reason     string `json:"reason"`
+	CreateDate string `json:"created"`
+}
+
+type Target struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	PostID    string `json:"post"`
+	Community string `json:"community"`
+}
+
+type ReportedItem struct {
+	Target
+	Count   int       `json:"count"`
+	Reports []*Report `json:"reports"`
+}
+
+type ReportRepo interface {
+	Add(target Target, userID uint, reason string) error
+	GetQueue(name string) ([]*ReportedItem, error)
+	Clear(targetType string, targetID string) error
+}
+
+func NormalizeReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > MaxReportReasonLength {
+		return "", ErrInvalidReportReason
+	}
+
+	return reason, nil
+}
+
+func reportKey(targetType string, targetID string) string {
+	return targetType + ":" + targetID
+}
@@ -0,0 +1,13 @@
+package community
+
+import (
+	"errors"
+)
+
+var ErrNotSubscribed = errors.New("not subscribed to community")
+
+type SubscriptionRepo interface {
+	Subscribe(userID uint, name string) error
+	Unsubscribe(userID uint, name string) error
+	GetByUser(userID uint) ([]string, error)
+}
@@ -0,0 +1,47 @@
+package event
+
+import (
+	"strconv"
+)
+
+const (
+	TypePostAdded      = "post_added"
+	TypePostDeleted    = "post_deleted"
+	TypePostVoted      = "post_voted"
+	TypeCommentAdded   = "comment_added"
+	TypeCommentDeleted = "comment_deleted"
+	TypeCommentVoted   = "comment_voted"
+	TypeNotification   = "notification"
+
+	// TopicAll carries the post level events of every community.
+	TopicAll = "all"
+)
+
+type Event struct {
+	Type      string      `json:"type"`
+	PostID    string      `json:"post,omitempty"`
+	CommentID string      `json:"comment,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+type VoteData struct {
+	Score          int `json:"score"`
+	UpvotesCount   int `json:"upvotes"`
+	DownvotesCount int `json:"downvotes"`
+}
+
+type Publisher interface {
+	Publish(e *Event, topics ...string)
+}
+
+func PostTopic(id string) string {
+	return "post:" + id
+}
+
+func CommunityTopic(name string) string {
+	return "community:" + name
+}
+
+func UserTopic(id uint) string {
+	return "user:" + strconv.Itoa(int(id))
+}
@@ -0,0 +1,88 @@
+package event
+
+import (
+	"sync"
+)
+
+const DefaultBufferSize = 64
+
+type Subscription struct {
+	C      <-chan *Event
+	ch     chan *Event
+	topics []string
+}
+
+// Hub fans events out to the subscriptions of their topics. Publishing never
+// blocks: a subscriber whose buffer is full misses the event.
+type Hub struct {
+	bufferSize    int
+	subscriptions map[string]map[*Subscription]struct{}
+	mu            *sync.RWMutex
+}
+
+var _ Publisher = (*Hub)(nil)
+
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &Hub{
+		bufferSize:    bufferSize,
+		subscriptions: make(map[string]map[*Subscription]struct{}),
+		mu:            &sync.RWMutex{},
+	}
+}
+
+func (h *Hub) Subscribe(topics ...string) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan *Event, h.bufferSize)
+	s := &Subscription{
+		C:      ch,
+		ch:     ch,
+		topics: topics,
+	}
+	for _, topic := range topics {
+		if h.subscriptions[topic] == nil {
+			h.subscriptions[topic] = make(map[*Subscription]struct{})
+		}
+		h.subscriptions[topic][s] = struct{}{}
+	}
+
+	return s
+}
+
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range s.topics {
+		delete(h.subscriptions[topic], s)
+		if len(h.subscriptions[topic]) == 0 {
+			delete(h.subscriptions, topic)
+		}
+	}
+}
+
+// Publish delivers e once to every subscription of any of the topics.
+func (h *Hub) Publish(e *Event, topics ...string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	delivered := make(map[*Subscription]struct{})
+	for _, topic := range topics {
+		for s := range h.subscriptions[topic] {
+			if _, ok := delivered[s]; ok {
+				continue
+			}
+			delivered[s] = struct{}{}
+
+			select {
+			case s.ch <- e:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package limiter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindUsername Kind = "user"
+	KindIP       Kind = "ip"
+	KindEmail    Kind = "email"
+
+	// Retention is how long a counter is kept, it has to cover the longest
+	// Window of the policies below.
+	Retention = 24 * time.Hour
+)
+
+// Policy lets Free attempts through, then makes the caller wait Delay,
+// doubled with every attempt up to MaxDelay. Lockout attempts lock the key for
+// LockoutDuration. Attempts older than Window are forgotten.
+type Policy struct {
+	Free            int
+	Delay           time.Duration
+	MaxDelay        time.Duration
+	Lockout         int
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+var (
+	LoginPolicies = map[Kind]Policy{
+		KindUsername: {
+			Free:            3,
+			Delay:           time.Second,
+			MaxDelay:        5 * time.Minute,
+			Lockout:         10,
+			LockoutDuration: 15 * time.Minute,
+			Window:          time.Hour,
+		},
+		// looser, everyone behind one NAT shares the address
+		KindIP: {
+			Free:            20,
+			Delay:           time.Second,
+			MaxDelay:        5 * time.Minute,
+			Lockout:         100,
+			LockoutDuration: time.Hour,
+			Window:          2 * time.Hour,
+		},
+	}
+	RegisterPolicies = map[Kind]Policy{
+		KindIP: {
+			Free:            5,
+			Delay:           time.Minute,
+			MaxDelay:        time.Hour,
+			Lockout:         20,
+			LockoutDuration: 12 * time.Hour,
+			Window:          Retention,
+		},
+	}
+	// every request sends a mail and replaces the previous link, so only a
+	// few of them are let through
+	ResetPolicies = map[Kind]Policy{
+		KindEmail: {
+			Free:            2,
+			Delay:           5 * time.Minute,
+			MaxDelay:        time.Hour,
+			Lockout:         10,
+			LockoutDuration: 12 * time.Hour,
+			Window:          Retention,
+		},
+		KindIP: {
+			Free:            5,
+			Delay:           time.Minute,
+			MaxDelay:        time.Hour,
+			Lockout:         30,
+			LockoutDuration: 12 * time.Hour,
+			Window:          Retention,
+		},
+	}
+)
+
+type Attempts struct {
+	Key   string
+	Count int
+	Last  int64
+	// Prev is the attempt before Last, zero when Last started the count
+	Prev int64
+}
+
+// AttemptRepo is the counter store, a shared one makes the limits hold across
+// instances.
+type AttemptRepo interface {
+	Get(key string) (*Attempts, error)
+	// Add counts an attempt at now, counting starts over when the last one
+	// was before since. The returned Count is taken by the same atomic update,
+	// so concurrent attempts never get the same number.
+	Add(key string, now time.Time, since time.Time) (*Attempts, error)
+	// Undo takes back one attempt counted by Add.
+	Undo(key string) error
+	Reset(key string) error
+	Prune(before time.Time) error
+}
+
+type Key struct {
+	Kind Kind
+	ID   string
+}
+
+type Limiter struct {
+	Repo     AttemptRepo
+	Name     string
+	Policies map[Kind]Policy
+}
+
+func NewLimiter(repo AttemptRepo, name string, policies map[Kind]Policy) *Limiter {
+	return &Limiter{
+		Repo:     repo,
+		Name:     name,
+		Policies: policies,
+	}
+}
+
+// Username keys on the hash of the name, the name comes straight from the
+// request before any lookup and may be of any length.
+func Username(username string) Key {
+	return Key{
+		Kind: KindUsername,
+		ID:   hashID(username),
+	}
+}
+
+func IP(ip string) Key {
+	return Key{
+		Kind: KindIP,
+		ID:   ip,
+	}
+}
+
+// Email keys on the hash of the address, which keeps the key short and the
+// addresses out of the counter store.
+func Email(email string) Key {
+	return Key{
+		Kind: KindEmail,
+		ID:   hashID(strings.ToLower(email)),
+	}
+}
+
+func hashID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Until is when the next attempt is allowed after a.
+func (p Policy) Until(a *Attempts) time.Time {
+	last := time.Unix(a.Last, 0)
+	if a.Count <= p.Free {
+		return last
+	}
+	if p.Lockout > 0 && a.Count >= p.Lockout {
+		return last.Add(p.LockoutDuration)
+	}
+
+	delay := p.Delay
+	for i := p.Free + 1; i < a.Count && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return last.Add(delay)
+}
+
+func (l *Limiter) key(k Key) string {
+	return l.Name + ":" + string(k.Kind) + ":" + k.ID
+}
+
+// Wait returns how long the caller has to wait before the next attempt, the
+// longest wait of keys wins.
+func (l *Limiter) Wait(now time.Time, keys ...Key) (time.Duration, error) {
+	var wait time.Duration
+	for _, k := range keys {
+		p, ok := l.Policies[k.Kind]
+		if !ok {
+			continue
+		}
+
+		a, err := l.Repo.Get(l.key(k))
+		if err != nil {
+			return 0, err
+		}
+		if a.Count == 0 || now.After(time.Unix(a.Last, 0).Add(p.Window)) {
+			continue
+		}
+		if d := p.Until(a).Sub(now); d > wait {
+			wait = d
+		}
+	}
+
+	return wait, nil
+}
+
+func (l *Limiter) Add(now time.Time, keys ...Key) error {
+	for _, k := range keys {
+		p, ok := l.Policies[k.Kind]
+		if !ok {
+			continue
+		}
+
+		_, err := l.Repo.Add(l.key(k), now, now.Add(-p.Window))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reserve counts an attempt before it is made and returns how long the caller
+// has to wait instead, the longest wait of keys wins. An attempt refused by Wait
+// isn't counted, so the wait holds; one that passes is decided again on the
+// count Add returns, which stops a burst that slipped between Wait and Add.
+func (l *Limiter) Reserve(now time.Time, keys ...Key) (time.Duration, error) {
+	wait, err := l.Wait(now, keys...)
+	if err != nil || wait > 0 {
+		return wait, err
+	}
+
+	for _, k := range keys {
+		p, ok := l.Policies[k.Kind]
+		if !ok {
+			continue
+		}
+
+		a, err := l.Repo.Add(l.key(k), now, now.Add(-p.Window))
+		if err != nil {
+			return 0, err
+		}
+		if a.Count <= 1 {
+			continue
+		}
+
+		before := &Attempts{
+			Key:   a.Key,
+			Count: a.Count - 1,
+			Last:  a.Prev,
+		}
+		if d := p.Until(before).Sub(now); d > wait {
+			wait = d
+		}
+	}
+
+	return wait, nil
+}
+
+// Release gives back the attempts reserved for keys, for the ones which
+// turned out not to be failures.
+func (l *Limiter) Release(keys ...Key) error {
+	for _, k := range keys {
+		if _, ok := l.Policies[k.Kind]; !ok {
+			continue
+		}
+
+		err := l.Repo.Undo(l.key(k))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Limiter) Reset(keys ...Key) error {
+	for _, k := range keys {
+		err := l.Repo.Reset(l.key(k))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
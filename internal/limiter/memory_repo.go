@@ -0,0 +1,83 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryRepo struct {
+	attempts map[string]*Attempts
+	mu       *sync.Mutex
+}
+
+var _ AttemptRepo = (*MemoryRepo)(nil)
+
+func NewMemoryRepo() *MemoryRepo {
+	return &MemoryRepo{
+		attempts: make(map[string]*Attempts),
+		mu:       &sync.Mutex{},
+	}
+}
+
+func (r *MemoryRepo) Get(key string) (*Attempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		return &Attempts{Key: key}, nil
+	}
+	copyAttempts := *a
+
+	return &copyAttempts, nil
+}
+
+func (r *MemoryRepo) Add(key string, now time.Time, since time.Time) (*Attempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok || a.Last < since.Unix() {
+		a = &Attempts{Key: key}
+		r.attempts[key] = a
+	}
+	a.Count++
+	a.Prev = a.Last
+	a.Last = now.Unix()
+	copyAttempts := *a
+
+	return &copyAttempts, nil
+}
+
+func (r *MemoryRepo) Undo(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a, ok := r.attempts[key]; ok && a.Count > 0 {
+		a.Count--
+	}
+
+	return nil
+}
+
+func (r *MemoryRepo) Reset(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+
+	return nil
+}
+
+func (r *MemoryRepo) Prune(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, a := range r.attempts {
+		if a.Last < before.Unix() {
+			delete(r.attempts, key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package limiter
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MySQLRepo struct {
+	DB *sql.DB
+}
+
+var _ AttemptRepo = (*MySQLRepo)(nil)
+
+func NewMySQLRepo(db *sql.DB) *MySQLRepo {
+	return &MySQLRepo{
+		DB: db,
+	}
+}
+
+func (r *MySQLRepo) Get(key string) (*Attempts, error) {
+	a := &Attempts{Key: key}
+	row := r.DB.QueryRow(
+		"SELECT `count`, `last_attempt`, `prev_attempt` FROM attempts WHERE `key` = ?",
+		key,
+	)
+	err := row.Scan(&a.Count, &a.Last, &a.Prev)
+	if err == sql.ErrNoRows {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Add counts in a single upsert, so concurrent instances don't lose attempts,
+// and LAST_INSERT_ID hands the count of this very update back to the caller.
+// The columns are assigned in order, so `count` and `prev_attempt` still see
+// the old `last_attempt`.
+func (r *MySQLRepo) Add(key string, now time.Time, since time.Time) (*Attempts, error) {
+	result, err := r.DB.Exec(
+		"INSERT INTO attempts (`key`, `count`, `last_attempt`, `prev_attempt`) VALUES (?, LAST_INSERT_ID(1), ?, 0) "+
+			"ON DUPLICATE KEY UPDATE `count` = LAST_INSERT_ID(IF(`last_attempt` < ?, 1, `count` + 1)), "+
+			"`prev_attempt` = IF(`last_attempt` < ?, 0, `last_attempt`), `last_attempt` = VALUES(`last_attempt`)",
+		key,
+		now.Unix(),
+		since.Unix(),
+		since.Unix(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	count, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	// Prev may already be a later concurrent attempt, which only makes the
+	// decision stricter
+	a, err := r.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	a.Count = int(count)
+	a.Last = now.Unix()
+
+	return a, nil
+}
+
+func (r *MySQLRepo) Undo(key string) error {
+	_, err := r.DB.Exec(
+		"UPDATE attempts SET `count` = GREATEST(`count` - 1, 0) WHERE `key` = ?",
+		key,
+	)
+
+	return err
+}
+
+func (r *MySQLRepo) Reset(key string) error {
+	_, err := r.DB.Exec(
+		"DELETE FROM attempts WHERE `key` = ?",
+		key,
+	)
+
+	return err
+}
+
+func (r *MySQLRepo) Prune(before time.Time) error {
+	_, err := r.DB.Exec(
+		"DELETE FROM attempts WHERE `last_attempt` < ?",
+		before.Unix(),
+	)
+
+	return err
+}
@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to W instead of delivering them, for local
+// development and tests.
+type LogMailer struct {
+	W  io.Writer
+	mu *sync.Mutex
+}
+
+var _ Mailer = (*LogMailer)(nil)
+
+func NewLogMailer(w io.Writer) *LogMailer {
+	return &LogMailer{
+		W:  w,
+		mu: &sync.Mutex{},
+	}
+}
+
+func (m *LogMailer) Send(msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.W, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+
+	return err
+}
@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+type SMTPMailer struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+var _ Mailer = (*SMTPMailer)(nil)
+
+// NewSMTPMailer authenticates with PLAIN auth when a username is given.
+func NewSMTPMailer(host string, port int, username string, password string, from string) *SMTPMailer {
+	m := &SMTPMailer{
+		Addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		From: from,
+	}
+	if username != "" {
+		m.Auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return m
+}
+
+func (m *SMTPMailer) Send(msg *Message) error {
+	return smtp.SendMail(m.Addr, m.Auth, m.From, []string{msg.To}, formatMessage(m.From, msg))
+}
+
+func formatMessage(from string, msg *Message) []byte {
+	// header values come from our own templates, newlines are still dropped so
+	// an address can't smuggle in extra headers
+	clean := strings.NewReplacer("\r", "", "\n", "")
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "From: %s\r\n", clean.Replace(from))
+	fmt.Fprintf(b, "To: %s\r\n", clean.Replace(msg.To))
+	fmt.Fprintf(b, "Subject: %s\r\n", clean.Replace(msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
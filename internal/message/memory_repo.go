@@ -0,0 +1,151 @@
+package message
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryMessage struct {
+	Message
+	deletedBySender    bool
+	deletedByRecipient bool
+}
+
+type MemoryRepo struct {
+	idCount  uint
+	messages []*memoryMessage
+	mu       *sync.RWMutex
+}
+
+var _ MessageRepo = (*MemoryRepo)(nil)
+
+func NewMemoryRepo() *MemoryRepo {
+	return &MemoryRepo{
+		messages: make([]*memoryMessage, 0),
+		mu:       &sync.RWMutex{},
+	}
+}
+
+func (m *memoryMessage) visibleTo(userID uint) bool {
+	return (m.SenderID == userID && !m.deletedBySender) || (m.RecipientID == userID && !m.deletedByRecipient)
+}
+
+func (r *MemoryRepo) Create(m *Message) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.idCount++
+	m.ID = r.idCount
+	m.CreateDate = time.Now().Format(time.RFC3339)
+	m.Read = false
+	r.messages = append(r.messages, &memoryMessage{Message: *m})
+
+	return m.ID, nil
+}
+
+func (r *MemoryRepo) GetByID(id uint, userID uint) (*Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range r.messages {
+		if m.ID != id || !m.visibleTo(userID) {
+			continue
+		}
+
+		copyMessage := m.Message
+		return &copyMessage, nil
+	}
+
+	return nil, ErrNotExist
+}
+
+func (r *MemoryRepo) find(match func(m *memoryMessage) bool) []*Message {
+	messages := make([]*Message, 0)
+	for i := len(r.messages) - 1; i >= 0; i-- {
+		if !match(r.messages[i]) {
+			continue
+		}
+
+		copyMessage := r.messages[i].Message
+		messages = append(messages, &copyMessage)
+	}
+
+	return messages
+}
+
+func (r *MemoryRepo) GetInbox(userID uint) ([]*Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.find(func(m *memoryMessage) bool {
+		return m.RecipientID == userID && !m.deletedByRecipient
+	}), nil
+}
+
+func (r *MemoryRepo) GetSent(userID uint) ([]*Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.find(func(m *memoryMessage) bool {
+		return m.SenderID == userID && !m.deletedBySender
+	}), nil
+}
+
+func (r *MemoryRepo) GetThread(threadID uint, userID uint) ([]*Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	messages := r.find(func(m *memoryMessage) bool {
+		return m.Thread() == threadID && m.visibleTo(userID)
+	})
+	if len(messages) == 0 {
+		return nil, ErrNotExist
+	}
+
+	// oldest first, the way a conversation is read
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+func (r *MemoryRepo) MarkRead(id uint, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.messages {
+		if m.ID != id || !m.visibleTo(userID) {
+			continue
+		}
+		if m.RecipientID != userID {
+			return ErrNoAccess
+		}
+
+		m.Read = true
+		return nil
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) Delete(id uint, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.messages {
+		if m.ID != id || !m.visibleTo(userID) {
+			continue
+		}
+
+		if m.SenderID == userID {
+			m.deletedBySender = true
+		}
+		if m.RecipientID == userID {
+			m.deletedByRecipient = true
+		}
+		return nil
+	}
+
+	return ErrNotExist
+}
@@ -0,0 +1,45 @@
+package message
+
+import (
+	"errors"
+)
+
+// MaxSubjectLength is in runes, as the subject column counts characters.
+const MaxSubjectLength = 300
+
+var (
+	ErrNotExist  = errors.New("message with specified id not exist")
+	ErrNoAccess  = errors.New("hasn`t access to message")
+	ErrInvalidID = errors.New("message id is invalid")
+)
+
+type Message struct {
+	ID          uint
+	ThreadID    uint
+	SenderID    uint
+	RecipientID uint
+	Subject     string
+	Body        string
+	CreateDate  string
+	Read        bool
+}
+
+// A message starts its own thread when ThreadID is zero, replies carry the
+// id of the first message. Messages deleted by a user are hidden only for them.
+type MessageRepo interface {
+	Create(m *Message) (uint, error)
+	GetByID(id uint, userID uint) (*Message, error)
+	GetInbox(userID uint) ([]*Message, error)
+	GetSent(userID uint) ([]*Message, error)
+	GetThread(threadID uint, userID uint) ([]*Message, error)
+	MarkRead(id uint, userID uint) error
+	Delete(id uint, userID uint) error
+}
+
+func (m *Message) Thread() uint {
+	if m.ThreadID == 0 {
+		return m.ID
+	}
+
+	return m.ThreadID
+}
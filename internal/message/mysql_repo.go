@@ -0,0 +1,166 @@
+package message
+
+import (
+	"database/sql"
+	_ "github.com/go-sql-driver/mysql"
+	"time"
+)
+
+const (
+	messageColumns = "id, thread_id, sender_id, recipient_id, subject, body, create_date, is_read"
+	visibleTo      = "((sender_id = ? AND deleted_by_sender = 0) OR (recipient_id = ? AND deleted_by_recipient = 0))"
+)
+
+type MySQLRepo struct {
+	DB *sql.DB
+}
+
+var _ MessageRepo = (*MySQLRepo)(nil)
+
+func NewMySQLRepo(db *sql.DB) *MySQLRepo {
+	return &MySQLRepo{
+		DB: db,
+	}
+}
+
+func (r *MySQLRepo) Create(m *Message) (uint, error) {
+	m.CreateDate = time.Now().Format(time.RFC3339)
+	m.Read = false
+
+	result, err := r.DB.Exec(
+		"INSERT INTO messages (`thread_id`, `sender_id`, `recipient_id`, `subject`, `body`, `create_date`) VALUES (?, ?, ?, ?, ?, ?)",
+		m.ThreadID,
+		m.SenderID,
+		m.RecipientID,
+		m.Subject,
+		m.Body,
+		m.CreateDate,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	m.ID = uint(id)
+
+	return m.ID, nil
+}
+
+func scanMessages(rows *sql.Rows) ([]*Message, error) {
+	defer rows.Close()
+
+	messages := make([]*Message, 0)
+	for rows.Next() {
+		m := &Message{}
+		err := rows.Scan(&m.ID, &m.ThreadID, &m.SenderID, &m.RecipientID, &m.Subject, &m.Body, &m.CreateDate, &m.Read)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *MySQLRepo) GetByID(id uint, userID uint) (*Message, error) {
+	row := r.DB.QueryRow(
+		"SELECT "+messageColumns+" FROM messages WHERE id = ? AND "+visibleTo,
+		id,
+		userID,
+		userID,
+	)
+
+	m := &Message{}
+	err := row.Scan(&m.ID, &m.ThreadID, &m.SenderID, &m.RecipientID, &m.Subject, &m.Body, &m.CreateDate, &m.Read)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (r *MySQLRepo) GetInbox(userID uint) ([]*Message, error) {
+	rows, err := r.DB.Query(
+		"SELECT "+messageColumns+" FROM messages WHERE recipient_id = ? AND deleted_by_recipient = 0 ORDER BY id DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+func (r *MySQLRepo) GetSent(userID uint) ([]*Message, error) {
+	rows, err := r.DB.Query(
+		"SELECT "+messageColumns+" FROM messages WHERE sender_id = ? AND deleted_by_sender = 0 ORDER BY id DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+func (r *MySQLRepo) GetThread(threadID uint, userID uint) ([]*Message, error) {
+	rows, err := r.DB.Query(
+		"SELECT "+messageColumns+" FROM messages WHERE (id = ? OR thread_id = ?) AND "+visibleTo+" ORDER BY id",
+		threadID,
+		threadID,
+		userID,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrNotExist
+	}
+
+	return messages, nil
+}
+
+func (r *MySQLRepo) MarkRead(id uint, userID uint) error {
+	m, err := r.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+	if m.RecipientID != userID {
+		return ErrNoAccess
+	}
+
+	_, err = r.DB.Exec(
+		"UPDATE messages SET is_read = 1 WHERE id = ?",
+		id,
+	)
+
+	return err
+}
+
+func (r *MySQLRepo) Delete(id uint, userID uint) error {
+	_, err := r.GetByID(id, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Exec(
+		"UPDATE messages SET deleted_by_sender = IF(sender_id = ?, 1, deleted_by_sender), deleted_by_recipient = IF(recipient_id = ?, 1, deleted_by_recipient) WHERE id = ?",
+		userID,
+		userID,
+		id,
+	)
+
+	return err
+}
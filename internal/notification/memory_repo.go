@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+type MemoryRepo struct {
+	idCount       uint
+	notifications []*Notification
+	mu            *sync.RWMutex
+}
+
+var _ NotificationRepo = (*MemoryRepo)(nil)
+
+func NewMemoryRepo() *MemoryRepo {
+	return &MemoryRepo{
+		notifications: make([]*Notification, 0),
+		mu:            &sync.RWMutex{},
+	}
+}
+
+func (r *MemoryRepo) Add(n *Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n.Key != "" {
+		for _, existing := range r.notifications {
+			if existing.UserID == n.UserID && existing.Key == n.Key {
+				return ErrAlreadyExist
+			}
+		}
+	}
+
+	r.idCount++
+	n.ID = strconv.Itoa(int(r.idCount))
+	n.CreateDate = time.Now().Format(time.RFC3339)
+	copyNotification := *n
+	r.notifications = append(r.notifications, &copyNotification)
+
+	return nil
+}
+
+func (r *MemoryRepo) GetByUser(userID uint, filter Filter) ([]*Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit := filter.limit()
+	notifications := make([]*Notification, 0)
+	for i := len(r.notifications) - 1; i >= 0 && len(notifications) < limit; i-- {
+		n := r.notifications[i]
+		if n.UserID != userID || (filter.UnreadOnly && n.Read) {
+			continue
+		}
+		copyNotification := *n
+		notifications = append(notifications, &copyNotification)
+	}
+
+	return notifications, nil
+}
+
+func (r *MemoryRepo) CountUnread(userID uint) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, n := range r.notifications {
+		if n.UserID == userID && !n.Read {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *MemoryRepo) MarkRead(id string, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.notifications {
+		if n.ID == id && n.UserID == userID {
+			n.Read = true
+			return nil
+		}
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) MarkAllRead(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.notifications {
+		if n.UserID == userID {
+			n.Read = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type NotificationItem struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	UserID     uint               `bson:"user_id"`
+	Type       string             `bson:"type"`
+	ActorID    uint               `bson:"actor_id,omitempty"`
+	PostID     string             `bson:"post_id"`
+	CommentID  string             `bson:"comment_id,omitempty"`
+	Score      int                `bson:"score,omitempty"`
+	Key        string             `bson:"key,omitempty"`
+	Read       bool               `bson:"read"`
+	CreateDate string             `bson:"create_date"`
+}
+
+type MongoRepo struct {
+	Notifications *mongo.Collection
+	DB            *mongo.Database
+}
+
+var _ NotificationRepo = (*MongoRepo)(nil)
+
+func NewMongoRepo(db *mongo.Database) *MongoRepo {
+	collection := db.Collection("notifications")
+
+	return &MongoRepo{
+		Notifications: collection,
+		DB:            db,
+	}
+}
+
+func (r *MongoRepo) Add(n *Notification) error {
+	item := &NotificationItem{
+		ID:         primitive.NewObjectID(),
+		UserID:     n.UserID,
+		Type:       n.Type,
+		ActorID:    n.ActorID,
+		PostID:     n.PostID,
+		CommentID:  n.CommentID,
+		Score:      n.Score,
+		Key:        n.Key,
+		CreateDate: time.Now().Format(time.RFC3339),
+	}
+
+	if n.Key == "" {
+		_, err := r.Notifications.InsertOne(context.TODO(), item)
+		if err != nil {
+			return err
+		}
+	} else {
+		filter := bson.M{"user_id": n.UserID, "key": n.Key}
+		update := bson.M{"$setOnInsert": item}
+		res, err := r.Notifications.UpdateOne(context.TODO(), filter, update, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		if res.UpsertedCount == 0 {
+			return ErrAlreadyExist
+		}
+	}
+	n.ID = item.ID.Hex()
+	n.CreateDate = item.CreateDate
+
+	return nil
+}
+
+func (r *MongoRepo) GetByUser(userID uint, filter Filter) ([]*Notification, error) {
+	var items []*NotificationItem
+
+	query := bson.M{"user_id": userID}
+	if filter.UnreadOnly {
+		query["read"] = false
+	}
+
+	option := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(filter.limit()))
+	cursor, err := r.Notifications.Find(context.TODO(), query, option)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]*Notification, 0, len(items))
+	for _, item := range items {
+		notifications = append(notifications, &Notification{
+			ID:         item.ID.Hex(),
+			UserID:     item.UserID,
+			Type:       item.Type,
+			ActorID:    item.ActorID,
+			PostID:     item.PostID,
+			CommentID:  item.CommentID,
+			Score:      item.Score,
+			Key:        item.Key,
+			Read:       item.Read,
+			CreateDate: item.CreateDate,
+		})
+	}
+
+	return notifications, nil
+}
+
+func (r *MongoRepo) CountUnread(userID uint) (int, error) {
+	count, err := r.Notifications.CountDocuments(context.TODO(), bson.M{"user_id": userID, "read": false})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (r *MongoRepo) MarkRead(id string, userID uint) error {
+	itemID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	filter := bson.M{"_id": itemID, "user_id": userID}
+	res, err := r.Notifications.UpdateOne(context.TODO(), filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotExist
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) MarkAllRead(userID uint) error {
+	filter := bson.M{"user_id": userID, "read": false}
+	_, err := r.Notifications.UpdateMany(context.TODO(), filter, bson.M{"$set": bson.M{"read": true}})
+
+	return err
+}
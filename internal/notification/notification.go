@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+const (
+	TypePostReply    = "post_reply"
+	TypeCommentReply = "comment_reply"
+	TypeMention      = "mention"
+	TypeMilestone    = "milestone"
+
+	DefaultLimit = 50
+	MaxLimit     = 200
+	MaxMentions  = 10
+)
+
+var (
+	ErrNotExist     = errors.New("notification with specified id not exist")
+	ErrAlreadyExist = errors.New("notification already exist")
+	ErrInvalidID    = errors.New("notification id is invalid")
+)
+
+// Milestones are the post and comment scores the author is told about.
+var Milestones = []int{10, 50, 100, 500, 1000, 5000, 10000}
+
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w/])/?u/([\w-]{1,32})`)
+
+// Key is optional. A notification whose key the user already has is
+// rejected with ErrAlreadyExist, which keeps milestones from repeating when
+// a score goes down and up again.
+type Notification struct {
+	ID         string
+	UserID     uint
+	Type       string
+	ActorID    uint
+	PostID     string
+	CommentID  string
+	Score      int
+	Key        string
+	Read       bool
+	CreateDate string
+}
+
+type Filter struct {
+	UnreadOnly bool
+	Limit      int
+}
+
+type NotificationRepo interface {
+	Add(n *Notification) error
+	GetByUser(userID uint, filter Filter) ([]*Notification, error)
+	CountUnread(userID uint) (int, error)
+	MarkRead(id string, userID uint) error
+	MarkAllRead(userID uint) error
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultLimit
+	}
+	if f.Limit > MaxLimit {
+		return MaxLimit
+	}
+
+	return f.Limit
+}
+
+// ParseMentions returns the distinct usernames written as u/name or /u/name.
+func ParseMentions(text string) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		name := match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if len(names) == MaxMentions {
+			break
+		}
+	}
+
+	return names
+}
+
+// ReachedMilestone returns the highest milestone not above score, or 0.
+func ReachedMilestone(score int) int {
+	reached := 0
+	for _, m := range Milestones {
+		if score < m {
+			break
+		}
+		reached = m
+	}
+
+	return reached
+}
+
+func MilestoneKey(postID string, commentID string, milestone int) string {
+	return TypeMilestone + ":" + postID + ":" + commentID + ":" + strconv.Itoa(milestone)
+}
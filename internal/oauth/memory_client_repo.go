@@ -0,0 +1,98 @@
+package oauth
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type MemoryClientRepo struct {
+	clients map[string]*Client
+	mu      *sync.RWMutex
+}
+
+var _ ClientRepo = (*MemoryClientRepo)(nil)
+
+func NewMemoryClientRepo() *MemoryClientRepo {
+	return &MemoryClientRepo{
+		clients: make(map[string]*Client),
+		mu:      &sync.RWMutex{},
+	}
+}
+
+func copyClient(c *Client) *Client {
+	result := *c
+	result.RedirectURIs = append([]string(nil), c.RedirectURIs...)
+
+	return &result
+}
+
+func (r *MemoryClientRepo) Add(ownerID uint, name string, redirectURIs []string, public bool) (*Client, string, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &Client{
+		ID:           id,
+		Name:         name,
+		RedirectURIs: append([]string(nil), redirectURIs...),
+		OwnerID:      ownerID,
+		CreateDate:   time.Now().Format(time.RFC3339),
+	}
+	var secret string
+	if !public {
+		secret, client.SecretHash, err = newToken("")
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[id] = client
+
+	return copyClient(client), secret, nil
+}
+
+func (r *MemoryClientRepo) Get(id string) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, ok := r.clients[id]
+	if !ok {
+		return nil, ErrNoClient
+	}
+
+	return copyClient(client), nil
+}
+
+func (r *MemoryClientRepo) GetByOwner(ownerID uint) ([]*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := make([]*Client, 0)
+	for _, c := range r.clients {
+		if c.OwnerID == ownerID {
+			clients = append(clients, copyClient(c))
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].CreateDate < clients[j].CreateDate
+	})
+
+	return clients, nil
+}
+
+func (r *MemoryClientRepo) Delete(ownerID uint, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[id]
+	if !ok || client.OwnerID != ownerID {
+		return ErrNoClient
+	}
+	delete(r.clients, id)
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package oauth
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryCodeRepo struct {
+	Lifetime time.Duration
+	codes    map[string]*Code
+	mu       *sync.Mutex
+}
+
+var _ CodeRepo = (*MemoryCodeRepo)(nil)
+
+func NewMemoryCodeRepo(lifetime time.Duration) *MemoryCodeRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultCodeLifetime
+	}
+
+	return &MemoryCodeRepo{
+		Lifetime: lifetime,
+		codes:    make(map[string]*Code),
+		mu:       &sync.Mutex{},
+	}
+}
+
+func (r *MemoryCodeRepo) Add(c *Code) (string, error) {
+	code, hash, err := newToken("")
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for h, saved := range r.codes {
+		if saved.ExpirationDate < now.Unix() {
+			delete(r.codes, h)
+		}
+	}
+	saved := *c
+	saved.ExpirationDate = now.Add(r.Lifetime).Unix()
+	r.codes[hash] = &saved
+
+	return code, nil
+}
+
+func (r *MemoryCodeRepo) Consume(code string) (*Code, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash := hashToken(code)
+	c, ok := r.codes[hash]
+	if !ok {
+		return nil, ErrBadCode
+	}
+	delete(r.codes, hash)
+
+	if time.Now().Unix() > c.ExpirationDate {
+		return nil, ErrBadCode
+	}
+
+	return c, nil
+}
@@ -0,0 +1,181 @@
+package oauth
+
+import (
+	"github.com/vlasdash/redditclone/internal/session"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryToken struct {
+	grant          Grant
+	refresh        bool
+	expirationDate int64
+}
+
+type MemoryTokenRepo struct {
+	AccessLifetime  time.Duration
+	RefreshLifetime time.Duration
+	tokens          map[string]*memoryToken
+	mu              *sync.Mutex
+}
+
+var _ TokenRepo = (*MemoryTokenRepo)(nil)
+
+func NewMemoryTokenRepo(accessLifetime time.Duration, refreshLifetime time.Duration) *MemoryTokenRepo {
+	if accessLifetime <= 0 {
+		accessLifetime = DefaultAccessTokenLifetime
+	}
+	if refreshLifetime <= 0 {
+		refreshLifetime = DefaultRefreshTokenLifetime
+	}
+
+	return &MemoryTokenRepo{
+		AccessLifetime:  accessLifetime,
+		RefreshLifetime: refreshLifetime,
+		tokens:          make(map[string]*memoryToken),
+		mu:              &sync.Mutex{},
+	}
+}
+
+// addPair must be called with the lock held.
+func (r *MemoryTokenRepo) addPair(g Grant) (*TokenPair, error) {
+	access, accessHash, err := newToken(session.OAuthTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	refresh, refreshHash, err := newToken(RefreshTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	r.tokens[accessHash] = &memoryToken{grant: g, expirationDate: now.Add(r.AccessLifetime).Unix()}
+	r.tokens[refreshHash] = &memoryToken{grant: g, refresh: true, expirationDate: now.Add(r.RefreshLifetime).Unix()}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(r.AccessLifetime / time.Second),
+		Scopes:       g.Scopes,
+	}, nil
+}
+
+// deleteGrant must be called with the lock held.
+func (r *MemoryTokenRepo) deleteGrant(grantID string) {
+	for h, t := range r.tokens {
+		if t.grant.ID == grantID {
+			delete(r.tokens, h)
+		}
+	}
+}
+
+func (r *MemoryTokenRepo) Issue(g *Grant) (*TokenPair, error) {
+	grant := *g
+	if grant.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		grant.ID = id
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.addPair(grant)
+}
+
+func (r *MemoryTokenRepo) Verify(accessToken string) (*session.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[hashToken(accessToken)]
+	if !ok || t.refresh {
+		return nil, session.ErrBadToken
+	}
+	if time.Now().Unix() > t.expirationDate {
+		return nil, session.ErrTokenExpired
+	}
+
+	return &session.Session{
+		UserID:         t.grant.UserID,
+		Username:       t.grant.Username,
+		ClientID:       t.grant.ClientID,
+		Scopes:         append([]session.Scope(nil), t.grant.Scopes...),
+		ExpirationDate: strconv.FormatInt(t.expirationDate, 10),
+	}, nil
+}
+
+func (r *MemoryTokenRepo) Refresh(refreshToken string, clientID string) (*TokenPair, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[hashToken(refreshToken)]
+	if !ok || !t.refresh || t.grant.ClientID != clientID {
+		return nil, session.ErrBadToken
+	}
+	if time.Now().Unix() > t.expirationDate {
+		return nil, session.ErrTokenExpired
+	}
+
+	r.deleteGrant(t.grant.ID)
+
+	return r.addPair(t.grant)
+}
+
+func (r *MemoryTokenRepo) Revoke(token string, clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[hashToken(token)]
+	if ok && t.grant.ClientID == clientID {
+		r.deleteGrant(t.grant.ID)
+	}
+
+	return nil
+}
+
+func (r *MemoryTokenRepo) RevokeClient(userID uint, clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := false
+	for h, t := range r.tokens {
+		if t.grant.UserID == userID && t.grant.ClientID == clientID {
+			delete(r.tokens, h)
+			found = true
+		}
+	}
+	if !found {
+		return ErrNoGrant
+	}
+
+	return nil
+}
+
+func (r *MemoryTokenRepo) DeleteByClient(clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for h, t := range r.tokens {
+		if t.grant.ClientID == clientID {
+			delete(r.tokens, h)
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryTokenRepo) DeleteByUser(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for h, t := range r.tokens {
+		if t.grant.UserID == userID {
+			delete(r.tokens, h)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package oauth
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type MySQLClientRepo struct {
+	DB *sql.DB
+}
+
+var _ ClientRepo = (*MySQLClientRepo)(nil)
+
+func NewMySQLClientRepo(db *sql.DB) *MySQLClientRepo {
+	return &MySQLClientRepo{
+		DB: db,
+	}
+}
+
+func (r *MySQLClientRepo) Add(ownerID uint, name string, redirectURIs []string, public bool) (*Client, string, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &Client{
+		ID:           id,
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		OwnerID:      ownerID,
+		CreateDate:   time.Now().Format(time.RFC3339),
+	}
+	var secret string
+	if !public {
+		secret, client.SecretHash, err = newToken("")
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	_, err = r.DB.Exec(
+		"INSERT INTO oauth_clients (`id`, `secret_hash`, `name`, `redirect_uris`, `owner_id`, `create_date`) VALUES (?, ?, ?, ?, ?, ?)",
+		client.ID,
+		client.SecretHash,
+		client.Name,
+		strings.Join(client.RedirectURIs, "\n"),
+		client.OwnerID,
+		client.CreateDate,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+func (r *MySQLClientRepo) Get(id string) (*Client, error) {
+	row := r.DB.QueryRow(
+		"SELECT id, secret_hash, name, redirect_uris, owner_id, create_date FROM oauth_clients WHERE id = ?",
+		id,
+	)
+
+	client, err := scanClient(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoClient
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (r *MySQLClientRepo) GetByOwner(ownerID uint) ([]*Client, error) {
+	rows, err := r.DB.Query(
+		"SELECT id, secret_hash, name, redirect_uris, owner_id, create_date FROM oauth_clients WHERE owner_id = ? ORDER BY create_date",
+		ownerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := make([]*Client, 0)
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+func (r *MySQLClientRepo) Delete(ownerID uint, id string) error {
+	result, err := r.DB.Exec(
+		"DELETE FROM oauth_clients WHERE id = ? AND owner_id = ?",
+		id,
+		ownerID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoClient
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanClient(row scanner) (*Client, error) {
+	client := &Client{}
+	var redirectURIs string
+	err := row.Scan(&client.ID, &client.SecretHash, &client.Name, &redirectURIs, &client.OwnerID, &client.CreateDate)
+	if err != nil {
+		return nil, err
+	}
+	client.RedirectURIs = strings.Split(redirectURIs, "\n")
+
+	return client, nil
+}
@@ -0,0 +1,89 @@
+package oauth
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MySQLCodeRepo struct {
+	DB       *sql.DB
+	Lifetime time.Duration
+}
+
+var _ CodeRepo = (*MySQLCodeRepo)(nil)
+
+func NewMySQLCodeRepo(db *sql.DB, lifetime time.Duration) *MySQLCodeRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultCodeLifetime
+	}
+
+	return &MySQLCodeRepo{
+		DB:       db,
+		Lifetime: lifetime,
+	}
+}
+
+func (r *MySQLCodeRepo) Add(c *Code) (string, error) {
+	code, hash, err := newToken("")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.DB.Exec(
+		"INSERT INTO oauth_codes (`code_hash`, `client_id`, `user_id`, `username`, `redirect_uri`, `scopes`, `challenge`, `expiration_date`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		hash,
+		c.ClientID,
+		c.UserID,
+		c.Username,
+		c.RedirectURI,
+		joinScopes(c.Scopes),
+		c.Challenge,
+		time.Now().Add(r.Lifetime).Unix(),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (r *MySQLCodeRepo) Consume(code string) (*Code, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	hash := hashToken(code)
+	row := tx.QueryRow(
+		"SELECT client_id, user_id, username, redirect_uri, scopes, challenge, expiration_date FROM oauth_codes WHERE code_hash = ? FOR UPDATE",
+		hash,
+	)
+
+	c := &Code{}
+	var scopes string
+	err = row.Scan(&c.ClientID, &c.UserID, &c.Username, &c.RedirectURI, &scopes, &c.Challenge, &c.ExpirationDate)
+	if err == sql.ErrNoRows {
+		return nil, ErrBadCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Scopes = splitScopes(scopes)
+
+	_, err = tx.Exec("DELETE FROM oauth_codes WHERE code_hash = ?", hash)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if time.Now().Unix() > c.ExpirationDate {
+		return nil, ErrBadCode
+	}
+
+	return c, nil
+}
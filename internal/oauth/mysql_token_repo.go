@@ -0,0 +1,235 @@
+package oauth
+
+import (
+	"database/sql"
+	"github.com/vlasdash/redditclone/internal/session"
+	"strconv"
+	"time"
+)
+
+type MySQLTokenRepo struct {
+	DB              *sql.DB
+	AccessLifetime  time.Duration
+	RefreshLifetime time.Duration
+}
+
+var _ TokenRepo = (*MySQLTokenRepo)(nil)
+
+func NewMySQLTokenRepo(db *sql.DB, accessLifetime time.Duration, refreshLifetime time.Duration) *MySQLTokenRepo {
+	if accessLifetime <= 0 {
+		accessLifetime = DefaultAccessTokenLifetime
+	}
+	if refreshLifetime <= 0 {
+		refreshLifetime = DefaultRefreshTokenLifetime
+	}
+
+	return &MySQLTokenRepo{
+		DB:              db,
+		AccessLifetime:  accessLifetime,
+		RefreshLifetime: refreshLifetime,
+	}
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *MySQLTokenRepo) insertPair(db execer, g *Grant) (*TokenPair, error) {
+	access, accessHash, err := newToken(session.OAuthTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	refresh, refreshHash, err := newToken(RefreshTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tokens := []struct {
+		hash    string
+		refresh bool
+		exp     int64
+	}{
+		{hash: accessHash, refresh: false, exp: now.Add(r.AccessLifetime).Unix()},
+		{hash: refreshHash, refresh: true, exp: now.Add(r.RefreshLifetime).Unix()},
+	}
+	for _, t := range tokens {
+		_, err = db.Exec(
+			"INSERT INTO oauth_tokens (`token_hash`, `refresh`, `grant_id`, `client_id`, `user_id`, `username`, `scopes`, `expiration_date`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			t.hash,
+			t.refresh,
+			g.ID,
+			g.ClientID,
+			g.UserID,
+			g.Username,
+			joinScopes(g.Scopes),
+			t.exp,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(r.AccessLifetime / time.Second),
+		Scopes:       g.Scopes,
+	}, nil
+}
+
+func (r *MySQLTokenRepo) Issue(g *Grant) (*TokenPair, error) {
+	grant := *g
+	if grant.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		grant.ID = id
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	pair, err := r.insertPair(tx, &grant)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+func (r *MySQLTokenRepo) Verify(accessToken string) (*session.Session, error) {
+	row := r.DB.QueryRow(
+		"SELECT client_id, user_id, username, scopes, expiration_date FROM oauth_tokens WHERE token_hash = ? AND refresh = 0",
+		hashToken(accessToken),
+	)
+
+	sess := &session.Session{}
+	var scopes string
+	var exp int64
+	err := row.Scan(&sess.ClientID, &sess.UserID, &sess.Username, &scopes, &exp)
+	if err == sql.ErrNoRows {
+		return nil, session.ErrBadToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > exp {
+		return nil, session.ErrTokenExpired
+	}
+	sess.Scopes = splitScopes(scopes)
+	sess.ExpirationDate = strconv.FormatInt(exp, 10)
+
+	return sess, nil
+}
+
+func (r *MySQLTokenRepo) Refresh(refreshToken string, clientID string) (*TokenPair, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	row := tx.QueryRow(
+		"SELECT grant_id, client_id, user_id, username, scopes, expiration_date FROM oauth_tokens WHERE token_hash = ? AND refresh = 1 FOR UPDATE",
+		hashToken(refreshToken),
+	)
+
+	g := &Grant{}
+	var scopes string
+	var exp int64
+	err = row.Scan(&g.ID, &g.ClientID, &g.UserID, &g.Username, &scopes, &exp)
+	if err == sql.ErrNoRows {
+		return nil, session.ErrBadToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if g.ClientID != clientID {
+		return nil, session.ErrBadToken
+	}
+	if time.Now().Unix() > exp {
+		return nil, session.ErrTokenExpired
+	}
+	g.Scopes = splitScopes(scopes)
+
+	// the old pair dies with the rotation
+	_, err = tx.Exec("DELETE FROM oauth_tokens WHERE grant_id = ?", g.ID)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := r.insertPair(tx, g)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+func (r *MySQLTokenRepo) Revoke(token string, clientID string) error {
+	row := r.DB.QueryRow(
+		"SELECT grant_id FROM oauth_tokens WHERE token_hash = ? AND client_id = ?",
+		hashToken(token),
+		clientID,
+	)
+
+	var grantID string
+	err := row.Scan(&grantID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Exec("DELETE FROM oauth_tokens WHERE grant_id = ?", grantID)
+
+	return err
+}
+
+func (r *MySQLTokenRepo) RevokeClient(userID uint, clientID string) error {
+	result, err := r.DB.Exec(
+		"DELETE FROM oauth_tokens WHERE user_id = ? AND client_id = ?",
+		userID,
+		clientID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoGrant
+	}
+
+	return nil
+}
+
+func (r *MySQLTokenRepo) DeleteByClient(clientID string) error {
+	_, err := r.DB.Exec("DELETE FROM oauth_tokens WHERE client_id = ?", clientID)
+
+	return err
+}
+
+func (r *MySQLTokenRepo) DeleteByUser(userID uint) error {
+	_, err := r.DB.Exec("DELETE FROM oauth_tokens WHERE user_id = ?", userID)
+
+	return err
+}
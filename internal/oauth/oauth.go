@@ -0,0 +1,118 @@
+package oauth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/vlasdash/redditclone/internal/session"
+	"time"
+)
+
+const (
+	DefaultCodeLifetime         = 5 * time.Minute
+	DefaultAccessTokenLifetime  = time.Hour
+	DefaultRefreshTokenLifetime = 30 * 24 * time.Hour
+
+	MaxClientNameLength = 64
+	MaxRedirectURIs     = 5
+
+	RefreshTokenPrefix = "rcr_"
+)
+
+var (
+	ErrNoClient         = errors.New("oauth client doesn't exist")
+	ErrBadClientName    = errors.New("client name must be 1-64 characters")
+	ErrBadRedirectURIs  = errors.New("client needs 1-5 redirect uris")
+	ErrBadRedirectURI   = errors.New("redirect uri must be an https url or an http url on localhost")
+	ErrUnknownRedirect  = errors.New("redirect uri isn't registered for the client")
+	ErrBadCodeChallenge = errors.New("code_challenge must be a S256 PKCE challenge")
+	ErrBadCode          = errors.New("authorization code is invalid or expired")
+	ErrNoGrant          = errors.New("client isn't authorized by the user")
+	ErrUnableGenerate   = errors.New("can`t create oauth token")
+)
+
+// Client is a third-party app. Public clients (mobile, single page apps) can't
+// keep a secret and rely on PKCE alone.
+type Client struct {
+	ID           string
+	SecretHash   string
+	Name         string
+	RedirectURIs []string
+	OwnerID      uint
+	CreateDate   string
+}
+
+// Code is an authorization code waiting to be exchanged for tokens.
+type Code struct {
+	ClientID       string
+	UserID         uint
+	Username       string
+	RedirectURI    string
+	Scopes         []session.Scope
+	Challenge      string
+	ExpirationDate int64
+}
+
+// Grant is what a user allowed a client to do, all tokens issued from one
+// authorization share it and are revoked together.
+type Grant struct {
+	ID       string
+	ClientID string
+	UserID   uint
+	Username string
+	Scopes   []session.Scope
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+	Scopes       []session.Scope
+}
+
+type ClientRepo interface {
+	Add(ownerID uint, name string, redirectURIs []string, public bool) (client *Client, secret string, err error)
+	Get(id string) (*Client, error)
+	GetByOwner(ownerID uint) ([]*Client, error)
+	Delete(ownerID uint, id string) error
+}
+
+type CodeRepo interface {
+	Add(c *Code) (code string, err error)
+	// Consume returns the code once, a second exchange fails
+	Consume(code string) (*Code, error)
+}
+
+type TokenRepo interface {
+	session.OAuthVerifier
+	Issue(g *Grant) (*TokenPair, error)
+	// Refresh rotates the refresh token of the client
+	Refresh(refreshToken string, clientID string) (*TokenPair, error)
+	// Revoke drops the whole grant of the token, unknown tokens are ignored
+	Revoke(token string, clientID string) error
+	RevokeClient(userID uint, clientID string) error
+	DeleteByClient(clientID string) error
+	// DeleteByUser drops every grant the user has given
+	DeleteByUser(userID uint) error
+}
+
+func (c *Client) Public() bool {
+	return c.SecretHash == ""
+}
+
+func (c *Client) HasRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIs {
+		if u == uri {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Client) CheckSecret(secret string) bool {
+	if c.Public() {
+		return secret == ""
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(c.SecretHash)) == 1
+}
@@ -0,0 +1,133 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/vlasdash/redditclone/internal/session"
+	"net"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// S256 is the only PKCE method accepted, "plain" gives no protection against
+// a stolen code.
+const S256 = "S256"
+
+func ValidateClient(name string, redirectURIs []string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxClientNameLength {
+		return ErrBadClientName
+	}
+	if len(redirectURIs) == 0 || len(redirectURIs) > MaxRedirectURIs {
+		return ErrBadRedirectURIs
+	}
+	for _, uri := range redirectURIs {
+		if err := ValidateRedirectURI(uri); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ValidateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" {
+		return ErrBadRedirectURI
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+
+	return ErrBadRedirectURI
+}
+
+// ParseScope reads the space separated scope parameter.
+func ParseScope(scope string) ([]session.Scope, error) {
+	return session.ParseScopes(strings.Fields(scope))
+}
+
+func FormatScope(scopes []session.Scope) string {
+	values := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		values = append(values, string(s))
+	}
+
+	return strings.Join(values, " ")
+}
+
+// ValidateChallenge checks the challenge looks like base64url of a sha256.
+func ValidateChallenge(challenge string, method string) error {
+	if method != S256 || len(challenge) != 43 {
+		return ErrBadCodeChallenge
+	}
+	if _, err := base64.RawURLEncoding.DecodeString(challenge); err != nil {
+		return ErrBadCodeChallenge
+	}
+
+	return nil
+}
+
+func VerifyPKCE(verifier string, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func newToken(prefix string) (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", ErrUnableGenerate
+	}
+	token = prefix + hex.EncodeToString(buf)
+
+	return token, hashToken(token), nil
+}
+
+func newID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", ErrUnableGenerate
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// only hashes of secrets, codes and tokens are stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func joinScopes(scopes []session.Scope) string {
+	return strings.ReplaceAll(FormatScope(scopes), " ", ",")
+}
+
+func splitScopes(value string) []session.Scope {
+	scopes := make([]session.Scope, 0)
+	for _, v := range strings.Split(value, ",") {
+		if v != "" {
+			scopes = append(scopes, session.Scope(v))
+		}
+	}
+
+	return scopes
+}
@@ -0,0 +1,36 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// IDLess orders both numeric ids of the memory repositories and hex object
+// ids of mongo by the time of creation.
+func IDLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+
+	return a < b
+}
+
+// EncodeCursor turns the cursor of a page into an opaque url-safe string.
+func EncodeCursor(c interface{}) string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads back into c a cursor made by EncodeCursor.
+func DecodeCursor(s string, c interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, c)
+}
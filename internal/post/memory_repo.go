@@ -7,18 +7,20 @@ import (
 )
 
 type MemoryRepo struct {
-	idCount uint
-	posts   []*Post
-	mu      *sync.RWMutex
+	idCount   uint
+	posts     []*Post
+	revisions map[string][]*Revision
+	mu        *sync.RWMutex
 }
 
 var _ PostRepo = (*MemoryRepo)(nil)
 
 func NewMemoryRepo() *MemoryRepo {
 	return &MemoryRepo{
-		idCount: 0,
-		posts:   make([]*Post, 0, 2),
-		mu:      &sync.RWMutex{},
+		idCount:   0,
+		posts:     make([]*Post, 0, 2),
+		revisions: make(map[string][]*Revision),
+		mu:        &sync.RWMutex{},
 	}
 }
 
@@ -104,6 +106,80 @@ func (r *MemoryRepo) GetByAuthor(id uint) ([]*Post, error) {
 	return posts, nil
 }
 
+func (r *MemoryRepo) GetByCommentIDs(ids []string) ([]*Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	posts := make([]*Post, 0, len(ids))
+	for _, post := range r.posts {
+		for _, commentID := range post.CommentIDs {
+			if wanted[commentID] {
+				posts = append(posts, post)
+				break
+			}
+		}
+	}
+
+	return posts, nil
+}
+
+func (r *MemoryRepo) GetPage(opts PageOptions) (*Page, error) {
+	return r.findPage(func(p *Post) bool { return true }, opts)
+}
+
+func (r *MemoryRepo) GetPageByCategory(category string, opts PageOptions) (*Page, error) {
+	page, err := r.findPage(func(p *Post) bool { return p.Category == category }, opts)
+	if err != nil || opts.After != "" {
+		return page, err
+	}
+
+	page.Pinned, err = r.GetPinned(category)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (r *MemoryRepo) GetPageByCategories(categories []string, opts PageOptions) (*Page, error) {
+	return r.findPage(func(p *Post) bool {
+		for _, category := range categories {
+			if p.Category == category {
+				return true
+			}
+		}
+		return false
+	}, opts)
+}
+
+func (r *MemoryRepo) GetPageByAuthor(id uint, opts PageOptions) (*Page, error) {
+	return r.findPage(func(p *Post) bool { return p.AuthorID == id }, opts)
+}
+
+func (r *MemoryRepo) findPage(match func(p *Post) bool, opts PageOptions) (*Page, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := make([]*Post, 0, 2)
+	for _, post := range r.posts {
+		if !match(post) {
+			continue
+		}
+
+		posts = append(posts, post)
+	}
+
+	if opts.Sort == "" {
+		opts.Sort = SortNew
+	}
+	return Rank(posts, opts, time.Now())
+}
+
 func (r *MemoryRepo) AddComment(postID string, commentID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -217,6 +293,58 @@ func (r *MemoryRepo) Unvote(postID string, voter uint) error {
 	return ErrNotExist
 }
 
+func (r *MemoryRepo) Update(postID string, userID uint, title string, text string, url string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, post := range r.posts {
+		if post.ID != postID {
+			continue
+		}
+		if post.AuthorID != userID {
+			return ErrNoEditAccess
+		}
+
+		revision := &Revision{
+			Title:      post.Title,
+			Text:       post.Text,
+			URL:        post.URL,
+			CreateDate: post.CreateDate,
+		}
+		if post.Edited != "" {
+			revision.CreateDate = post.Edited
+		}
+		r.revisions[postID] = append(r.revisions[postID], revision)
+
+		post.Title = title
+		post.Text = text
+		post.URL = url
+		post.Edited = time.Now().Format(time.RFC3339)
+
+		return nil
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) GetRevisions(postID string) ([]*Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, post := range r.posts {
+		if post.ID != postID {
+			continue
+		}
+
+		revisions := make([]*Revision, 0, len(r.revisions[postID]))
+		revisions = append(revisions, r.revisions[postID]...)
+
+		return revisions, nil
+	}
+
+	return nil, ErrNotExist
+}
+
 func (r *MemoryRepo) Delete(postID string, userID uint) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -229,6 +357,7 @@ func (r *MemoryRepo) Delete(postID string, userID uint) error {
 			return ErrNoAccess
 		}
 
+		delete(r.revisions, postID)
 		copy(r.posts[i:], r.posts[i+1:])
 		r.posts[len(r.posts)-1] = nil
 		r.posts = r.posts[:len(r.posts)-1]
@@ -268,3 +397,99 @@ func (r *MemoryRepo) DeleteComment(postID string, commentID string) error {
 
 	return ErrCommentNotExist
 }
+
+func (r *MemoryRepo) Remove(postID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.posts {
+		if r.posts[i].ID != postID {
+			continue
+		}
+
+		delete(r.revisions, postID)
+		copy(r.posts[i:], r.posts[i+1:])
+		r.posts[len(r.posts)-1] = nil
+		r.posts = r.posts[:len(r.posts)-1]
+
+		return nil
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) SetLocked(postID string, locked bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, post := range r.posts {
+		if post.ID == postID {
+			post.Locked = locked
+			return nil
+		}
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) SetPinned(postID string, pinned bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, post := range r.posts {
+		if post.ID == postID {
+			post.Pinned = pinned
+			return nil
+		}
+	}
+
+	return ErrNotExist
+}
+
+func (r *MemoryRepo) GetPinned(category string) ([]*Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	posts := make([]*Post, 0)
+
+	for i := len(r.posts) - 1; i >= 0; i-- {
+		if r.posts[i].Category == category && r.posts[i].Pinned {
+			posts = append(posts, r.posts[i])
+		}
+	}
+
+	return posts, nil
+}
+
+func (r *MemoryRepo) GetAuthorStats(id uint) (*AuthorStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &AuthorStats{}
+	for _, item := range r.posts {
+		if item.AuthorID != id {
+			continue
+		}
+
+		stats.Count++
+		for _, v := range item.Votes {
+			if v.UserID != id {
+				stats.Karma += v.Value
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+func (r *MemoryRepo) Anonymize(authorID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, post := range r.posts {
+		if post.AuthorID == authorID {
+			post.AuthorID = DeletedAuthorID
+		}
+	}
+
+	return nil
+}
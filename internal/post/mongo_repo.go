@@ -23,6 +23,34 @@ type Item struct {
 	AuthorID       uint               `bson:"author_id"`
 	UpvotesCount   int                `bson:"upvotes_count"`
 	DownvotesCount int                `bson:"downvotes_count"`
+	Edited         string             `bson:"edited,omitempty"`
+	Revisions      []*Revision        `bson:"revisions,omitempty"`
+	Locked         bool               `bson:"locked,omitempty"`
+	Pinned         bool               `bson:"pinned,omitempty"`
+	Score          int                `bson:"score"`
+	Hot            float64            `bson:"hot"`
+	Controversy    float64            `bson:"controversy"`
+}
+
+// sortFields name the stored keys of the ranked sort modes, every one of them
+// is indexed together with _id so that a page is read as a range of the index.
+var sortFields = map[Sort]string{
+	SortHot:           "hot",
+	SortTop:           "score",
+	SortControversial: "controversy",
+}
+
+func sortKeys(ups, downs int, createDate string) bson.M {
+	return bson.M{
+		"score":       ups - downs,
+		"hot":         Hot(ups, downs, createTime(&Post{CreateDate: createDate})),
+		"controversy": Controversy(ups, downs),
+	}
+}
+
+type StatsItem struct {
+	Count int `bson:"count"`
+	Karma int `bson:"karma"`
 }
 
 type MongoRepo struct {
@@ -41,6 +69,75 @@ func NewMongoRepo(db *mongo.Database) *MongoRepo {
 	}
 }
 
+// EnsureIndexes creates the indexes of the ranked sort modes and stores the sort
+// keys of the posts created before they were introduced.
+func (r *MongoRepo) EnsureIndexes() error {
+	models := make([]mongo.IndexModel, 0, len(sortFields))
+	for _, field := range []string{"hot", "score", "controversy"} {
+		models = append(models, mongo.IndexModel{
+			Keys: primitive.D{
+				{Key: field, Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		})
+	}
+
+	_, err := r.Posts.Indexes().CreateMany(context.TODO(), models)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := r.Posts.Find(context.TODO(), bson.M{"hot": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+
+	var items []*Item
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = r.setSortKeys(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refreshSortKeys recomputes the stored sort keys after a vote.
+func (r *MongoRepo) refreshSortKeys(itemID primitive.ObjectID) error {
+	item := &Item{}
+	err := r.Posts.FindOne(context.TODO(), bson.M{"_id": itemID}).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrNotExist
+		}
+
+		return err
+	}
+
+	return r.setSortKeys(item)
+}
+
+// setSortKeys only matches the counters the keys were computed from, if another
+// vote slips in between, its own refresh stores the right keys.
+func (r *MongoRepo) setSortKeys(item *Item) error {
+	filter := bson.M{
+		"_id":             item.ID,
+		"upvotes_count":   item.UpvotesCount,
+		"downvotes_count": item.DownvotesCount,
+	}
+	update := bson.M{"$set": sortKeys(item.UpvotesCount, item.DownvotesCount, item.CreateDate)}
+
+	_, err := r.Posts.UpdateOne(context.TODO(), filter, update)
+
+	return err
+}
+
 func (r *MongoRepo) GetAll() ([]*Post, error) {
 	var items []*Item
 
@@ -69,6 +166,9 @@ func (r *MongoRepo) GetAll() ([]*Post, error) {
 			AuthorID:       item.AuthorID,
 			UpvotesCount:   item.UpvotesCount,
 			DownvotesCount: item.DownvotesCount,
+			Edited:         item.Edited,
+			Locked:         item.Locked,
+			Pinned:         item.Pinned,
 		})
 	}
 
@@ -97,6 +197,9 @@ func (r *MongoRepo) Create(post *Post) (id string, err error) {
 		},
 	}
 	item.CommentIDs = make([]string, 0)
+	item.Score = item.UpvotesCount - item.DownvotesCount
+	item.Hot = Hot(item.UpvotesCount, item.DownvotesCount, createTime(&Post{CreateDate: item.CreateDate}))
+	item.Controversy = Controversy(item.UpvotesCount, item.DownvotesCount)
 
 	_, err = r.Posts.InsertOne(context.TODO(), item)
 	if err != nil {
@@ -142,6 +245,9 @@ func (r *MongoRepo) GetByID(id string, viewsUpdate int) (*Post, error) {
 		AuthorID:       item.AuthorID,
 		UpvotesCount:   item.UpvotesCount,
 		DownvotesCount: item.DownvotesCount,
+		Edited:         item.Edited,
+		Locked:         item.Locked,
+		Pinned:         item.Pinned,
 	}
 
 	return post, nil
@@ -176,6 +282,9 @@ func (r *MongoRepo) GetByCategory(category string) ([]*Post, error) {
 			AuthorID:       item.AuthorID,
 			UpvotesCount:   item.UpvotesCount,
 			DownvotesCount: item.DownvotesCount,
+			Edited:         item.Edited,
+			Locked:         item.Locked,
+			Pinned:         item.Pinned,
 		}
 		posts = append(posts, post)
 	}
@@ -212,6 +321,9 @@ func (r *MongoRepo) GetByAuthor(id uint) ([]*Post, error) {
 			AuthorID:       item.AuthorID,
 			UpvotesCount:   item.UpvotesCount,
 			DownvotesCount: item.DownvotesCount,
+			Edited:         item.Edited,
+			Locked:         item.Locked,
+			Pinned:         item.Pinned,
 		}
 		posts = append(posts, post)
 	}
@@ -219,6 +331,167 @@ func (r *MongoRepo) GetByAuthor(id uint) ([]*Post, error) {
 	return posts, nil
 }
 
+func (r *MongoRepo) GetByCommentIDs(ids []string) ([]*Post, error) {
+	filter := bson.M{"comment_ids": bson.M{"$in": ids}}
+	cursor, err := r.Posts.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*Post, 0, len(items))
+	for _, item := range items {
+		post := &Post{
+			ID:             item.ID.Hex(),
+			Category:       item.Category,
+			CreateDate:     item.CreateDate,
+			Text:           item.Text,
+			URL:            item.URL,
+			Title:          item.Title,
+			Type:           item.Type,
+			Views:          item.Views,
+			Votes:          item.Votes,
+			CommentIDs:     item.CommentIDs,
+			AuthorID:       item.AuthorID,
+			UpvotesCount:   item.UpvotesCount,
+			DownvotesCount: item.DownvotesCount,
+			Edited:         item.Edited,
+			Locked:         item.Locked,
+			Pinned:         item.Pinned,
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+func (r *MongoRepo) GetPage(opts PageOptions) (*Page, error) {
+	return r.findPage(bson.M{}, opts)
+}
+
+func (r *MongoRepo) GetPageByCategory(category string, opts PageOptions) (*Page, error) {
+	page, err := r.findPage(bson.M{"category": category}, opts)
+	if err != nil || opts.After != "" {
+		return page, err
+	}
+
+	page.Pinned, err = r.GetPinned(category)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (r *MongoRepo) GetPageByCategories(categories []string, opts PageOptions) (*Page, error) {
+	return r.findPage(bson.M{"category": bson.M{"$in": categories}}, opts)
+}
+
+func (r *MongoRepo) GetPageByAuthor(id uint, opts PageOptions) (*Page, error) {
+	return r.findPage(bson.M{"author_id": id}, opts)
+}
+
+func (r *MongoRepo) findPage(filter bson.M, opts PageOptions) (*Page, error) {
+	now := time.Now()
+	if opts.Sort == "" {
+		opts.Sort = SortNew
+	}
+
+	var after *cursor
+	var afterID primitive.ObjectID
+	if opts.After != "" {
+		var err error
+		after, err = decodeCursor(opts.After)
+		if err != nil {
+			return nil, err
+		}
+		afterID, err = primitive.ObjectIDFromHex(after.ID)
+		if err != nil || after.Sort != opts.Sort {
+			return nil, ErrInvalidCursor
+		}
+	}
+	now = rankTime(after, now)
+
+	option := options.Find()
+	switch opts.Sort {
+	case SortNew:
+		if after != nil {
+			filter["_id"] = bson.M{"$lt": afterID}
+		}
+
+		option.SetSort(bson.M{"_id": -1})
+	case SortRising:
+		// rising depends on the current time and can not be stored, but only the
+		// posts of the last risingWindow rise, so they are ranked in the
+		// application against the time of the first page
+		filter["_id"] = bson.M{"$gte": primitive.NewObjectIDFromTimestamp(now.Add(-risingWindow))}
+	default:
+		field := sortFields[opts.Sort]
+		if since := opts.Period.Since(now); opts.Sort == SortTop && !since.IsZero() {
+			filter["_id"] = bson.M{"$gte": primitive.NewObjectIDFromTimestamp(since)}
+		}
+		if after != nil {
+			filter["$or"] = []bson.M{
+				{field: bson.M{"$lt": after.Key}},
+				{field: after.Key, "_id": bson.M{"$lt": afterID}},
+			}
+		}
+
+		option.SetSort(primitive.D{
+			{Key: field, Value: -1},
+			{Key: "_id", Value: -1},
+		})
+	}
+	if opts.Sort != SortRising && opts.Limit > 0 {
+		option.SetLimit(int64(opts.Limit + 1))
+	}
+
+	cursor, err := r.Posts.Find(context.TODO(), filter, option)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*Post, 0, len(items))
+	for _, item := range items {
+		post := &Post{
+			ID:             item.ID.Hex(),
+			Category:       item.Category,
+			CreateDate:     item.CreateDate,
+			Text:           item.Text,
+			URL:            item.URL,
+			Title:          item.Title,
+			Type:           item.Type,
+			Views:          item.Views,
+			Votes:          item.Votes,
+			CommentIDs:     item.CommentIDs,
+			AuthorID:       item.AuthorID,
+			UpvotesCount:   item.UpvotesCount,
+			DownvotesCount: item.DownvotesCount,
+			Edited:         item.Edited,
+			Locked:         item.Locked,
+			Pinned:         item.Pinned,
+		}
+		posts = append(posts, post)
+	}
+
+	if opts.Sort == SortRising {
+		return Rank(posts, opts, now)
+	}
+
+	return newPage(posts, opts, now), nil
+}
+
 func (r *MongoRepo) AddComment(postID string, commentID string) error {
 	itemID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
@@ -253,6 +526,9 @@ func (r *MongoRepo) Upvote(postID string, voter uint) error {
 	filter := bson.M{"_id": itemID, "votes.user_id": voter}
 
 	res := r.Posts.FindOneAndUpdate(context.TODO(), filter, update)
+	if res.Err() == nil {
+		return r.refreshSortKeys(itemID)
+	}
 	if res.Err() != mongo.ErrNoDocuments {
 		return res.Err()
 	}
@@ -271,7 +547,11 @@ func (r *MongoRepo) Upvote(postID string, voter uint) error {
 	option := options.Update().SetUpsert(true)
 
 	_, err = r.Posts.UpdateByID(context.TODO(), itemID, update, option)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return r.refreshSortKeys(itemID)
 }
 
 func (r *MongoRepo) Downvote(postID string, voter uint) error {
@@ -292,6 +572,9 @@ func (r *MongoRepo) Downvote(postID string, voter uint) error {
 	}
 
 	res := r.Posts.FindOneAndUpdate(context.TODO(), filter, update)
+	if res.Err() == nil {
+		return r.refreshSortKeys(itemID)
+	}
 	if res.Err() != mongo.ErrNoDocuments {
 		return res.Err()
 	}
@@ -310,7 +593,11 @@ func (r *MongoRepo) Downvote(postID string, voter uint) error {
 	option := options.Update().SetUpsert(true)
 
 	_, err = r.Posts.UpdateByID(context.TODO(), itemID, update, option)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return r.refreshSortKeys(itemID)
 }
 
 func (r *MongoRepo) Unvote(postID string, voter uint) error {
@@ -352,10 +639,82 @@ func (r *MongoRepo) Unvote(postID string, voter uint) error {
 	}
 	option := options.Update().SetUpsert(true)
 	_, err = r.Posts.UpdateByID(context.TODO(), itemID, update, option)
+	if err != nil {
+		return err
+	}
+
+	return r.refreshSortKeys(itemID)
+}
+
+func (r *MongoRepo) Update(postID string, userID uint, title string, text string, url string) error {
+	itemID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return ErrInvalidID
+	}
+	filter := bson.M{"_id": itemID}
+	item := &Item{}
+
+	err = r.Posts.FindOne(context.TODO(), filter).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrNotExist
+		}
 
+		return err
+	}
+
+	if item.AuthorID != userID {
+		return ErrNoEditAccess
+	}
+
+	revision := &Revision{
+		Title:      item.Title,
+		Text:       item.Text,
+		URL:        item.URL,
+		CreateDate: item.CreateDate,
+	}
+	if item.Edited != "" {
+		revision.CreateDate = item.Edited
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"title":  title,
+			"text":   text,
+			"url":    url,
+			"edited": time.Now().Format(time.RFC3339),
+		},
+		"$push": bson.M{
+			"revisions": revision,
+		},
+	}
+
+	_, err = r.Posts.UpdateOne(context.TODO(), filter, update)
 	return err
 }
 
+func (r *MongoRepo) GetRevisions(postID string) ([]*Revision, error) {
+	itemID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	item := &Item{}
+
+	option := options.FindOne().SetProjection(bson.M{"revisions": 1})
+	err = r.Posts.FindOne(context.TODO(), bson.M{"_id": itemID}, option).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotExist
+		}
+
+		return nil, err
+	}
+
+	if item.Revisions == nil {
+		return make([]*Revision, 0), nil
+	}
+	return item.Revisions, nil
+}
+
 func (r *MongoRepo) Delete(postID string, userID uint) error {
 	itemID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
@@ -397,3 +756,126 @@ func (r *MongoRepo) DeleteComment(postID string, commentID string) error {
 
 	return res.Err()
 }
+
+func (r *MongoRepo) Remove(postID string) error {
+	itemID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	res, err := r.Posts.DeleteOne(context.TODO(), bson.M{"_id": itemID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotExist
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) setFlag(postID string, flag string, value bool) error {
+	itemID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	update := bson.M{"$set": bson.M{flag: value}}
+	res, err := r.Posts.UpdateOne(context.TODO(), bson.M{"_id": itemID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotExist
+	}
+
+	return nil
+}
+
+func (r *MongoRepo) SetLocked(postID string, locked bool) error {
+	return r.setFlag(postID, "locked", locked)
+}
+
+func (r *MongoRepo) SetPinned(postID string, pinned bool) error {
+	return r.setFlag(postID, "pinned", pinned)
+}
+
+func (r *MongoRepo) GetPinned(category string) ([]*Post, error) {
+	var items []*Item
+
+	option := options.Find().SetSort(bson.M{"_id": -1})
+	cursor, err := r.Posts.Find(context.TODO(), bson.M{"category": category, "pinned": true}, option)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*Post, 0, len(items))
+	for _, item := range items {
+		posts = append(posts, &Post{
+			ID:             item.ID.Hex(),
+			Category:       item.Category,
+			CreateDate:     item.CreateDate,
+			Text:           item.Text,
+			URL:            item.URL,
+			Title:          item.Title,
+			Type:           item.Type,
+			Views:          item.Views,
+			Votes:          item.Votes,
+			CommentIDs:     item.CommentIDs,
+			AuthorID:       item.AuthorID,
+			UpvotesCount:   item.UpvotesCount,
+			DownvotesCount: item.DownvotesCount,
+			Edited:         item.Edited,
+			Locked:         item.Locked,
+			Pinned:         item.Pinned,
+		})
+	}
+
+	return posts, nil
+}
+
+func (r *MongoRepo) GetAuthorStats(id uint) (*AuthorStats, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"author_id": id}},
+		{"$group": bson.M{
+			"_id":   nil,
+			"count": bson.M{"$sum": 1},
+			"karma": bson.M{"$sum": bson.M{"$sum": bson.M{"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{
+					"input": "$votes",
+					"cond":  bson.M{"$ne": []interface{}{"$$this.user_id", id}},
+				}},
+				"in": "$$this.value",
+			}}}},
+		}},
+	}
+
+	cursor, err := r.Posts.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var items []*StatsItem
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &AuthorStats{}
+	if len(items) != 0 {
+		stats.Count = items[0].Count
+		stats.Karma = items[0].Karma
+	}
+
+	return stats, nil
+}
+
+// Anonymize hands the posts of a removed account over to DeletedAuthorID.
+func (r *MongoRepo) Anonymize(authorID uint) error {
+	_, err := r.Posts.UpdateMany(context.TODO(), bson.M{"author_id": authorID}, bson.M{"$set": bson.M{"author_id": DeletedAuthorID}})
+
+	return err
+}
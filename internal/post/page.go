@@ -0,0 +1,76 @@
+package post
+
+import (
+	"github.com/vlasdash/redditclone/internal/pagination"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 25
+	MaxPageLimit     = 100
+)
+
+// cursor of the time dependent sorts also carries Now, the moment the first
+// page was ranked at, see rankTime.
+type cursor struct {
+	ID   string  `json:"id"`
+	Sort Sort    `json:"sort"`
+	Key  float64 `json:"key"`
+	Now  int64   `json:"now,omitempty"`
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	c := &cursor{}
+	err := pagination.DecodeCursor(s, c)
+	if err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// rankTime is the time a page is ranked at. Rising keys and the top period
+// move with the clock, so the following pages are ranked against the time of
+// the first one kept in the cursor, otherwise posts would be skipped or
+// repeated between pages. It is whole seconds, like the cursor keeps it.
+func rankTime(after *cursor, now time.Time) time.Time {
+	if after == nil || after.Now == 0 || after.Now > now.Unix() {
+		return now.Truncate(time.Second)
+	}
+
+	return time.Unix(after.Now, 0)
+}
+
+// newPage expects posts to hold up to opts.Limit+1 elements: the extra one only
+// signals that another page exists and is never returned to the caller.
+func newPage(posts []*Post, opts PageOptions, now time.Time) *Page {
+	page := &Page{
+		Posts: posts,
+	}
+	if opts.Limit <= 0 || len(posts) <= opts.Limit {
+		return page
+	}
+
+	page.Posts = posts[:opts.Limit]
+	page.Next = nextCursor(page.Posts[opts.Limit-1], opts.Sort, now)
+
+	return page
+}
+
+func nextCursor(p *Post, sortMode Sort, now time.Time) string {
+	c := &cursor{
+		ID:   p.ID,
+		Sort: sortMode,
+		Key:  sortKey(p, sortMode, now),
+	}
+	if sortMode == SortRising || sortMode == SortTop {
+		c.Now = now.Unix()
+	}
+
+	return pagination.EncodeCursor(c)
+}
+
+// NextCursor returns the cursor of the page that starts right after p.
+func NextCursor(p *Post, sortMode Sort) string {
+	return nextCursor(p, sortMode, time.Now())
+}
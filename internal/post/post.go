@@ -7,13 +7,21 @@ import (
 const (
 	Like   = 1
 	Unlike = -1
+
+	// DeletedAuthorID marks posts whose author has deleted the account.
+	DeletedAuthorID uint = 0
 )
 
 var (
 	ErrNotExist        = errors.New("post with specified id not exist")
 	ErrCommentNotExist = errors.New("comment with specified id not exist")
 	ErrNoAccess        = errors.New("hasn`t access to delete post")
+	ErrNoEditAccess    = errors.New("hasn`t access to edit post")
 	ErrInvalidID       = errors.New("post id is invalid")
+	ErrInvalidCursor   = errors.New("pagination cursor is invalid")
+	ErrInvalidSort     = errors.New("unknown sort mode")
+	ErrInvalidPeriod   = errors.New("unknown time period")
+	ErrLocked          = errors.New("post is locked")
 )
 
 type Vote struct {
@@ -35,6 +43,36 @@ type Post struct {
 	AuthorID       uint
 	UpvotesCount   int
 	DownvotesCount int
+	Edited         string
+	Locked         bool
+	Pinned         bool
+}
+
+type Revision struct {
+	Title      string `json:"title" bson:"title"`
+	Text       string `json:"text,omitempty" bson:"text"`
+	URL        string `json:"url,omitempty" bson:"url"`
+	CreateDate string `json:"created" bson:"create_date"`
+}
+
+type PageOptions struct {
+	Limit  int
+	After  string
+	Sort   Sort
+	Period Period
+}
+
+type Page struct {
+	Posts  []*Post
+	Pinned []*Post
+	Next   string
+}
+
+// AuthorStats sums up the posts of one author. Karma counts only the votes of
+// other users, so the automatic vote for one's own post is left out.
+type AuthorStats struct {
+	Count int
+	Karma int
 }
 
 type PostRepo interface {
@@ -43,10 +81,23 @@ type PostRepo interface {
 	GetByID(id string, viewsUpdate int) (*Post, error)
 	GetByCategory(category string) ([]*Post, error)
 	GetByAuthor(id uint) ([]*Post, error)
+	GetAuthorStats(id uint) (*AuthorStats, error)
+	GetByCommentIDs(ids []string) ([]*Post, error)
+	Anonymize(authorID uint) error
+	GetPage(opts PageOptions) (*Page, error)
+	GetPageByCategory(category string, opts PageOptions) (*Page, error)
+	GetPageByCategories(categories []string, opts PageOptions) (*Page, error)
+	GetPageByAuthor(id uint, opts PageOptions) (*Page, error)
 	AddComment(postID string, commentID string) error
 	Upvote(postID string, voter uint) error
 	Downvote(postID string, voter uint) error
 	Unvote(postID string, voter uint) error
+	Update(postID string, userID uint, title string, text string, url string) error
+	GetRevisions(postID string) ([]*Revision, error)
 	Delete(postID string, userID uint) error
 	DeleteComment(postID string, commentID string) error
+	Remove(postID string) error
+	SetLocked(postID string, locked bool) error
+	SetPinned(postID string, pinned bool) error
+	GetPinned(category string) ([]*Post, error)
 }
@@ -0,0 +1,236 @@
+package post
+
+import (
+	"container/heap"
+	"github.com/vlasdash/redditclone/internal/pagination"
+	"math"
+	"sort"
+	"time"
+)
+
+type Sort string
+
+const (
+	SortHot           Sort = "hot"
+	SortNew           Sort = "new"
+	SortTop           Sort = "top"
+	SortRising        Sort = "rising"
+	SortControversial Sort = "controversial"
+)
+
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+	PeriodAll   Period = "all"
+)
+
+// hotEpoch is the reference point of the reddit hot formula, every 12.5
+// hours after it weigh as much as a tenfold increase of the score.
+const (
+	hotEpoch       = 1134028003
+	hotTimeDivisor = 45000
+	risingWindow   = 24 * time.Hour
+	risingGravity  = 1.5
+	risingViewCost = 10
+)
+
+func ParseSort(s string) (Sort, error) {
+	switch Sort(s) {
+	case "":
+		return SortNew, nil
+	case SortHot, SortNew, SortTop, SortRising, SortControversial:
+		return Sort(s), nil
+	}
+
+	return "", ErrInvalidSort
+}
+
+func ParsePeriod(s string) (Period, error) {
+	switch Period(s) {
+	case "":
+		return PeriodAll, nil
+	case PeriodDay, PeriodWeek, PeriodMonth, PeriodYear, PeriodAll:
+		return Period(s), nil
+	}
+
+	return "", ErrInvalidPeriod
+}
+
+// Since returns the earliest creation time included into the period, zero
+// time means that the period is not limited.
+func (p Period) Since(now time.Time) time.Time {
+	switch p {
+	case PeriodDay:
+		return now.AddDate(0, 0, -1)
+	case PeriodWeek:
+		return now.AddDate(0, 0, -7)
+	case PeriodMonth:
+		return now.AddDate(0, -1, 0)
+	case PeriodYear:
+		return now.AddDate(-1, 0, 0)
+	}
+
+	return time.Time{}
+}
+
+func createTime(p *Post) time.Time {
+	t, err := time.Parse(time.RFC3339, p.CreateDate)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+func Hot(ups, downs int, created time.Time) float64 {
+	score := float64(ups - downs)
+	order := math.Log10(math.Max(math.Abs(score), 1))
+	sign := 0.0
+	if score > 0 {
+		sign = 1
+	} else if score < 0 {
+		sign = -1
+	}
+	seconds := float64(created.Unix() - hotEpoch)
+
+	return math.Round((sign*order+seconds/hotTimeDivisor)*1e7) / 1e7
+}
+
+func Controversy(ups, downs int) float64 {
+	if ups <= 0 || downs <= 0 {
+		return 0
+	}
+
+	magnitude := float64(ups + downs)
+	balance := float64(downs) / float64(ups)
+	if ups < downs {
+		balance = float64(ups) / float64(downs)
+	}
+
+	return math.Pow(magnitude, balance)
+}
+
+// Rising favours young posts which quickly collect votes and views, posts
+// older than risingWindow never rise and are left out of the rising pages.
+func Rising(ups, downs, views int, created, now time.Time) float64 {
+	age := now.Sub(created)
+	if age > risingWindow {
+		return 0
+	}
+	if age < 0 {
+		age = 0
+	}
+
+	activity := float64(ups-downs) + float64(views)/risingViewCost
+	return activity / math.Pow(age.Hours()+2, risingGravity)
+}
+
+func sortKey(p *Post, s Sort, now time.Time) float64 {
+	switch s {
+	case SortHot:
+		return Hot(p.UpvotesCount, p.DownvotesCount, createTime(p))
+	case SortTop:
+		return float64(p.UpvotesCount - p.DownvotesCount)
+	case SortRising:
+		return Rising(p.UpvotesCount, p.DownvotesCount, p.Views, createTime(p), now)
+	case SortControversial:
+		return Controversy(p.UpvotesCount, p.DownvotesCount)
+	}
+
+	return float64(createTime(p).Unix())
+}
+
+type rankedPost struct {
+	post *Post
+	key  float64
+}
+
+func ranksBefore(a, b rankedPost) bool {
+	if a.key != b.key {
+		return a.key > b.key
+	}
+
+	return pagination.IDLess(b.post.ID, a.post.ID)
+}
+
+// rankedHeap keeps the lowest ranked post on top, so a page only holds the
+// best opts.Limit+1 candidates instead of sorting all of them.
+type rankedHeap []rankedPost
+
+func (h rankedHeap) Len() int           { return len(h) }
+func (h rankedHeap) Less(i, j int) bool { return ranksBefore(h[j], h[i]) }
+func (h rankedHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *rankedHeap) Push(x interface{}) {
+	*h = append(*h, x.(rankedPost))
+}
+
+func (h *rankedHeap) Pop() interface{} {
+	old := *h
+	rp := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return rp
+}
+
+// Rank orders posts for the requested sort mode and cuts the page following
+// opts.After. The memory repository ranks every sort mode through it and mongo
+// the rising one, the keys stored by mongo come from the same functions, so
+// both agree on the order of the same set of posts.
+func Rank(posts []*Post, opts PageOptions, now time.Time) (*Page, error) {
+	var after *cursor
+	if opts.After != "" {
+		var err error
+		after, err = decodeCursor(opts.After)
+		if err != nil {
+			return nil, err
+		}
+		if after.Sort != opts.Sort {
+			return nil, ErrInvalidCursor
+		}
+	}
+	now = rankTime(after, now)
+
+	since := time.Time{}
+	switch opts.Sort {
+	case SortTop:
+		since = opts.Period.Since(now)
+	case SortRising:
+		since = now.Add(-risingWindow)
+	}
+
+	ranked := make(rankedHeap, 0, 2)
+	for _, p := range posts {
+		if !since.IsZero() && createTime(p).Before(since) {
+			continue
+		}
+
+		rp := rankedPost{
+			post: p,
+			key:  sortKey(p, opts.Sort, now),
+		}
+		if after != nil && !ranksBefore(rankedPost{post: &Post{ID: after.ID}, key: after.Key}, rp) {
+			continue
+		}
+
+		heap.Push(&ranked, rp)
+		if opts.Limit > 0 && ranked.Len() > opts.Limit+1 {
+			heap.Pop(&ranked)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranksBefore(ranked[i], ranked[j])
+	})
+
+	result := make([]*Post, 0, len(ranked))
+	for _, rp := range ranked {
+		result = append(result, rp.post)
+	}
+
+	return newPage(result, opts, now), nil
+}
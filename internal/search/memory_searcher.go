@@ -0,0 +1,198 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// field weights, a match in the title says more than one in the body
+const (
+	titleWeight = 3
+	urlWeight   = 2
+	textWeight  = 1
+)
+
+type MemorySearcher struct {
+	docs     map[string]*Document
+	postDocs map[string]map[string]struct{}
+	// term -> document key -> weighted term frequency
+	postings map[string]map[string]float64
+	mu       *sync.RWMutex
+}
+
+var _ Searcher = (*MemorySearcher)(nil)
+
+func NewMemorySearcher() *MemorySearcher {
+	return &MemorySearcher{
+		docs:     make(map[string]*Document),
+		postDocs: make(map[string]map[string]struct{}),
+		postings: make(map[string]map[string]float64),
+		mu:       &sync.RWMutex{},
+	}
+}
+
+func (s *MemorySearcher) Index(doc *Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := documentKey(doc.Type, doc.ID)
+	copyDoc := *doc
+	if old, ok := s.docs[key]; ok {
+		copyDoc.CreateDate = old.CreateDate
+		s.remove(key)
+	}
+	if copyDoc.CreateDate.IsZero() {
+		copyDoc.CreateDate = time.Now()
+	}
+
+	s.docs[key] = &copyDoc
+	if s.postDocs[copyDoc.PostID] == nil {
+		s.postDocs[copyDoc.PostID] = make(map[string]struct{})
+	}
+	s.postDocs[copyDoc.PostID][key] = struct{}{}
+
+	s.addTerms(key, copyDoc.Title, titleWeight)
+	s.addTerms(key, copyDoc.URL, urlWeight)
+	s.addTerms(key, copyDoc.Text, textWeight)
+
+	return nil
+}
+
+func (s *MemorySearcher) addTerms(key string, text string, weight float64) {
+	for _, term := range Tokenize(text) {
+		if s.postings[term] == nil {
+			s.postings[term] = make(map[string]float64)
+		}
+		s.postings[term][key] += weight
+	}
+}
+
+func (s *MemorySearcher) remove(key string) {
+	doc, ok := s.docs[key]
+	if !ok {
+		return
+	}
+
+	for _, text := range []string{doc.Title, doc.URL, doc.Text} {
+		for _, term := range Tokenize(text) {
+			delete(s.postings[term], key)
+			if len(s.postings[term]) == 0 {
+				delete(s.postings, term)
+			}
+		}
+	}
+	delete(s.postDocs[doc.PostID], key)
+	if len(s.postDocs[doc.PostID]) == 0 {
+		delete(s.postDocs, doc.PostID)
+	}
+	delete(s.docs, key)
+}
+
+func (s *MemorySearcher) Remove(docType string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(documentKey(docType, id))
+
+	return nil
+}
+
+func (s *MemorySearcher) RemoveByPost(postID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.postDocs[postID] {
+		s.remove(key)
+	}
+
+	return nil
+}
+
+func (s *MemorySearcher) Anonymize(authorID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, doc := range s.docs {
+		if doc.AuthorID == authorID {
+			doc.AuthorID = 0
+		}
+	}
+
+	return nil
+}
+
+func (s *MemorySearcher) Search(q Query) ([]*Result, error) {
+	terms := Tokenize(q.Text)
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// tf-idf summed over the query terms
+	scores := make(map[string]float64)
+	for _, term := range terms {
+		docs := s.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+
+		idf := math.Log(1 + float64(len(s.docs))/float64(len(docs)))
+		for key, tf := range docs {
+			scores[key] += tf * idf
+		}
+	}
+
+	results := make([]*Result, 0)
+	for key, score := range scores {
+		doc := s.docs[key]
+		if !q.matches(doc) {
+			continue
+		}
+
+		results = append(results, &Result{
+			Type:       doc.Type,
+			ID:         doc.ID,
+			PostID:     doc.PostID,
+			Category:   doc.Category,
+			AuthorID:   doc.AuthorID,
+			Title:      doc.Title,
+			Text:       doc.Text,
+			URL:        doc.URL,
+			CreateDate: doc.CreateDate.Format(time.RFC3339),
+			Score:      score,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreateDate > results[j].CreateDate
+	})
+	if limit := q.limit(); len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+func (q Query) matches(doc *Document) bool {
+	if q.Type != "" && doc.Type != q.Type {
+		return false
+	}
+	if q.Category != "" && doc.Category != q.Category {
+		return false
+	}
+	if q.AuthorID != 0 && doc.AuthorID != q.AuthorID {
+		return false
+	}
+	if !q.Since.IsZero() && doc.CreateDate.Before(q.Since) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type Item struct {
+	Key        string    `bson:"_id"`
+	Type       string    `bson:"type"`
+	ID         string    `bson:"target_id"`
+	PostID     string    `bson:"post_id"`
+	Category   string    `bson:"category"`
+	AuthorID   uint      `bson:"author_id"`
+	Title      string    `bson:"title,omitempty"`
+	Text       string    `bson:"text,omitempty"`
+	URL        string    `bson:"url,omitempty"`
+	CreateDate time.Time `bson:"create_date"`
+	Score      float64   `bson:"score,omitempty"`
+}
+
+type MongoSearcher struct {
+	Documents *mongo.Collection
+	DB        *mongo.Database
+}
+
+var _ Searcher = (*MongoSearcher)(nil)
+
+func NewMongoSearcher(db *mongo.Database) *MongoSearcher {
+	collection := db.Collection("search")
+
+	return &MongoSearcher{
+		Documents: collection,
+		DB:        db,
+	}
+}
+
+func (s *MongoSearcher) EnsureIndex() error {
+	model := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "url", Value: "text"},
+			{Key: "text", Value: "text"},
+		},
+		Options: options.Index().SetWeights(bson.M{
+			"title": titleWeight,
+			"url":   urlWeight,
+			"text":  textWeight,
+		}),
+	}
+
+	_, err := s.Documents.Indexes().CreateOne(context.TODO(), model)
+
+	return err
+}
+
+func (s *MongoSearcher) Index(doc *Document) error {
+	createDate := doc.CreateDate
+	if createDate.IsZero() {
+		createDate = time.Now()
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"type":      doc.Type,
+			"target_id": doc.ID,
+			"post_id":   doc.PostID,
+			"category":  doc.Category,
+			"author_id": doc.AuthorID,
+			"title":     doc.Title,
+			"text":      doc.Text,
+			"url":       doc.URL,
+		},
+		"$setOnInsert": bson.M{"create_date": createDate},
+	}
+	filter := bson.M{"_id": documentKey(doc.Type, doc.ID)}
+	_, err := s.Documents.UpdateOne(context.TODO(), filter, update, options.Update().SetUpsert(true))
+
+	return err
+}
+
+func (s *MongoSearcher) Remove(docType string, id string) error {
+	_, err := s.Documents.DeleteOne(context.TODO(), bson.M{"_id": documentKey(docType, id)})
+
+	return err
+}
+
+func (s *MongoSearcher) RemoveByPost(postID string) error {
+	_, err := s.Documents.DeleteMany(context.TODO(), bson.M{"post_id": postID})
+
+	return err
+}
+
+func (s *MongoSearcher) Anonymize(authorID uint) error {
+	_, err := s.Documents.UpdateMany(context.TODO(), bson.M{"author_id": authorID}, bson.M{"$set": bson.M{"author_id": 0}})
+
+	return err
+}
+
+func (s *MongoSearcher) Search(q Query) ([]*Result, error) {
+	if len(Tokenize(q.Text)) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	filter := bson.M{"$text": bson.M{"$search": q.Text}}
+	if q.Type != "" {
+		filter["type"] = q.Type
+	}
+	if q.Category != "" {
+		filter["category"] = q.Category
+	}
+	if q.AuthorID != 0 {
+		filter["author_id"] = q.AuthorID
+	}
+	if !q.Since.IsZero() {
+		filter["create_date"] = bson.M{"$gte": q.Since}
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	option := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "create_date", Value: -1}}).
+		SetLimit(int64(q.limit()))
+	cursor, err := s.Documents.Find(context.TODO(), filter, option)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Item
+	err = cursor.All(context.TODO(), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Result, 0, len(items))
+	for _, item := range items {
+		results = append(results, &Result{
+			Type:       item.Type,
+			ID:         item.ID,
+			PostID:     item.PostID,
+			Category:   item.Category,
+			AuthorID:   item.AuthorID,
+			Title:      item.Title,
+			Text:       item.Text,
+			URL:        item.URL,
+			CreateDate: item.CreateDate.Format(time.RFC3339),
+			Score:      item.Score,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+
+	DefaultLimit = 25
+	MaxLimit     = 100
+)
+
+var (
+	ErrEmptyQuery  = errors.New("search query is empty")
+	ErrInvalidType = errors.New("search type must be post or comment")
+)
+
+type Document struct {
+	Type       string
+	ID         string
+	PostID     string
+	Category   string
+	AuthorID   uint
+	Title      string
+	Text       string
+	URL        string
+	CreateDate time.Time
+}
+
+type Query struct {
+	Text     string
+	Type     string
+	Category string
+	AuthorID uint
+	Since    time.Time
+	Limit    int
+}
+
+type Result struct {
+	Type       string  `json:"type"`
+	ID         string  `json:"id"`
+	PostID     string  `json:"post"`
+	Category   string  `json:"category"`
+	AuthorID   uint    `json:"author,string"`
+	Title      string  `json:"title,omitempty"`
+	Text       string  `json:"text,omitempty"`
+	URL        string  `json:"url,omitempty"`
+	CreateDate string  `json:"created"`
+	Score      float64 `json:"score"`
+}
+
+// Index adds a document or updates the searchable fields of an existing one,
+// the creation date is kept from the first time the document was indexed.
+type Searcher interface {
+	Index(doc *Document) error
+	Remove(docType string, id string) error
+	RemoveByPost(postID string) error
+	Anonymize(authorID uint) error
+	Search(q Query) ([]*Result, error)
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		return MaxLimit
+	}
+
+	return q.Limit
+}
+
+func ParseType(s string) (string, error) {
+	switch s {
+	case "", TypePost, TypeComment:
+		return s, nil
+	}
+
+	return "", ErrInvalidType
+}
+
+func Tokenize(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) < 2 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+
+	return tokens
+}
+
+func documentKey(docType string, id string) string {
+	return docType + ":" + id
+}
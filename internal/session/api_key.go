@@ -0,0 +1,154 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeRead    Scope = "read"
+	ScopePost    Scope = "post"
+	ScopeComment Scope = "comment"
+	ScopeVote    Scope = "vote"
+)
+
+const (
+	// APIKeyPrefix and OAuthTokenPrefix tell the tokens apart from JWTs in the
+	// Authorization header
+	APIKeyPrefix     = "rck_"
+	OAuthTokenPrefix = "rco_"
+
+	MaxAPIKeyNameLength = 64
+	MaxAPIKeys          = 25
+
+	// LastUsedInterval is how stale the recorded last use of a key may get, it
+	// saves a write on every request made with the key.
+	LastUsedInterval = time.Minute
+)
+
+var (
+	ErrNoAPIKey        = errors.New("api key doesn't exist")
+	ErrBadAPIKeyName   = errors.New("api key name must be 1-64 characters")
+	ErrBadScope        = errors.New("unknown api key scope")
+	ErrNoScopes        = errors.New("api key needs at least one scope")
+	ErrTooManyAPIKeys  = errors.New("too many api keys")
+	ErrScopeNotAllowed = errors.New("api key has no access to this route")
+)
+
+type APIKey struct {
+	ID         uint
+	UserID     uint
+	Username   string
+	Name       string
+	Scopes     []Scope
+	CreateDate string
+	LastUsed   string
+}
+
+type APIKeyRepo interface {
+	Add(userID uint, username string, name string, scopes []Scope) (key *APIKey, token string, err error)
+	GetAll(userID uint) ([]*APIKey, error)
+	// Authenticate finds the key and records its use
+	Authenticate(token string) (*APIKey, error)
+	Delete(userID uint, id uint) error
+	DeleteAll(userID uint) error
+}
+
+func ParseScopes(values []string) ([]Scope, error) {
+	if len(values) == 0 {
+		return nil, ErrNoScopes
+	}
+
+	scopes := make([]Scope, 0, len(values))
+	seen := make(map[Scope]bool, len(values))
+	for _, v := range values {
+		s := Scope(v)
+		switch s {
+		case ScopeRead, ScopePost, ScopeComment, ScopeVote:
+		default:
+			return nil, ErrBadScope
+		}
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+
+	return scopes, nil
+}
+
+func ValidateAPIKeyName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MaxAPIKeyNameLength {
+		return ErrBadAPIKeyName
+	}
+
+	return nil
+}
+
+// Scoped is false for sessions that come from a login.
+func (s *Session) Scoped() bool {
+	return s.APIKeyID != 0 || s.ClientID != ""
+}
+
+func (s *Session) Allows(scope Scope) bool {
+	if !s.Scoped() {
+		return true
+	}
+	for _, granted := range s.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newAPIKey() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", ErrUnableGenerateToken
+	}
+	token = APIKeyPrefix + hex.EncodeToString(buf)
+
+	return token, hashAPIKey(token), nil
+}
+
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func joinScopes(scopes []Scope) string {
+	values := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		values = append(values, string(s))
+	}
+
+	return strings.Join(values, ",")
+}
+
+func splitScopes(value string) []Scope {
+	scopes := make([]Scope, 0)
+	for _, v := range strings.Split(value, ",") {
+		if v != "" {
+			scopes = append(scopes, Scope(v))
+		}
+	}
+
+	return scopes
+}
+
+// lastUsedStale reports whether lastUsed is older than LastUsedInterval, keys
+// never used or with an unreadable date are stale.
+func lastUsedStale(lastUsed string, now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, lastUsed)
+
+	return err != nil || now.Sub(t) >= LastUsedInterval
+}
@@ -4,59 +4,235 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type JWTRepo struct {
 	Generator TokenGenerator
+	Keys      *KeySet
+	mu        *sync.RWMutex
+	// tokens stay stateless, so revoked ones are kept here until they expire
+	denied        map[string]int64
+	revokedBefore map[uint]int64
+	issued        map[uint][]*Session
 }
 
-func NewJWTRepo(generator TokenGenerator) *JWTRepo {
+func NewJWTRepo(generator TokenGenerator, keys *KeySet) *JWTRepo {
 	return &JWTRepo{
-		Generator: generator,
+		Generator:     generator,
+		Keys:          keys,
+		mu:            &sync.RWMutex{},
+		denied:        make(map[string]int64),
+		revokedBefore: make(map[uint]int64),
+		issued:        make(map[uint][]*Session),
 	}
 }
 
 var _ SessionRepo = (*JWTRepo)(nil)
 
-func (r *JWTRepo) Get(accessToken string) (*Session, error) {
+func (r *JWTRepo) parse(accessToken string) (*Session, jwt.MapClaims, error) {
 	tokenParts := strings.Split(accessToken, " ")
-	hashSecretGetter := func(token *jwt.Token) (interface{}, error) {
-		method, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok || method.Alg() != "HS256" {
+	if len(tokenParts) != 2 {
+		return nil, nil, ErrBadToken
+	}
+	publicKeyGetter := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := r.Keys.Verification(kid, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		// the alg header is chosen by the client, it has to agree with the key
+		if token.Method.Alg() != key.Algorithm {
 			return nil, ErrBadSigningMethod
 		}
 
-		return tokenSecret, nil
+		return key.Public(), nil
 	}
 
-	token, err := jwt.Parse(tokenParts[1], hashSecretGetter)
+	token, err := jwt.Parse(tokenParts[1], publicKeyGetter)
 	if err != nil || !token.Valid {
-		return nil, ErrBadToken
+		return nil, nil, ErrBadToken
 	}
 
 	payload, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return nil, ErrEmptyPayload
+		return nil, nil, ErrEmptyPayload
 	}
 	userInfo, ok := payload["user"].(map[string]interface{})
 	if !ok {
-		return nil, ErrEmptyUserInfo
+		return nil, nil, ErrEmptyUserInfo
 	}
 
-	id, err := strconv.Atoi(userInfo["id"].(string))
+	rawID, ok := userInfo["id"].(string)
+	if !ok {
+		return nil, nil, ErrBadToken
+	}
+	username, ok := userInfo["username"].(string)
+	if !ok {
+		return nil, nil, ErrBadToken
+	}
+	id, err := strconv.Atoi(rawID)
 	if err != nil {
-		return nil, err
+		return nil, nil, ErrBadToken
 	}
 
 	sess := &Session{
 		UserID:   uint(id),
-		Username: userInfo["username"].(string),
+		Username: username,
+		Token:    tokenParts[1],
 	}
+	if exp, ok := payload["exp"].(float64); ok {
+		sess.ExpirationDate = strconv.FormatInt(int64(exp), 10)
+	}
+	return sess, payload, nil
+}
+
+func (r *JWTRepo) Get(accessToken string) (*Session, error) {
+	sess, payload, err := r.parse(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.denied[sess.Token]; ok {
+		return nil, ErrTokenRevoked
+	}
+	iat, _ := payload["iat"].(float64)
+	if int64(iat) < r.revokedBefore[sess.UserID] {
+		return nil, ErrTokenRevoked
+	}
+
 	return sess, nil
 }
 
-func (r *JWTRepo) Add(username string, userID uint) (token string, err error) {
-	token, _, err = r.Generator.Generate(username, userID)
+func (r *JWTRepo) Add(username string, userID uint, client Client) (token string, err error) {
+	token, exp, err := r.Generator.Generate(username, userID)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.issued[userID] = append(r.issued[userID], &Session{
+		UserID:         userID,
+		Username:       username,
+		ExpirationDate: strconv.FormatInt(exp, 10),
+		Token:          token,
+		CreateDate:     time.Now().Format(time.RFC3339),
+		UserAgent:      client.UserAgent,
+		IP:             client.IP,
+	})
+
+	return token, nil
+}
+
+func (r *JWTRepo) GetAll(userID uint) ([]*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune(time.Now().Unix())
+
+	sessions := make([]*Session, 0, len(r.issued[userID]))
+	for _, sess := range r.issued[userID] {
+		copySession := *sess
+		sessions = append(sessions, &copySession)
+	}
+
+	return sessions, nil
+}
+
+func (r *JWTRepo) Delete(accessToken string) error {
+	sess, payload, err := r.parse(accessToken)
+	if err != nil {
+		return err
+	}
+	exp, _ := payload["exp"].(float64)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.denied[sess.Token]; ok {
+		return ErrTokenRevoked
+	}
+	r.denied[sess.Token] = int64(exp)
+
+	issued := r.issued[sess.UserID]
+	for i, s := range issued {
+		if s.Token == sess.Token {
+			r.issued[sess.UserID] = append(issued[:i], issued[i+1:]...)
+			break
+		}
+	}
+	r.prune(time.Now().Unix())
+
+	return nil
+}
+
+func (r *JWTRepo) DeleteAll(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sess := range r.issued[userID] {
+		exp, err := strconv.ParseInt(sess.ExpirationDate, 10, 64)
+		if err != nil {
+			return err
+		}
+		r.denied[sess.Token] = exp
+	}
+	delete(r.issued, userID)
+	r.revokedBefore[userID] = time.Now().Unix()
+	r.prune(time.Now().Unix())
 
-	return token, err
+	return nil
+}
+
+// DeleteOthers denies the other tokens the repository has issued to the user,
+// revokedBefore is left alone as it would cover token as well.
+func (r *JWTRepo) DeleteOthers(userID uint, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := make([]*Session, 0, 1)
+	for _, sess := range r.issued[userID] {
+		if sess.Token == token {
+			kept = append(kept, sess)
+			continue
+		}
+
+		exp, err := strconv.ParseInt(sess.ExpirationDate, 10, 64)
+		if err != nil {
+			return err
+		}
+		r.denied[sess.Token] = exp
+	}
+	r.issued[userID] = kept
+	r.prune(time.Now().Unix())
+
+	return nil
+}
+
+func (r *JWTRepo) prune(now int64) {
+	for token, exp := range r.denied {
+		if exp < now {
+			delete(r.denied, token)
+		}
+	}
+	for userID, sessions := range r.issued {
+		active := sessions[:0]
+		for _, sess := range sessions {
+			exp, err := strconv.ParseInt(sess.ExpirationDate, 10, 64)
+			if err == nil && exp >= now {
+				active = append(active, sess)
+			}
+		}
+		if len(active) == 0 {
+			delete(r.issued, userID)
+			continue
+		}
+		r.issued[userID] = active
+	}
 }
@@ -1,30 +1,59 @@
 package session
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/dgrijalva/jwt-go"
 	"strconv"
 	"time"
 )
 
-type JWTGenerator struct{}
+type JWTGenerator struct {
+	Keys     *KeySet
+	Lifetime time.Duration
+}
 
 var _ TokenGenerator = (*JWTGenerator)(nil)
 
+func NewJWTGenerator(keys *KeySet, lifetime time.Duration) *JWTGenerator {
+	return &JWTGenerator{
+		Keys:     keys,
+		Lifetime: lifetime,
+	}
+}
+
 func (g *JWTGenerator) Generate(username string, userID uint) (tokenStr string, exp int64, err error) {
 	now := time.Now()
-	exp = now.Add(3 * time.Hour).Unix()
+	lifetime := g.Lifetime
+	if lifetime <= 0 {
+		lifetime = DefaultAccessTokenLifetime
+	}
+	exp = now.Add(lifetime).Unix()
 	idStr := strconv.Itoa(int(userID))
+	// unique id keeps two tokens issued in the same second apart, so one can be revoked alone
+	jti := make([]byte, 16)
+	if _, err = rand.Read(jti); err != nil {
+		return "", 0, ErrUnableGenerateToken
+	}
+
+	key, err := g.Keys.Current(now)
+	if err != nil {
+		return "", 0, ErrUnableGenerateToken
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	token := jwt.NewWithClaims(key.Method(), jwt.MapClaims{
 		"user": map[string]interface{}{
 			"username": username,
 			"id":       idStr,
 		},
+		"jti": hex.EncodeToString(jti),
 		"iat": now.Unix(),
 		"exp": exp,
 	})
 
-	tokenStr, err = token.SignedString(tokenSecret)
+	token.Header["kid"] = key.ID
+
+	tokenStr, err = token.SignedString(key.Private)
 	if err != nil {
 		return "", 0, ErrUnableGenerateToken
 	}
@@ -0,0 +1,254 @@
+package session
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	// DefaultGracePeriod keeps a replaced key valid for the tokens it signed,
+	// it should not be shorter than the access token lifetime
+	DefaultGracePeriod = 2 * DefaultAccessTokenLifetime
+
+	minRSABits = 2048
+)
+
+var (
+	ErrNoSigningKey    = errors.New("no signing key is active")
+	ErrUnknownKey      = errors.New("unknown signing key")
+	ErrBadAlgorithm    = errors.New("signing algorithm must be RS256 or EdDSA")
+	ErrBadKey          = errors.New("key doesn't match the algorithm")
+	ErrDuplicateKeyID  = errors.New("signing key ids must be unique")
+	ErrNoPrivateKeyPEM = errors.New("no private key found in pem file")
+)
+
+// SigningKey signs tokens from ActiveFrom until a newer key becomes active.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	Private    crypto.Signer
+	ActiveFrom time.Time
+}
+
+// KeySet picks the key to sign with and the keys tokens may be verified with.
+// Rotation is scheduled by giving the next key a later ActiveFrom, it is
+// published in the JWKS before it is used.
+type KeySet struct {
+	keys  []*SigningKey
+	Grace time.Duration
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+func NewKeySet(keys []*SigningKey, grace time.Duration) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+
+	ids := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if ids[k.ID] {
+			return nil, ErrDuplicateKeyID
+		}
+		ids[k.ID] = true
+
+		if err := checkKey(k.Algorithm, k.Private); err != nil {
+			return nil, err
+		}
+	}
+
+	sorted := append([]*SigningKey(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+
+	return &KeySet{
+		keys:  sorted,
+		Grace: grace,
+	}, nil
+}
+
+func checkKey(algorithm string, key crypto.Signer) error {
+	switch algorithm {
+	case AlgRS256:
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok || rsaKey.N.BitLen() < minRSABits {
+			return ErrBadKey
+		}
+	case AlgEdDSA:
+		if _, ok := key.(ed25519.PrivateKey); !ok {
+			return ErrBadKey
+		}
+	default:
+		return ErrBadAlgorithm
+	}
+
+	return nil
+}
+
+// LoadSigningKey reads a PKCS#8 (or PKCS#1 for RSA) private key in PEM.
+func LoadSigningKey(id string, algorithm string, path string, activeFrom time.Time) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoPrivateKeyPEM
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, ErrNoPrivateKeyPEM
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrBadKey
+	}
+	if err = checkKey(algorithm, signer); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:         id,
+		Algorithm:  algorithm,
+		Private:    signer,
+		ActiveFrom: activeFrom,
+	}, nil
+}
+
+// GenerateSigningKey makes a throwaway Ed25519 key, for setups without keys
+// configured.
+func GenerateSigningKey(id string) (*SigningKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:        id,
+		Algorithm: AlgEdDSA,
+		Private:   private,
+	}, nil
+}
+
+func (k *SigningKey) Method() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return SigningMethodEdDSA
+	}
+
+	return jwt.SigningMethodRS256
+}
+
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// Current is the newest key already active.
+func (s *KeySet) Current(now time.Time) (*SigningKey, error) {
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].ActiveFrom.After(now) {
+			return s.keys[i], nil
+		}
+	}
+
+	return nil, ErrNoSigningKey
+}
+
+// retired is true once the key after k has been active longer than the grace
+// period.
+func (s *KeySet) retired(i int, now time.Time) bool {
+	for _, next := range s.keys[i+1:] {
+		if next.ActiveFrom.After(s.keys[i].ActiveFrom) {
+			return !next.ActiveFrom.Add(s.Grace).After(now)
+		}
+	}
+
+	return false
+}
+
+// Verification returns the key to check a token signed with kid.
+func (s *KeySet) Verification(kid string, now time.Time) (*SigningKey, error) {
+	for i, k := range s.keys {
+		if k.ID == kid {
+			if s.retired(i, now) {
+				return nil, ErrUnknownKey
+			}
+			return k, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// JWKS lists the public halves of the keys that aren't retired, upcoming
+// ones included.
+func (s *KeySet) JWKS(now time.Time) *JWKS {
+	set := &JWKS{
+		Keys: make([]*JWK, 0, len(s.keys)),
+	}
+	for i, k := range s.keys {
+		if s.retired(i, now) {
+			continue
+		}
+
+		jwk := &JWK{
+			ID:        k.ID,
+			Use:       "sig",
+			Algorithm: k.Algorithm,
+		}
+		switch public := k.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -2,24 +2,51 @@ package session
 
 import (
 	"github.com/vlasdash/redditclone/internal/user"
+	"strings"
 )
 
 type Manager struct {
 	userRepo    user.UserRepo
 	sessionRepo SessionRepo
+	apiKeyRepo  APIKeyRepo
+	oauth       OAuthVerifier
 }
 
-func NewManager(sr SessionRepo, ur user.UserRepo) *Manager {
+func NewManager(sr SessionRepo, ur user.UserRepo, kr APIKeyRepo, ov OAuthVerifier) *Manager {
 	return &Manager{
 		userRepo:    ur,
 		sessionRepo: sr,
+		apiKeyRepo:  kr,
+		oauth:       ov,
 	}
 }
 
+// Create accepts "Bearer <jwt>" as well as api keys and OAuth access tokens.
 func (m *Manager) Create(accessToken string) (*Session, error) {
+	tokenParts := strings.Split(accessToken, " ")
+	if len(tokenParts) == 2 && strings.HasPrefix(tokenParts[1], OAuthTokenPrefix) {
+		return m.oauth.Verify(tokenParts[1])
+	}
+	if len(tokenParts) == 2 && strings.HasPrefix(tokenParts[1], APIKeyPrefix) {
+		key, err := m.apiKeyRepo.Authenticate(tokenParts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return &Session{
+			UserID:     key.UserID,
+			Username:   key.Username,
+			CreateDate: key.CreateDate,
+			APIKeyID:   key.ID,
+			Scopes:     key.Scopes,
+		}, nil
+	}
+
 	return m.sessionRepo.Get(accessToken)
 }
 
+// HasUserExist also copies the current role of the user to s, so a changed
+// role or a ban takes effect on the next request.
 func (m *Manager) HasUserExist(s *Session) (bool, error) {
 	u, err := m.userRepo.GetByID(s.UserID)
 	if err == user.ErrNoExist {
@@ -32,6 +59,10 @@ func (m *Manager) HasUserExist(s *Session) (bool, error) {
 	if u.Username != s.Username {
 		return false, nil
 	}
+	if u.Banned {
+		return false, ErrUserBanned
+	}
+	s.Role = u.Role
 
 	return true, nil
 }
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type MemoryAPIKeyRepo struct {
+	keys   map[string]*APIKey
+	lastID uint
+	mu     *sync.Mutex
+}
+
+var _ APIKeyRepo = (*MemoryAPIKeyRepo)(nil)
+
+func NewMemoryAPIKeyRepo() *MemoryAPIKeyRepo {
+	return &MemoryAPIKeyRepo{
+		keys: make(map[string]*APIKey),
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (r *MemoryAPIKeyRepo) Add(userID uint, username string, name string, scopes []Scope) (*APIKey, string, error) {
+	token, hash, err := newAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			count++
+		}
+	}
+	if count >= MaxAPIKeys {
+		return nil, "", ErrTooManyAPIKeys
+	}
+
+	r.lastID++
+	key := &APIKey{
+		ID:         r.lastID,
+		UserID:     userID,
+		Username:   username,
+		Name:       name,
+		Scopes:     append([]Scope(nil), scopes...),
+		CreateDate: time.Now().Format(time.RFC3339),
+	}
+	r.keys[hash] = key
+	result := *key
+
+	return &result, token, nil
+}
+
+func (r *MemoryAPIKeyRepo) GetAll(userID uint) ([]*APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]*APIKey, 0)
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			key := *k
+			keys = append(keys, &key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys, nil
+}
+
+func (r *MemoryAPIKeyRepo) Authenticate(token string) (*APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[hashAPIKey(token)]
+	if !ok {
+		return nil, ErrBadToken
+	}
+	k.LastUsed = time.Now().Format(time.RFC3339)
+	key := *k
+
+	return &key, nil
+}
+
+func (r *MemoryAPIKeyRepo) Delete(userID uint, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, k := range r.keys {
+		if k.ID == id && k.UserID == userID {
+			delete(r.keys, hash)
+			return nil
+		}
+	}
+
+	return ErrNoAPIKey
+}
+
+func (r *MemoryAPIKeyRepo) DeleteAll(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, k := range r.keys {
+		if k.UserID == userID {
+			delete(r.keys, hash)
+		}
+	}
+
+	return nil
+}
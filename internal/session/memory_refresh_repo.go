@@ -0,0 +1,114 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryRefreshRepo struct {
+	mu       *sync.Mutex
+	tokens   map[string]*RefreshToken
+	Lifetime time.Duration
+}
+
+var _ RefreshRepo = (*MemoryRefreshRepo)(nil)
+
+func NewMemoryRefreshRepo(lifetime time.Duration) *MemoryRefreshRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultRefreshTokenLifetime
+	}
+
+	return &MemoryRefreshRepo{
+		mu:       &sync.Mutex{},
+		tokens:   make(map[string]*RefreshToken),
+		Lifetime: lifetime,
+	}
+}
+
+func (r *MemoryRefreshRepo) Add(username string, userID uint) (string, error) {
+	familyID, err := newFamilyID()
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.add(familyID, username, userID)
+}
+
+func (r *MemoryRefreshRepo) add(familyID string, username string, userID uint) (string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	r.tokens[hash] = &RefreshToken{
+		FamilyID:       familyID,
+		UserID:         userID,
+		Username:       username,
+		ExpirationDate: time.Now().Add(r.Lifetime).Unix(),
+	}
+
+	return token, nil
+}
+
+func (r *MemoryRefreshRepo) Rotate(token string) (*RefreshToken, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rt, ok := r.tokens[hashRefreshToken(token)]
+	if !ok {
+		return nil, "", ErrBadToken
+	}
+	if rt.Used {
+		r.deleteFamily(rt.FamilyID)
+		return nil, "", ErrTokenReused
+	}
+	if time.Now().Unix() > rt.ExpirationDate {
+		return nil, "", ErrTokenExpired
+	}
+
+	rt.Used = true
+	newToken, err := r.add(rt.FamilyID, rt.Username, rt.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	copyToken := *rt
+	return &copyToken, newToken, nil
+}
+
+func (r *MemoryRefreshRepo) DeleteFamily(token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rt, ok := r.tokens[hashRefreshToken(token)]
+	if !ok {
+		return ErrBadToken
+	}
+	r.deleteFamily(rt.FamilyID)
+
+	return nil
+}
+
+func (r *MemoryRefreshRepo) DeleteAll(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, rt := range r.tokens {
+		if rt.UserID == userID {
+			delete(r.tokens, hash)
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryRefreshRepo) deleteFamily(familyID string) {
+	for hash, rt := range r.tokens {
+		if rt.FamilyID == familyID {
+			delete(r.tokens, hash)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package session
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MySQLAPIKeyRepo struct {
+	DB *sql.DB
+}
+
+var _ APIKeyRepo = (*MySQLAPIKeyRepo)(nil)
+
+func NewMySQLAPIKeyRepo(db *sql.DB) *MySQLAPIKeyRepo {
+	return &MySQLAPIKeyRepo{
+		DB: db,
+	}
+}
+
+func (r *MySQLAPIKeyRepo) Add(userID uint, username string, name string, scopes []Scope) (*APIKey, string, error) {
+	var count int
+	row := r.DB.QueryRow("SELECT COUNT(*) FROM api_keys WHERE user_id = ?", userID)
+	if err := row.Scan(&count); err != nil {
+		return nil, "", err
+	}
+	if count >= MaxAPIKeys {
+		return nil, "", ErrTooManyAPIKeys
+	}
+
+	token, hash, err := newAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &APIKey{
+		UserID:     userID,
+		Username:   username,
+		Name:       name,
+		Scopes:     scopes,
+		CreateDate: time.Now().Format(time.RFC3339),
+	}
+	result, err := r.DB.Exec(
+		"INSERT INTO api_keys (`token_hash`, `user_id`, `username`, `name`, `scopes`, `create_date`) VALUES (?, ?, ?, ?, ?, ?)",
+		hash,
+		userID,
+		username,
+		name,
+		joinScopes(scopes),
+		key.CreateDate,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, "", err
+	}
+	key.ID = uint(id)
+
+	return key, token, nil
+}
+
+func (r *MySQLAPIKeyRepo) GetAll(userID uint) ([]*APIKey, error) {
+	rows, err := r.DB.Query(
+		"SELECT id, username, name, scopes, create_date, last_used FROM api_keys WHERE user_id = ? ORDER BY id",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*APIKey, 0)
+	for rows.Next() {
+		key := &APIKey{UserID: userID}
+		var scopes string
+		err = rows.Scan(&key.ID, &key.Username, &key.Name, &scopes, &key.CreateDate, &key.LastUsed)
+		if err != nil {
+			return nil, err
+		}
+		key.Scopes = splitScopes(scopes)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *MySQLAPIKeyRepo) Authenticate(token string) (*APIKey, error) {
+	hash := hashAPIKey(token)
+	row := r.DB.QueryRow(
+		"SELECT id, user_id, username, name, scopes, create_date, last_used FROM api_keys WHERE token_hash = ?",
+		hash,
+	)
+
+	key := &APIKey{}
+	var scopes string
+	err := row.Scan(&key.ID, &key.UserID, &key.Username, &key.Name, &scopes, &key.CreateDate, &key.LastUsed)
+	if err == sql.ErrNoRows {
+		return nil, ErrBadToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = splitScopes(scopes)
+
+	now := time.Now()
+	if !lastUsedStale(key.LastUsed, now) {
+		return key, nil
+	}
+	key.LastUsed = now.Format(time.RFC3339)
+	_, err = r.DB.Exec(
+		"UPDATE api_keys SET last_used = ? WHERE id = ?",
+		key.LastUsed,
+		key.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *MySQLAPIKeyRepo) Delete(userID uint, id uint) error {
+	result, err := r.DB.Exec(
+		"DELETE FROM api_keys WHERE id = ? AND user_id = ?",
+		id,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoAPIKey
+	}
+
+	return nil
+}
+
+func (r *MySQLAPIKeyRepo) DeleteAll(userID uint) error {
+	_, err := r.DB.Exec("DELETE FROM api_keys WHERE user_id = ?", userID)
+
+	return err
+}
@@ -0,0 +1,146 @@
+package session
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MySQLRefreshRepo struct {
+	DB       *sql.DB
+	Lifetime time.Duration
+}
+
+var _ RefreshRepo = (*MySQLRefreshRepo)(nil)
+
+func NewMySQLRefreshRepo(db *sql.DB, lifetime time.Duration) *MySQLRefreshRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultRefreshTokenLifetime
+	}
+
+	return &MySQLRefreshRepo{
+		DB:       db,
+		Lifetime: lifetime,
+	}
+}
+
+func (r *MySQLRefreshRepo) Add(username string, userID uint) (string, error) {
+	familyID, err := newFamilyID()
+	if err != nil {
+		return "", err
+	}
+
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.DB.Exec(
+		"INSERT INTO refresh_tokens (`token_hash`, `family_id`, `user_id`, `username`, `expiration_date`) VALUES (?, ?, ?, ?, ?)",
+		hash,
+		familyID,
+		userID,
+		username,
+		time.Now().Add(r.Lifetime).Unix(),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (r *MySQLRefreshRepo) Rotate(token string) (*RefreshToken, string, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	row := tx.QueryRow(
+		"SELECT family_id, user_id, username, expiration_date, used FROM refresh_tokens WHERE token_hash = ? FOR UPDATE",
+		hashRefreshToken(token),
+	)
+
+	rt := &RefreshToken{}
+	err = row.Scan(&rt.FamilyID, &rt.UserID, &rt.Username, &rt.ExpirationDate, &rt.Used)
+	if err == sql.ErrNoRows {
+		return nil, "", ErrBadToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// a used token showing up again means it was stolen, so the whole chain goes
+	if rt.Used {
+		_, err = tx.Exec("DELETE FROM refresh_tokens WHERE family_id = ?", rt.FamilyID)
+		if err != nil {
+			return nil, "", err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, "", err
+		}
+
+		return nil, "", ErrTokenReused
+	}
+	if time.Now().Unix() > rt.ExpirationDate {
+		return nil, "", ErrTokenExpired
+	}
+
+	_, err = tx.Exec("UPDATE refresh_tokens SET used = 1 WHERE token_hash = ?", hashRefreshToken(token))
+	if err != nil {
+		return nil, "", err
+	}
+
+	newToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO refresh_tokens (`token_hash`, `family_id`, `user_id`, `username`, `expiration_date`) VALUES (?, ?, ?, ?, ?)",
+		hash,
+		rt.FamilyID,
+		rt.UserID,
+		rt.Username,
+		time.Now().Add(r.Lifetime).Unix(),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, "", err
+	}
+
+	return rt, newToken, nil
+}
+
+func (r *MySQLRefreshRepo) DeleteFamily(token string) error {
+	result, err := r.DB.Exec(
+		"DELETE FROM refresh_tokens WHERE family_id = (SELECT family_id FROM (SELECT family_id FROM refresh_tokens WHERE token_hash = ?) AS t)",
+		hashRefreshToken(token),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrBadToken
+	}
+
+	return nil
+}
+
+func (r *MySQLRefreshRepo) DeleteAll(userID uint) error {
+	_, err := r.DB.Exec(
+		"DELETE FROM refresh_tokens WHERE user_id = ?",
+		userID,
+	)
+
+	return err
+}
@@ -1,13 +1,18 @@
 package session
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	_ "github.com/go-sql-driver/mysql"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// MySQLRepo keys the sessions on the sha256 of the token: signed tokens are too
+// long for a primary key, and a leaked table can't be replayed. The sessions it
+// returns carry that hash as Token.
 type MySQLRepo struct {
 	DB        *sql.DB
 	Generator TokenGenerator
@@ -22,15 +27,33 @@ func NewMySQLRepo(db *sql.DB, generator TokenGenerator) *MySQLRepo {
 	}
 }
 
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func (r *MySQLRepo) Get(accessToken string) (*Session, error) {
-	accessToken = strings.Split(accessToken, " ")[1]
+	tokenParts := strings.Split(accessToken, " ")
+	if len(tokenParts) != 2 {
+		return nil, ErrBadToken
+	}
+	tokenHash := hashAccessToken(tokenParts[1])
 	row := r.DB.QueryRow(
-		"SELECT username, user_id, expiration_date FROM sessions WHERE token = ?",
-		accessToken,
+		"SELECT username, user_id, expiration_date, create_date, user_agent, ip FROM sessions WHERE token_hash = ?",
+		tokenHash,
 	)
 
-	session := &Session{}
-	err := row.Scan(&session.Username, &session.UserID, &session.ExpirationDate)
+	session := &Session{
+		Token: tokenHash,
+	}
+	err := row.Scan(
+		&session.Username,
+		&session.UserID,
+		&session.ExpirationDate,
+		&session.CreateDate,
+		&session.UserAgent,
+		&session.IP,
+	)
 	if err == sql.ErrNoRows {
 		return nil, ErrBadToken
 	}
@@ -50,18 +73,21 @@ func (r *MySQLRepo) Get(accessToken string) (*Session, error) {
 	return session, nil
 }
 
-func (r *MySQLRepo) Add(username string, userID uint) (tokenStr string, err error) {
+func (r *MySQLRepo) Add(username string, userID uint, client Client) (tokenStr string, err error) {
 	token, exp, err := r.Generator.Generate(username, userID)
 	if err != nil {
 		return "", ErrUnableGenerateToken
 	}
 
 	_, err = r.DB.Exec(
-		"INSERT INTO sessions (`token`, `username`, `user_id`, `expiration_date`) VALUES (?, ?, ?, ?)",
-		token,
+		"INSERT INTO sessions (`token_hash`, `username`, `user_id`, `expiration_date`, `create_date`, `user_agent`, `ip`) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		hashAccessToken(token),
 		username,
 		userID,
 		exp,
+		time.Now().Format(time.RFC3339),
+		client.UserAgent,
+		client.IP,
 	)
 	if err != nil {
 		return "", err
@@ -69,3 +95,92 @@ func (r *MySQLRepo) Add(username string, userID uint) (tokenStr string, err erro
 
 	return token, nil
 }
+
+func (r *MySQLRepo) GetAll(userID uint) ([]*Session, error) {
+	rows, err := r.DB.Query(
+		"SELECT token_hash, username, user_id, expiration_date, create_date, user_agent, ip FROM sessions WHERE user_id = ?",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now().Unix()
+	sessions := make([]*Session, 0)
+	for rows.Next() {
+		session := &Session{}
+		err = rows.Scan(
+			&session.Token,
+			&session.Username,
+			&session.UserID,
+			&session.ExpirationDate,
+			&session.CreateDate,
+			&session.UserAgent,
+			&session.IP,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		expirationDate, err := strconv.Atoi(session.ExpirationDate)
+		if err != nil {
+			return nil, err
+		}
+		if int(now) > expirationDate {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *MySQLRepo) Delete(accessToken string) error {
+	tokenParts := strings.Split(accessToken, " ")
+	if len(tokenParts) != 2 {
+		return ErrBadToken
+	}
+
+	result, err := r.DB.Exec(
+		"DELETE FROM sessions WHERE token_hash = ?",
+		hashAccessToken(tokenParts[1]),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrBadToken
+	}
+
+	return nil
+}
+
+func (r *MySQLRepo) DeleteAll(userID uint) error {
+	_, err := r.DB.Exec(
+		"DELETE FROM sessions WHERE user_id = ?",
+		userID,
+	)
+
+	return err
+}
+
+// DeleteOthers revokes every session of the user except the one with token, the
+// Token of a session returned by Get.
+func (r *MySQLRepo) DeleteOthers(userID uint, token string) error {
+	_, err := r.DB.Exec(
+		"DELETE FROM sessions WHERE user_id = ? AND token_hash <> ?",
+		userID,
+		token,
+	)
+
+	return err
+}
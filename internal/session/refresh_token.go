@@ -0,0 +1,32 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+func newRefreshToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", ErrUnableGenerateToken
+	}
+	token = hex.EncodeToString(buf)
+
+	return token, hashRefreshToken(token), nil
+}
+
+// only hashes are stored, so a leaked table can't be replayed
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newFamilyID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", ErrUnableGenerateToken
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -3,14 +3,16 @@ package session
 import (
 	"context"
 	"errors"
-	"github.com/vlasdash/redditclone/config"
+	"github.com/vlasdash/redditclone/internal/user"
+	"time"
 )
 
 const (
 	SessionKey = "session-key"
-)
 
-var tokenSecret = []byte(config.C.App.SecretKey)
+	DefaultAccessTokenLifetime  = 15 * time.Minute
+	DefaultRefreshTokenLifetime = 30 * 24 * time.Hour
+)
 
 var (
 	ErrBadSigningMethod    = errors.New("invalid signing method")
@@ -21,17 +23,60 @@ var (
 	ErrNoAuthentication    = errors.New("unauthorized")
 	ErrUnableGenerateToken = errors.New("can`t create token for user")
 	ErrTokenExpired        = errors.New("token expiration date has passed")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+	ErrTokenReused         = errors.New("refresh token has already been used")
+	ErrUserBanned          = errors.New("account is banned")
 )
 
 type Session struct {
 	UserID         uint
 	Username       string
 	ExpirationDate string
+	Token          string
+	CreateDate     string
+	UserAgent      string
+	IP             string
+	// APIKeyID or ClientID is set when the request is authorized by an api key
+	// or an OAuth client, such requests are limited to Scopes
+	APIKeyID uint
+	ClientID string
+	Scopes   []Scope
+	// Role is filled from the account on every request by HasUserExist
+	Role user.Role
+}
+
+type Client struct {
+	UserAgent string
+	IP        string
 }
 
 type SessionRepo interface {
 	Get(accessToken string) (*Session, error)
-	Add(username string, userID uint) (tokenStr string, err error)
+	Add(username string, userID uint, client Client) (tokenStr string, err error)
+	GetAll(userID uint) ([]*Session, error)
+	Delete(accessToken string) error
+	DeleteAll(userID uint) error
+	DeleteOthers(userID uint, token string) error
+}
+
+type RefreshToken struct {
+	FamilyID       string
+	UserID         uint
+	Username       string
+	ExpirationDate int64
+	Used           bool
+}
+
+type RefreshRepo interface {
+	Add(username string, userID uint) (token string, err error)
+	Rotate(token string) (*RefreshToken, string, error)
+	DeleteFamily(token string) error
+	DeleteAll(userID uint) error
+}
+
+// OAuthVerifier resolves the access tokens issued to third-party clients.
+type OAuthVerifier interface {
+	Verify(accessToken string) (*Session, error)
 }
 
 type TokenGenerator interface {
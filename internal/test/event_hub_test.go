@@ -0,0 +1,63 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/event"
+	"testing"
+)
+
+func TestHubPublish(t *testing.T) {
+	hub := event.NewHub(2)
+
+	post := hub.Subscribe(event.PostTopic("1"))
+	listing := hub.Subscribe(event.CommunityTopic("music"), event.TopicAll)
+	other := hub.Subscribe(event.PostTopic("2"))
+	defer hub.Unsubscribe(post)
+	defer hub.Unsubscribe(listing)
+	defer hub.Unsubscribe(other)
+
+	e := &event.Event{Type: event.TypeCommentAdded, PostID: "1", CommentID: "3"}
+	hub.Publish(e, event.PostTopic("1"), event.CommunityTopic("music"), event.TopicAll)
+
+	if got := <-post.C; got != e {
+		t.Errorf("wrong result, expected %#v, got %#v", e, got)
+	}
+	if got := <-listing.C; got != e {
+		t.Errorf("wrong result, expected %#v, got %#v", e, got)
+	}
+	if len(listing.C) != 0 {
+		t.Errorf("expected event to be delivered once per subscription, got %d more", len(listing.C))
+	}
+	if len(other.C) != 0 {
+		t.Errorf("expected no events for other post, got %d", len(other.C))
+	}
+}
+
+func TestHubSlowSubscriber(t *testing.T) {
+	hub := event.NewHub(1)
+
+	sub := hub.Subscribe(event.TopicAll)
+	defer hub.Unsubscribe(sub)
+
+	first := &event.Event{Type: event.TypePostAdded, PostID: "1"}
+	hub.Publish(first, event.TopicAll)
+	hub.Publish(&event.Event{Type: event.TypePostAdded, PostID: "2"}, event.TopicAll)
+
+	if got := <-sub.C; got != first {
+		t.Errorf("wrong result, expected %#v, got %#v", first, got)
+	}
+	if len(sub.C) != 0 {
+		t.Errorf("expected overflowing event to be dropped, got %d", len(sub.C))
+	}
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	hub := event.NewHub(1)
+
+	sub := hub.Subscribe(event.UserTopic(1))
+	hub.Unsubscribe(sub)
+	hub.Publish(&event.Event{Type: event.TypeNotification}, event.UserTopic(1))
+
+	if len(sub.C) != 0 {
+		t.Errorf("expected no events after unsubscribe, got %d", len(sub.C))
+	}
+}
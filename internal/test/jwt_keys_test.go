@@ -0,0 +1,311 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/vlasdash/redditclone/internal/session"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestKeySet(t *testing.T) *session.KeySet {
+	key, err := session.GenerateSigningKey("test")
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	keys, err := session.NewKeySet([]*session.SigningKey{key}, 0)
+	if err != nil {
+		t.Fatalf("unable create key set: %v", err)
+	}
+
+	return keys
+}
+
+func newTestRSAKey(t *testing.T, id string, activeFrom time.Time) *session.SigningKey {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable generate rsa key: %v", err)
+	}
+
+	return &session.SigningKey{
+		ID:         id,
+		Algorithm:  session.AlgRS256,
+		Private:    private,
+		ActiveFrom: activeFrom,
+	}
+}
+
+func TestJWTSignedWithCurrentKey(t *testing.T) {
+	now := time.Now()
+	edKey, err := session.GenerateSigningKey("ed")
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	edKey.ActiveFrom = now.Add(-time.Hour)
+	rsaKey := newTestRSAKey(t, "rsa", now.Add(-time.Minute))
+
+	keys, err := session.NewKeySet([]*session.SigningKey{rsaKey, edKey}, time.Hour)
+	if err != nil {
+		t.Fatalf("unable create key set: %v", err)
+	}
+	repo := session.NewJWTRepo(session.NewJWTGenerator(keys, 0), keys)
+
+	token, err := repo.Add("username", 1, session.Client{})
+	if err != nil {
+		t.Fatalf("unable add session: %v", err)
+	}
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("unable parse token: %v", err)
+	}
+	if parsed.Header["kid"] != "rsa" || parsed.Header["alg"] != session.AlgRS256 {
+		t.Errorf("wrong header, expected rsa key, got %v", parsed.Header)
+		return
+	}
+
+	sess, err := repo.Get("Bearer " + token)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if sess.UserID != 1 || sess.Username != "username" {
+		t.Errorf("wrong result, got session %#v", sess)
+		return
+	}
+
+	// a token signed by the key still in its grace period stays valid
+	old := jwt.NewWithClaims(session.SigningMethodEdDSA, jwt.MapClaims{
+		"user": map[string]interface{}{
+			"username": "username",
+			"id":       "1",
+		},
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	})
+	old.Header["kid"] = "ed"
+	oldToken, err := old.SignedString(edKey.Private)
+	if err != nil {
+		t.Fatalf("unable sign token: %v", err)
+	}
+	if _, err = repo.Get("Bearer " + oldToken); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+}
+
+func TestJWTBadKey(t *testing.T) {
+	keys := newTestKeySet(t)
+	repo := session.NewJWTRepo(session.NewJWTGenerator(keys, 0), keys)
+	claims := jwt.MapClaims{
+		"user": map[string]interface{}{
+			"username": "username",
+			"id":       "1",
+		},
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+
+	_, foreign, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	current, err := keys.Current(time.Now())
+	if err != nil {
+		t.Fatalf("unable get key: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    interface{}
+	}{
+		{"unknown kid", session.SigningMethodEdDSA, "unknown", foreign},
+		{"foreign key", session.SigningMethodEdDSA, current.ID, foreign},
+		{"hmac with public key", jwt.SigningMethodHS256, current.ID, []byte(current.Public().(ed25519.PublicKey))},
+	}
+	for _, c := range cases {
+		token := jwt.NewWithClaims(c.method, claims)
+		token.Header["kid"] = c.kid
+		tokenStr, err := token.SignedString(c.key)
+		if err != nil {
+			t.Fatalf("[%s] unable sign token: %v", c.name, err)
+		}
+		if _, err = repo.Get("Bearer " + tokenStr); err != session.ErrBadToken {
+			t.Errorf("[%s] expected error %v, got error %v", c.name, session.ErrBadToken, err)
+		}
+	}
+}
+
+func TestJWTBadClaims(t *testing.T) {
+	keys := newTestKeySet(t)
+	repo := session.NewJWTRepo(session.NewJWTGenerator(keys, 0), keys)
+	current, err := keys.Current(time.Now())
+	if err != nil {
+		t.Fatalf("unable get key: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		user map[string]interface{}
+	}{
+		{"numeric id", map[string]interface{}{"username": "username", "id": 1}},
+		{"bad id", map[string]interface{}{"username": "username", "id": "one"}},
+		{"no username", map[string]interface{}{"id": "1"}},
+	}
+	for _, c := range cases {
+		token := jwt.NewWithClaims(current.Method(), jwt.MapClaims{
+			"user": c.user,
+			"exp":  time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = current.ID
+		tokenStr, err := token.SignedString(current.Private)
+		if err != nil {
+			t.Fatalf("[%s] unable sign token: %v", c.name, err)
+		}
+		if _, err = repo.Get("Bearer " + tokenStr); err != session.ErrBadToken {
+			t.Errorf("[%s] expected error %v, got error %v", c.name, session.ErrBadToken, err)
+		}
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	now := time.Now()
+	older, err := session.GenerateSigningKey("older")
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	older.ActiveFrom = now.Add(-2 * time.Hour)
+	current, err := session.GenerateSigningKey("current")
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	current.ActiveFrom = now.Add(-10 * time.Minute)
+	next := newTestRSAKey(t, "next", now.Add(time.Hour))
+
+	keys, err := session.NewKeySet([]*session.SigningKey{next, current, older}, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("unable create key set: %v", err)
+	}
+
+	signing, err := keys.Current(now)
+	if err != nil || signing.ID != "current" {
+		t.Errorf("wrong result, expected current key, got %#v, error %v", signing, err)
+		return
+	}
+	if _, err = keys.Verification("older", now); err != nil {
+		t.Errorf("wrong result, older key should be in grace period, got error: %v", err)
+		return
+	}
+	if _, err = keys.Verification("older", now.Add(25*time.Minute)); err != session.ErrUnknownKey {
+		t.Errorf("expected error %v, got error %v", session.ErrUnknownKey, err)
+		return
+	}
+	signing, err = keys.Current(now.Add(2 * time.Hour))
+	if err != nil || signing.ID != "next" {
+		t.Errorf("wrong result, expected next key, got %#v, error %v", signing, err)
+		return
+	}
+
+	if set := keys.JWKS(now); len(set.Keys) != 3 {
+		t.Errorf("wrong result, expected 3 keys, got %#v", set.Keys)
+		return
+	}
+	set := keys.JWKS(now.Add(25 * time.Minute))
+	if len(set.Keys) != 2 {
+		t.Errorf("wrong result, expected 2 keys, got %#v", set.Keys)
+		return
+	}
+	for _, k := range set.Keys {
+		switch k.ID {
+		case "current":
+			if k.KeyType != "OKP" || k.Curve != "Ed25519" || k.Algorithm != session.AlgEdDSA || k.X == "" {
+				t.Errorf("wrong ed25519 jwk: %#v", k)
+			}
+		case "next":
+			if k.KeyType != "RSA" || k.Algorithm != session.AlgRS256 || k.E != "AQAB" || k.N == "" {
+				t.Errorf("wrong rsa jwk: %#v", k)
+			}
+		default:
+			t.Errorf("unexpected key %s", k.ID)
+		}
+	}
+}
+
+func TestNewKeySetError(t *testing.T) {
+	edKey, err := session.GenerateSigningKey("key")
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("unable generate rsa key: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		keys []*session.SigningKey
+		err  error
+	}{
+		{"no keys", nil, session.ErrNoSigningKey},
+		{"duplicate id", []*session.SigningKey{edKey, edKey}, session.ErrDuplicateKeyID},
+		{"unknown algorithm", []*session.SigningKey{{ID: "a", Algorithm: "HS256", Private: edKey.Private}}, session.ErrBadAlgorithm},
+		{"algorithm mismatch", []*session.SigningKey{{ID: "a", Algorithm: session.AlgRS256, Private: edKey.Private}}, session.ErrBadKey},
+		{"short rsa key", []*session.SigningKey{{ID: "a", Algorithm: session.AlgRS256, Private: small}}, session.ErrBadKey},
+	}
+	for _, c := range cases {
+		if _, err = session.NewKeySet(c.keys, 0); err != c.err {
+			t.Errorf("[%s] expected error %v, got error %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatalf("unable marshal key: %v", err)
+	}
+	edPath := filepath.Join(dir, "ed.pem")
+	err = os.WriteFile(edPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatalf("unable write key: %v", err)
+	}
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable generate rsa key: %v", err)
+	}
+	rsaPath := filepath.Join(dir, "rsa.pem")
+	err = os.WriteFile(rsaPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaPrivate)}), 0600)
+	if err != nil {
+		t.Fatalf("unable write key: %v", err)
+	}
+
+	activeFrom := time.Now().Add(time.Hour)
+	key, err := session.LoadSigningKey("ed", session.AlgEdDSA, edPath, activeFrom)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if !edPrivate.Equal(key.Private) || !key.ActiveFrom.Equal(activeFrom) {
+		t.Errorf("wrong result, got key %#v", key)
+		return
+	}
+	if _, err = session.LoadSigningKey("rsa", session.AlgRS256, rsaPath, activeFrom); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if _, err = session.LoadSigningKey("rsa", session.AlgEdDSA, rsaPath, activeFrom); err != session.ErrBadKey {
+		t.Errorf("expected error %v, got error %v", session.ErrBadKey, err)
+	}
+}
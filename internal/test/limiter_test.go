@@ -0,0 +1,233 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/limiter"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPolicyBackoff(t *testing.T) {
+	p := limiter.Policy{
+		Free:            2,
+		Delay:           time.Second,
+		MaxDelay:        10 * time.Second,
+		Lockout:         10,
+		LockoutDuration: time.Hour,
+		Window:          2 * time.Hour,
+	}
+	cases := []struct {
+		count int
+		wait  time.Duration
+	}{
+		{count: 1, wait: 0},
+		{count: 2, wait: 0},
+		{count: 3, wait: time.Second},
+		{count: 4, wait: 2 * time.Second},
+		{count: 5, wait: 4 * time.Second},
+		{count: 6, wait: 8 * time.Second},
+		{count: 7, wait: 10 * time.Second},
+		{count: 9, wait: 10 * time.Second},
+		{count: 10, wait: time.Hour},
+	}
+
+	for _, c := range cases {
+		a := &limiter.Attempts{Count: c.count, Last: 1000}
+		if wait := p.Until(a).Sub(time.Unix(1000, 0)); wait != c.wait {
+			t.Errorf("wrong wait after %d attempts, expected %v, got %v", c.count, c.wait, wait)
+		}
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	p := limiter.Policy{
+		Free:            1,
+		Delay:           time.Minute,
+		MaxDelay:        time.Hour,
+		Lockout:         3,
+		LockoutDuration: 30 * time.Minute,
+		Window:          time.Hour,
+	}
+	l := limiter.NewLimiter(limiter.NewMemoryRepo(), "login", map[limiter.Kind]limiter.Policy{
+		limiter.KindUsername: p,
+	})
+	now := time.Unix(1000000, 0)
+	user := limiter.Username("username")
+	// kinds without a policy aren't limited
+	ip := limiter.IP("127.0.0.1")
+
+	for i := 0; i < 2; i++ {
+		if err := l.Add(now, user, ip); err != nil {
+			t.Fatalf("unable add attempt: %v", err)
+		}
+	}
+	wait, err := l.Wait(now, user, ip)
+	if err != nil || wait != time.Minute {
+		t.Errorf("wrong result, expected wait %v, got %v, error %v", time.Minute, wait, err)
+		return
+	}
+
+	if err = l.Add(now, user); err != nil {
+		t.Fatalf("unable add attempt: %v", err)
+	}
+	if wait, _ = l.Wait(now.Add(time.Minute), user); wait != 29*time.Minute {
+		t.Errorf("wrong result, expected lockout of %v, got %v", 29*time.Minute, wait)
+		return
+	}
+	if wait, _ = l.Wait(now.Add(2*time.Hour), user); wait != 0 {
+		t.Errorf("wrong result, attempts out of window should be forgotten, got wait %v", wait)
+		return
+	}
+
+	// the count starts over after the window
+	if err = l.Add(now.Add(2*time.Hour), user); err != nil {
+		t.Fatalf("unable add attempt: %v", err)
+	}
+	if wait, _ = l.Wait(now.Add(2*time.Hour), user); wait != 0 {
+		t.Errorf("wrong result, expected no wait, got %v", wait)
+		return
+	}
+
+	if err = l.Reset(user); err != nil {
+		t.Fatalf("unable reset: %v", err)
+	}
+	if a, _ := l.Repo.Get("login:user:" + limiter.Username("username").ID); a.Count != 0 {
+		t.Errorf("wrong result, expected no attempts after reset, got %d", a.Count)
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	p := limiter.Policy{
+		Free:            2,
+		Delay:           time.Minute,
+		MaxDelay:        time.Hour,
+		Lockout:         10,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+	l := limiter.NewLimiter(limiter.NewMemoryRepo(), "login", map[limiter.Kind]limiter.Policy{
+		limiter.KindUsername: p,
+		limiter.KindIP:       p,
+	})
+	now := time.Unix(1000000, 0)
+	user := limiter.Username("username")
+	ip := limiter.IP("127.0.0.1")
+
+	// a concurrent burst gets its numbers one by one, only the free attempts
+	// and the one checked after them pass
+	var allowed int32
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait, err := l.Reserve(now, user, ip)
+			if err == nil && wait == 0 {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != int32(p.Free+1) {
+		t.Errorf("wrong result, expected %d allowed attempts, got %d", p.Free+1, allowed)
+		return
+	}
+
+	// refused attempts aren't counted, so the wait is honest
+	a, _ := l.Repo.Get("login:user:" + limiter.Username("username").ID)
+	wait, err := l.Reserve(now.Add(time.Second), user)
+	if err != nil || wait != p.Until(a).Sub(now.Add(time.Second)) {
+		t.Errorf("wrong result, expected wait %v, got %v, error %v", p.Until(a).Sub(now.Add(time.Second)), wait, err)
+		return
+	}
+	if b, _ := l.Repo.Get("login:user:" + limiter.Username("username").ID); b.Count != a.Count {
+		t.Errorf("wrong result, refused attempt counted, got %d attempts instead of %d", b.Count, a.Count)
+		return
+	}
+
+	if err = l.Release(ip); err != nil {
+		t.Fatalf("unable release attempt: %v", err)
+	}
+	if b, _ := l.Repo.Get("login:ip:127.0.0.1"); b.Count != a.Count-1 {
+		t.Errorf("wrong result, expected %d attempts after release, got %d", a.Count-1, b.Count)
+	}
+}
+
+func TestLimiterKeysBounded(t *testing.T) {
+	long := strings.Repeat("a", 1000)
+	if id := limiter.Username(long).ID; len(id) != 64 || id == limiter.Username("a").ID {
+		t.Errorf("wrong username key %q", id)
+	}
+	if limiter.Email("User@Example.com").ID != limiter.Email("user@example.com").ID {
+		t.Errorf("email keys must not depend on case")
+	}
+}
+
+func TestMySQLAttemptRepo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	now := time.Unix(5000, 0)
+	since := time.Unix(1400, 0)
+	key := "login:ip:127.0.0.1"
+	// the row already holds a concurrent attempt, the count is the one of the upsert
+	mock.
+		ExpectExec("INSERT INTO attempts \\(`key`, `count`, `last_attempt`, `prev_attempt`\\) VALUES \\(\\?, LAST_INSERT_ID\\(1\\), \\?, 0\\) ON DUPLICATE KEY UPDATE").
+		WithArgs(key, now.Unix(), since.Unix(), since.Unix()).
+		WillReturnResult(sqlmock.NewResult(4, 2))
+	mock.
+		ExpectQuery("SELECT `count`, `last_attempt`, `prev_attempt` FROM attempts WHERE `key` = ?").
+		WithArgs(key).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "last_attempt", "prev_attempt"}).AddRow(5, now.Unix()+1, now.Unix()-10))
+	mock.
+		ExpectQuery("SELECT `count`, `last_attempt`, `prev_attempt` FROM attempts WHERE `key` = ?").
+		WithArgs("login:ip:10.0.0.1").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "last_attempt", "prev_attempt"}))
+	mock.
+		ExpectExec("UPDATE attempts SET `count` = GREATEST\\(`count` - 1, 0\\) WHERE `key` = ?").
+		WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.
+		ExpectExec("DELETE FROM attempts WHERE `last_attempt` < ?").
+		WithArgs(since.Unix()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	repo := limiter.NewMySQLRepo(db)
+	a, err := repo.Add(key, now, since)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if a.Key != key || a.Count != 4 || a.Last != now.Unix() || a.Prev != now.Unix()-10 {
+		t.Errorf("wrong result, got %#v", a)
+		return
+	}
+	a, err = repo.Get("login:ip:10.0.0.1")
+	if err != nil || a.Count != 0 {
+		t.Errorf("wrong result, expected no attempts, got %#v, error %v", a, err)
+		return
+	}
+	if err = repo.Undo(key); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err = repo.Prune(since); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package test
+
+import (
+	"bytes"
+	"github.com/vlasdash/redditclone/internal/mail"
+	"strings"
+	"testing"
+)
+
+func TestLogMailer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	mailer := mail.NewLogMailer(buf)
+
+	err := mailer.Send(&mail.Message{
+		To:      "user@example.com",
+		Subject: "Password reset",
+		Body:    "token",
+	})
+	if err != nil {
+		t.Fatalf("unable send message: %v", err)
+	}
+
+	out := buf.String()
+	for _, part := range []string{"To: user@example.com\n", "Subject: Password reset\n", "\n\ntoken\n"} {
+		if !strings.Contains(out, part) {
+			t.Errorf("expected %q in output %q", part, out)
+		}
+	}
+}
@@ -49,6 +49,35 @@ func (mr *MockCommentRepoMockRecorder) Add(userID, body interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockCommentRepo)(nil).Add), userID, body)
 }
 
+// AddReply mocks base method.
+func (m *MockCommentRepo) AddReply(userID uint, parentID, body string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReply", userID, parentID, body)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReply indicates an expected call of AddReply.
+func (mr *MockCommentRepoMockRecorder) AddReply(userID, parentID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReply", reflect.TypeOf((*MockCommentRepo)(nil).AddReply), userID, parentID, body)
+}
+
+// Anonymize mocks base method.
+func (m *MockCommentRepo) Anonymize(authorID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", authorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockCommentRepoMockRecorder) Anonymize(authorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockCommentRepo)(nil).Anonymize), authorID)
+}
+
 // Delete mocks base method.
 func (m *MockCommentRepo) Delete(id string, userID uint) error {
 	m.ctrl.T.Helper()
@@ -63,6 +92,35 @@ func (mr *MockCommentRepoMockRecorder) Delete(id, userID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentRepo)(nil).Delete), id, userID)
 }
 
+// Downvote mocks base method.
+func (m *MockCommentRepo) Downvote(id string, voter uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Downvote", id, voter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Downvote indicates an expected call of Downvote.
+func (mr *MockCommentRepoMockRecorder) Downvote(id, voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Downvote", reflect.TypeOf((*MockCommentRepo)(nil).Downvote), id, voter)
+}
+
+// GetAuthorStats mocks base method.
+func (m *MockCommentRepo) GetAuthorStats(id uint) (*comment.AuthorStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorStats", id)
+	ret0, _ := ret[0].(*comment.AuthorStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthorStats indicates an expected call of GetAuthorStats.
+func (mr *MockCommentRepoMockRecorder) GetAuthorStats(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorStats", reflect.TypeOf((*MockCommentRepo)(nil).GetAuthorStats), id)
+}
+
 // GetByID mocks base method.
 func (m *MockCommentRepo) GetByID(id string) (*comment.Comment, error) {
 	m.ctrl.T.Helper()
@@ -77,3 +135,89 @@ func (mr *MockCommentRepoMockRecorder) GetByID(id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCommentRepo)(nil).GetByID), id)
 }
+
+// GetPageByAuthor mocks base method.
+func (m *MockCommentRepo) GetPageByAuthor(id uint, opts comment.PageOptions) (*comment.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPageByAuthor", id, opts)
+	ret0, _ := ret[0].(*comment.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPageByAuthor indicates an expected call of GetPageByAuthor.
+func (mr *MockCommentRepoMockRecorder) GetPageByAuthor(id, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPageByAuthor", reflect.TypeOf((*MockCommentRepo)(nil).GetPageByAuthor), id, opts)
+}
+
+// GetRevisions mocks base method.
+func (m *MockCommentRepo) GetRevisions(id string) ([]*comment.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", id)
+	ret0, _ := ret[0].([]*comment.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockCommentRepoMockRecorder) GetRevisions(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockCommentRepo)(nil).GetRevisions), id)
+}
+
+// Remove mocks base method.
+func (m *MockCommentRepo) Remove(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockCommentRepoMockRecorder) Remove(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockCommentRepo)(nil).Remove), id)
+}
+
+// Unvote mocks base method.
+func (m *MockCommentRepo) Unvote(id string, voter uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unvote", id, voter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unvote indicates an expected call of Unvote.
+func (mr *MockCommentRepoMockRecorder) Unvote(id, voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unvote", reflect.TypeOf((*MockCommentRepo)(nil).Unvote), id, voter)
+}
+
+// Update mocks base method.
+func (m *MockCommentRepo) Update(id string, userID uint, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, userID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCommentRepoMockRecorder) Update(id, userID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCommentRepo)(nil).Update), id, userID, body)
+}
+
+// Upvote mocks base method.
+func (m *MockCommentRepo) Upvote(id string, voter uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upvote", id, voter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upvote indicates an expected call of Upvote.
+func (mr *MockCommentRepoMockRecorder) Upvote(id, voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upvote", reflect.TypeOf((*MockCommentRepo)(nil).Upvote), id, voter)
+}
@@ -48,6 +48,20 @@ func (mr *MockPostRepoMockRecorder) AddComment(postID, commentID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddComment", reflect.TypeOf((*MockPostRepo)(nil).AddComment), postID, commentID)
 }
 
+// Anonymize mocks base method.
+func (m *MockPostRepo) Anonymize(authorID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", authorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockPostRepoMockRecorder) Anonymize(authorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockPostRepo)(nil).Anonymize), authorID)
+}
+
 // Create mocks base method.
 func (m *MockPostRepo) Create(post *post.Post) (string, error) {
 	m.ctrl.T.Helper()
@@ -120,6 +134,21 @@ func (mr *MockPostRepoMockRecorder) GetAll() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPostRepo)(nil).GetAll))
 }
 
+// GetAuthorStats mocks base method.
+func (m *MockPostRepo) GetAuthorStats(id uint) (*post.AuthorStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorStats", id)
+	ret0, _ := ret[0].(*post.AuthorStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthorStats indicates an expected call of GetAuthorStats.
+func (mr *MockPostRepoMockRecorder) GetAuthorStats(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorStats", reflect.TypeOf((*MockPostRepo)(nil).GetAuthorStats), id)
+}
+
 // GetByAuthor mocks base method.
 func (m *MockPostRepo) GetByAuthor(id uint) ([]*post.Post, error) {
 	m.ctrl.T.Helper()
@@ -150,6 +179,21 @@ func (mr *MockPostRepoMockRecorder) GetByCategory(category interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCategory", reflect.TypeOf((*MockPostRepo)(nil).GetByCategory), category)
 }
 
+// GetByCommentIDs mocks base method.
+func (m *MockPostRepo) GetByCommentIDs(ids []string) ([]*post.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByCommentIDs", ids)
+	ret0, _ := ret[0].([]*post.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByCommentIDs indicates an expected call of GetByCommentIDs.
+func (mr *MockPostRepoMockRecorder) GetByCommentIDs(ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCommentIDs", reflect.TypeOf((*MockPostRepo)(nil).GetByCommentIDs), ids)
+}
+
 // GetByID mocks base method.
 func (m *MockPostRepo) GetByID(id string, viewsUpdate int) (*post.Post, error) {
 	m.ctrl.T.Helper()
@@ -165,6 +209,138 @@ func (mr *MockPostRepoMockRecorder) GetByID(id, viewsUpdate interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPostRepo)(nil).GetByID), id, viewsUpdate)
 }
 
+// GetPage mocks base method.
+func (m *MockPostRepo) GetPage(opts post.PageOptions) (*post.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPage", opts)
+	ret0, _ := ret[0].(*post.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPage indicates an expected call of GetPage.
+func (mr *MockPostRepoMockRecorder) GetPage(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPage", reflect.TypeOf((*MockPostRepo)(nil).GetPage), opts)
+}
+
+// GetPageByAuthor mocks base method.
+func (m *MockPostRepo) GetPageByAuthor(id uint, opts post.PageOptions) (*post.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPageByAuthor", id, opts)
+	ret0, _ := ret[0].(*post.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPageByAuthor indicates an expected call of GetPageByAuthor.
+func (mr *MockPostRepoMockRecorder) GetPageByAuthor(id, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPageByAuthor", reflect.TypeOf((*MockPostRepo)(nil).GetPageByAuthor), id, opts)
+}
+
+// GetPageByCategories mocks base method.
+func (m *MockPostRepo) GetPageByCategories(categories []string, opts post.PageOptions) (*post.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPageByCategories", categories, opts)
+	ret0, _ := ret[0].(*post.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPageByCategories indicates an expected call of GetPageByCategories.
+func (mr *MockPostRepoMockRecorder) GetPageByCategories(categories, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPageByCategories", reflect.TypeOf((*MockPostRepo)(nil).GetPageByCategories), categories, opts)
+}
+
+// GetPageByCategory mocks base method.
+func (m *MockPostRepo) GetPageByCategory(category string, opts post.PageOptions) (*post.Page, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPageByCategory", category, opts)
+	ret0, _ := ret[0].(*post.Page)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPageByCategory indicates an expected call of GetPageByCategory.
+func (mr *MockPostRepoMockRecorder) GetPageByCategory(category, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPageByCategory", reflect.TypeOf((*MockPostRepo)(nil).GetPageByCategory), category, opts)
+}
+
+// GetPinned mocks base method.
+func (m *MockPostRepo) GetPinned(category string) ([]*post.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPinned", category)
+	ret0, _ := ret[0].([]*post.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPinned indicates an expected call of GetPinned.
+func (mr *MockPostRepoMockRecorder) GetPinned(category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPinned", reflect.TypeOf((*MockPostRepo)(nil).GetPinned), category)
+}
+
+// GetRevisions mocks base method.
+func (m *MockPostRepo) GetRevisions(postID string) ([]*post.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", postID)
+	ret0, _ := ret[0].([]*post.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockPostRepoMockRecorder) GetRevisions(postID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockPostRepo)(nil).GetRevisions), postID)
+}
+
+// Remove mocks base method.
+func (m *MockPostRepo) Remove(postID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", postID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockPostRepoMockRecorder) Remove(postID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockPostRepo)(nil).Remove), postID)
+}
+
+// SetLocked mocks base method.
+func (m *MockPostRepo) SetLocked(postID string, locked bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLocked", postID, locked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLocked indicates an expected call of SetLocked.
+func (mr *MockPostRepoMockRecorder) SetLocked(postID, locked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLocked", reflect.TypeOf((*MockPostRepo)(nil).SetLocked), postID, locked)
+}
+
+// SetPinned mocks base method.
+func (m *MockPostRepo) SetPinned(postID string, pinned bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPinned", postID, pinned)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPinned indicates an expected call of SetPinned.
+func (mr *MockPostRepoMockRecorder) SetPinned(postID, pinned interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPinned", reflect.TypeOf((*MockPostRepo)(nil).SetPinned), postID, pinned)
+}
+
 // Unvote mocks base method.
 func (m *MockPostRepo) Unvote(postID string, voter uint) error {
 	m.ctrl.T.Helper()
@@ -179,6 +355,20 @@ func (mr *MockPostRepoMockRecorder) Unvote(postID, voter interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unvote", reflect.TypeOf((*MockPostRepo)(nil).Unvote), postID, voter)
 }
 
+// Update mocks base method.
+func (m *MockPostRepo) Update(postID string, userID uint, title, text, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", postID, userID, title, text, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPostRepoMockRecorder) Update(postID, userID, title, text, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPostRepo)(nil).Update), postID, userID, title, text, url)
+}
+
 // Upvote mocks base method.
 func (m *MockPostRepo) Upvote(postID string, voter uint) error {
 	m.ctrl.T.Helper()
@@ -35,18 +35,60 @@ func (m *MockSessionRepo) EXPECT() *MockSessionRepoMockRecorder {
 }
 
 // Add mocks base method.
-func (m *MockSessionRepo) Add(username string, userID uint) (string, error) {
+func (m *MockSessionRepo) Add(username string, userID uint, client session.Client) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", username, userID)
+	ret := m.ctrl.Call(m, "Add", username, userID, client)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockSessionRepoMockRecorder) Add(username, userID interface{}) *gomock.Call {
+func (mr *MockSessionRepoMockRecorder) Add(username, userID, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockSessionRepo)(nil).Add), username, userID, client)
+}
+
+// Delete mocks base method.
+func (m *MockSessionRepo) Delete(accessToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", accessToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSessionRepoMockRecorder) Delete(accessToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionRepo)(nil).Delete), accessToken)
+}
+
+// DeleteAll mocks base method.
+func (m *MockSessionRepo) DeleteAll(userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAll", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAll indicates an expected call of DeleteAll.
+func (mr *MockSessionRepoMockRecorder) DeleteAll(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockSessionRepo)(nil).DeleteAll), userID)
+}
+
+// DeleteOthers mocks base method.
+func (m *MockSessionRepo) DeleteOthers(userID uint, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOthers", userID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOthers indicates an expected call of DeleteOthers.
+func (mr *MockSessionRepoMockRecorder) DeleteOthers(userID, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockSessionRepo)(nil).Add), username, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOthers", reflect.TypeOf((*MockSessionRepo)(nil).DeleteOthers), userID, token)
 }
 
 // Get mocks base method.
@@ -64,6 +106,103 @@ func (mr *MockSessionRepoMockRecorder) Get(accessToken interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionRepo)(nil).Get), accessToken)
 }
 
+// GetAll mocks base method.
+func (m *MockSessionRepo) GetAll(userID uint) ([]*session.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", userID)
+	ret0, _ := ret[0].([]*session.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockSessionRepoMockRecorder) GetAll(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockSessionRepo)(nil).GetAll), userID)
+}
+
+// MockRefreshRepo is a mock of RefreshRepo interface.
+type MockRefreshRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshRepoMockRecorder
+}
+
+// MockRefreshRepoMockRecorder is the mock recorder for MockRefreshRepo.
+type MockRefreshRepoMockRecorder struct {
+	mock *MockRefreshRepo
+}
+
+// NewMockRefreshRepo creates a new mock instance.
+func NewMockRefreshRepo(ctrl *gomock.Controller) *MockRefreshRepo {
+	mock := &MockRefreshRepo{ctrl: ctrl}
+	mock.recorder = &MockRefreshRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefreshRepo) EXPECT() *MockRefreshRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockRefreshRepo) Add(username string, userID uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", username, userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockRefreshRepoMockRecorder) Add(username, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockRefreshRepo)(nil).Add), username, userID)
+}
+
+// DeleteAll mocks base method.
+func (m *MockRefreshRepo) DeleteAll(userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAll", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAll indicates an expected call of DeleteAll.
+func (mr *MockRefreshRepoMockRecorder) DeleteAll(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockRefreshRepo)(nil).DeleteAll), userID)
+}
+
+// DeleteFamily mocks base method.
+func (m *MockRefreshRepo) DeleteFamily(token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFamily", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFamily indicates an expected call of DeleteFamily.
+func (mr *MockRefreshRepoMockRecorder) DeleteFamily(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFamily", reflect.TypeOf((*MockRefreshRepo)(nil).DeleteFamily), token)
+}
+
+// Rotate mocks base method.
+func (m *MockRefreshRepo) Rotate(token string) (*session.RefreshToken, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", token)
+	ret0, _ := ret[0].(*session.RefreshToken)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockRefreshRepoMockRecorder) Rotate(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockRefreshRepo)(nil).Rotate), token)
+}
+
 // MockTokenGenerator is a mock of TokenGenerator interface.
 type MockTokenGenerator struct {
 	ctrl     *gomock.Controller
@@ -143,6 +143,46 @@ func TestCommentAdd(t *testing.T) {
 	})
 }
 
+func TestCommentAddReply(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		_, err := commentRepo.AddReply(1, primitive.NewObjectID().Hex(), "body")
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+
+		_, err := commentRepo.AddReply(1, primitive.NewObjectID().Hex(), "body")
+
+		if !mongo.IsDuplicateKeyError(err) {
+			t.Errorf("wrong result, expected error mongo.DuplicateKeyError, got %v", err)
+			return
+		}
+	})
+}
+
 func TestCommentDelete(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	defer mt.Close()
@@ -249,3 +289,346 @@ func TestCommentDelete(t *testing.T) {
 		}
 	})
 }
+
+func TestCommentUpvote(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("bad id", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+
+		err := commentRepo.Upvote("bad_id", 1)
+		if err != comment.ErrInvalidID {
+			t.Errorf("wrong result, expected error %v, got %v", comment.ErrInvalidID, err)
+			return
+		}
+	})
+
+	mt.Run("change vote", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+		id := primitive.NewObjectID()
+
+		mt.AddMockResponses(bson.D{{"ok", 1}, {"value", bson.D{{"_id", id}}}})
+
+		err := commentRepo.Upvote(id.Hex(), 1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+	})
+
+	mt.Run("new vote", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+		id := primitive.NewObjectID()
+
+		mt.AddMockResponses(
+			bson.D{{"ok", 1}, {"value", nil}},
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		err := commentRepo.Upvote(id.Hex(), 1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+		id := primitive.NewObjectID()
+
+		mt.AddMockResponses(
+			bson.D{{"ok", 1}, {"value", nil}},
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateCursorResponse(0, "reddit.comments", mtest.FirstBatch),
+		)
+
+		err := commentRepo.Downvote(id.Hex(), 1)
+		if err != comment.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", comment.ErrNotExist, err)
+			return
+		}
+	})
+
+	mt.Run("find and update error", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+		expectedError := "command failed"
+
+		mt.AddMockResponses(bson.D{{"ok", 0}})
+
+		err := commentRepo.Downvote(primitive.NewObjectID().Hex(), 1)
+		if err.Error() != expectedError {
+			t.Errorf("wrong result, expected error %v, got %v", expectedError, err.Error())
+			return
+		}
+	})
+}
+
+func TestCommentUnvote(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+		id := primitive.NewObjectID()
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "reddit.comments", mtest.FirstBatch, bson.D{
+				{"_id", id},
+				{"votes", []*comment.Vote{
+					{
+						UserID: 2,
+						Value:  comment.Like,
+					},
+					{
+						UserID: 1,
+						Value:  comment.Unlike,
+					},
+				}},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		err := commentRepo.Unvote(id.Hex(), 1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+	})
+
+	mt.Run("no vote", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+		id := primitive.NewObjectID()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "reddit.comments", mtest.FirstBatch, bson.D{
+			{"_id", id},
+			{"votes", make([]*comment.Vote, 0)},
+		}))
+
+		err := commentRepo.Unvote(id.Hex(), 1)
+		if err != comment.ErrVoteNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", comment.ErrVoteNotExist, err)
+			return
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		collection := mt.Coll
+		commentRepo := comment.MongoRepo{
+			Comments: collection,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.comments", mtest.FirstBatch))
+
+		err := commentRepo.Unvote(primitive.NewObjectID().Hex(), 1)
+		if err != comment.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", comment.ErrNotExist, err)
+			return
+		}
+	})
+}
+
+func TestCommentGetAuthorStats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		commentRepo := comment.MongoRepo{
+			Comments: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.comments", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "count", Value: 5},
+			{Key: "karma", Value: -2},
+		}))
+
+		stats, err := commentRepo.GetAuthorStats(1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		expected := &comment.AuthorStats{Count: 5, Karma: -2}
+		if !reflect.DeepEqual(stats, expected) {
+			t.Errorf("wrong result, expected %#v, got %#v", expected, stats)
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		commentRepo := comment.MongoRepo{
+			Comments: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+
+		_, err := commentRepo.GetAuthorStats(1)
+		if err == nil {
+			t.Errorf("wrong result, expected error")
+		}
+	})
+}
+
+func TestCommentGetPageByAuthor(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	firstID := primitive.NewObjectID()
+	secondID := primitive.NewObjectID()
+
+	mt.Run("success", func(mt *mtest.T) {
+		commentRepo := comment.MongoRepo{
+			Comments: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.comments", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: secondID},
+			{Key: "author_id", Value: 1},
+			{Key: "body", Value: "second"},
+			{Key: "upvotes_count", Value: 1},
+		}, bson.D{
+			{Key: "_id", Value: firstID},
+			{Key: "author_id", Value: 1},
+			{Key: "body", Value: "first"},
+			{Key: "upvotes_count", Value: 1},
+		}))
+
+		page, err := commentRepo.GetPageByAuthor(1, comment.PageOptions{Limit: 1, Sort: comment.SortNew})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if len(page.Comments) != 1 || page.Comments[0].ID != secondID.Hex() {
+			t.Errorf("wrong result, got %#v", page.Comments)
+		}
+		if page.Next != comment.NextCursor(page.Comments[0], comment.SortNew) {
+			t.Errorf("wrong next cursor %q", page.Next)
+		}
+	})
+
+	mt.Run("invalid cursor", func(mt *mtest.T) {
+		commentRepo := comment.MongoRepo{
+			Comments: mt.Coll,
+		}
+
+		after := comment.NextCursor(&comment.Comment{ID: firstID.Hex()}, comment.SortNew)
+		_, err := commentRepo.GetPageByAuthor(1, comment.PageOptions{Limit: 1, Sort: comment.SortTop, After: after})
+		if err != comment.ErrInvalidCursor {
+			t.Errorf("expected error %v, got %v", comment.ErrInvalidCursor, err)
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		commentRepo := comment.MongoRepo{
+			Comments: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+
+		_, err := commentRepo.GetPageByAuthor(1, comment.PageOptions{Limit: 1, Sort: comment.SortTop})
+		if err == nil {
+			t.Errorf("wrong result, expected error")
+		}
+	})
+}
+
+func TestMemoryCommentGetPageByAuthor(t *testing.T) {
+	repo := comment.NewMemoryRepo()
+
+	ids := make([]string, 0, 3)
+	for _, body := range []string{"first", "second", "third"} {
+		id, err := repo.Add(1, body)
+		if err != nil {
+			t.Fatalf("unable add comment: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	_, err := repo.Add(2, "other")
+	if err != nil {
+		t.Fatalf("unable add comment: %v", err)
+	}
+	err = repo.Upvote(ids[1], 3)
+	if err != nil {
+		t.Fatalf("unable upvote: %v", err)
+	}
+
+	cases := []struct {
+		sort     comment.Sort
+		expected []string
+	}{
+		{sort: comment.SortNew, expected: []string{ids[2], ids[1], ids[0]}},
+		{sort: comment.SortTop, expected: []string{ids[1], ids[2], ids[0]}},
+	}
+	for _, c := range cases {
+		got := make([]string, 0, len(c.expected))
+		opts := comment.PageOptions{Limit: 2, Sort: c.sort}
+		for {
+			page, err := repo.GetPageByAuthor(1, opts)
+			if err != nil {
+				t.Fatalf("unable get page: %v", err)
+			}
+			for _, comm := range page.Comments {
+				got = append(got, comm.ID)
+			}
+			if page.Next == "" {
+				break
+			}
+			opts.After = page.Next
+		}
+
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("sort %s: expected %v, got %v", c.sort, c.expected, got)
+		}
+	}
+}
+
+func TestCommentAnonymize(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		commentRepo := comment.MongoRepo{
+			Comments: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}))
+
+		err := commentRepo.Anonymize(1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		commentRepo := comment.MongoRepo{
+			Comments: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+
+		err := commentRepo.Anonymize(1)
+		if err == nil {
+			t.Errorf("wrong result, expected error")
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/community"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"reflect"
+	"testing"
+)
+
+func TestCommunityGetByName(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+		expected := &community.Community{
+			Name:       "golang",
+			OwnerID:    1,
+			CreateDate: "date",
+			Settings: community.Settings{
+				Description:  "gophers",
+				Rules:        []string{"be nice"},
+				AllowedTypes: []string{community.TypeText},
+				Restricted:   true,
+			},
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+			{Key: "create_date", Value: "date"},
+			{Key: "description", Value: "gophers"},
+			{Key: "rules", Value: bson.A{"be nice"}},
+			{Key: "allowed_types", Value: bson.A{"text"}},
+			{Key: "restricted", Value: true},
+		}))
+
+		c, err := communityRepo.GetByName("golang")
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if !reflect.DeepEqual(c, expected) {
+			t.Errorf("wrong result, expected %#v, got %#v", expected, c)
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch))
+
+		_, err := communityRepo.GetByName("golang")
+		if err != community.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrNotExist, err)
+		}
+	})
+}
+
+func TestCommunityCreate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := communityRepo.Create(&community.Community{Name: "golang", OwnerID: 1})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+
+	mt.Run("duplicate", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+
+		err := communityRepo.Create(&community.Community{Name: "golang", OwnerID: 1})
+		if err != community.ErrAlreadyExist {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrAlreadyExist, err)
+		}
+	})
+}
+
+func TestCommunityUpdateSettings(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("no access", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+		}))
+
+		err := communityRepo.UpdateSettings("golang", 2, community.Settings{Restricted: true})
+		if err != community.ErrNoAccess {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrNoAccess, err)
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+		}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := communityRepo.UpdateSettings("golang", 1, community.Settings{Restricted: true})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+
+	mt.Run("update error", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+		}), bson.D{{Key: "ok", Value: 0}})
+
+		err := communityRepo.UpdateSettings("golang", 1, community.Settings{})
+		if _, ok := err.(mongo.CommandError); !ok {
+			t.Errorf("wrong result, expected command error, got %v", err)
+		}
+	})
+}
+
+func TestCommunityAddModerator(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("no access", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+			{Key: "moderators", Value: bson.A{2}},
+		}))
+
+		err := communityRepo.AddModerator("golang", 2, 3)
+		if err != community.ErrNoAccess {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrNoAccess, err)
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+		}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := communityRepo.AddModerator("golang", 1, 3)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+}
+
+func TestCommunityBan(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("owner", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+			{Key: "moderators", Value: bson.A{2}},
+		}))
+
+		err := communityRepo.Ban("golang", 2, 1)
+		if err != community.ErrNoAccess {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrNoAccess, err)
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		communityRepo := community.MongoRepo{
+			Communities: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.communities", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "golang"},
+			{Key: "owner_id", Value: 1},
+			{Key: "moderators", Value: bson.A{2}},
+		}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := communityRepo.Ban("golang", 2, 3)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+}
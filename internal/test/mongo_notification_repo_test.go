@@ -0,0 +1,155 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/notification"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"testing"
+)
+
+func TestNotificationAdd(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		notificationRepo := notification.MongoRepo{
+			Notifications: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		n := &notification.Notification{
+			UserID:  1,
+			Type:    notification.TypePostReply,
+			ActorID: 2,
+			PostID:  primitive.NewObjectID().Hex(),
+		}
+		err := notificationRepo.Add(n)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if n.ID == "" || n.CreateDate == "" {
+			t.Errorf("wrong result, expected id and create date to be set, got %#v", n)
+		}
+	})
+
+	mt.Run("milestone", func(mt *mtest.T) {
+		notificationRepo := notification.MongoRepo{
+			Notifications: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "nModified", Value: 0},
+			bson.E{Key: "upserted", Value: bson.A{bson.D{
+				{Key: "index", Value: 0},
+				{Key: "_id", Value: primitive.NewObjectID()},
+			}}},
+		))
+
+		err := notificationRepo.Add(&notification.Notification{
+			UserID: 1,
+			Type:   notification.TypeMilestone,
+			PostID: "post",
+			Score:  10,
+			Key:    notification.MilestoneKey("post", "", 10),
+		})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+
+	mt.Run("milestone already reached", func(mt *mtest.T) {
+		notificationRepo := notification.MongoRepo{
+			Notifications: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}))
+
+		err := notificationRepo.Add(&notification.Notification{
+			UserID: 1,
+			Type:   notification.TypeMilestone,
+			PostID: "post",
+			Score:  10,
+			Key:    notification.MilestoneKey("post", "", 10),
+		})
+		if err != notification.ErrAlreadyExist {
+			t.Errorf("wrong result, expected error %v, got %v", notification.ErrAlreadyExist, err)
+		}
+	})
+}
+
+func TestNotificationMarkRead(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("invalid id", func(mt *mtest.T) {
+		notificationRepo := notification.MongoRepo{
+			Notifications: mt.Coll,
+		}
+
+		err := notificationRepo.MarkRead("invalid", 1)
+		if err != notification.ErrInvalidID {
+			t.Errorf("wrong result, expected error %v, got %v", notification.ErrInvalidID, err)
+		}
+	})
+
+	mt.Run("not exist", func(mt *mtest.T) {
+		notificationRepo := notification.MongoRepo{
+			Notifications: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		err := notificationRepo.MarkRead(primitive.NewObjectID().Hex(), 1)
+		if err != notification.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", notification.ErrNotExist, err)
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		notificationRepo := notification.MongoRepo{
+			Notifications: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := notificationRepo.MarkRead(primitive.NewObjectID().Hex(), 1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+}
+
+func TestNotificationGetByUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		notificationRepo := notification.MongoRepo{
+			Notifications: mt.Coll,
+		}
+
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.notifications", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "user_id", Value: 1},
+			{Key: "type", Value: notification.TypeMention},
+			{Key: "actor_id", Value: 2},
+			{Key: "post_id", Value: "post"},
+			{Key: "read", Value: false},
+			{Key: "create_date", Value: "2022-10-10T10:10:10Z"},
+		}))
+
+		notifications, err := notificationRepo.GetByUser(1, notification.Filter{UnreadOnly: true})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if len(notifications) != 1 || notifications[0].ID != id.Hex() || notifications[0].ActorID != 2 {
+			t.Errorf("wrong result, got %#v", notifications)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPostGetAll(t *testing.T) {
@@ -560,6 +561,43 @@ func TestPostUpvote(t *testing.T) {
 		}
 	})
 
+	mt.Run("refresh sort keys", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+		id := primitive.NewObjectID()
+		createDate := time.Now().Format(time.RFC3339)
+		created, _ := time.Parse(time.RFC3339, createDate)
+
+		mt.AddMockResponses(bson.D{
+			{"ok", 1},
+			{"value", bson.D{{"_id", id}}},
+		}, mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch, bson.D{
+			{"_id", id},
+			{"create_date", createDate},
+			{"upvotes_count", 3},
+			{"downvotes_count", 1},
+		}), bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}})
+
+		err := postRepo.Upvote(id.Hex(), 1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+
+		// skip the vote itself and the lookup of the counters
+		mt.GetStartedEvent()
+		mt.GetStartedEvent()
+		set := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$set")
+		if score := set.Document().Lookup("score").AsInt64(); score != 2 {
+			t.Errorf("wrong result, expected score %d, got %d", 2, score)
+		}
+		if hot := set.Document().Lookup("hot").Double(); hot != post.Hot(3, 1, created) {
+			t.Errorf("wrong result, expected hot %v, got %v", post.Hot(3, 1, created), hot)
+		}
+	})
+
 	mt.Run("update command error", func(mt *mtest.T) {
 		collection := mt.Coll
 		postRepo := post.MongoRepo{
@@ -992,3 +1030,492 @@ func TestPostDeleteComment(t *testing.T) {
 		}
 	})
 }
+
+func TestPostGetPage(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		firstID := primitive.NewObjectID()
+		secondID := primitive.NewObjectID()
+		expectedPosts := []*post.Post{
+			{
+				ID:             secondID.Hex(),
+				Category:       "music",
+				CreateDate:     "date",
+				Text:           "text",
+				Title:          "title",
+				Type:           "link",
+				Views:          0,
+				Votes:          make([]*post.Vote, 0),
+				CommentIDs:     make([]string, 0),
+				AuthorID:       1,
+				UpvotesCount:   0,
+				DownvotesCount: 0,
+			},
+		}
+		startCursor := mtest.CreateCursorResponse(1, "reddit.posts", mtest.FirstBatch, bson.D{
+			{"_id", secondID},
+			{"category", expectedPosts[0].Category},
+			{"create_date", expectedPosts[0].CreateDate},
+			{"text", expectedPosts[0].Text},
+			{"title", expectedPosts[0].Title},
+			{"type", expectedPosts[0].Type},
+			{"views", expectedPosts[0].Views},
+			{"votes", expectedPosts[0].Votes},
+			{"comment_ids", expectedPosts[0].CommentIDs},
+			{"author_id", expectedPosts[0].AuthorID},
+			{"upvotes_count", expectedPosts[0].UpvotesCount},
+			{"downvotes_count", expectedPosts[0].DownvotesCount},
+		}, bson.D{
+			{"_id", firstID},
+			{"category", "music"},
+		})
+		endCursor := mtest.CreateCursorResponse(0, "reddit.posts", mtest.NextBatch)
+		mt.AddMockResponses(startCursor, endCursor)
+
+		page, err := postRepo.GetPage(post.PageOptions{Limit: 1})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if !reflect.DeepEqual(page.Posts, expectedPosts) {
+			t.Errorf("wrong result, expected %#v, got %#v", expectedPosts, page.Posts)
+			return
+		}
+		if page.Next == "" {
+			t.Errorf("wrong result, expected next cursor")
+			return
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch))
+
+		page, err = postRepo.GetPage(post.PageOptions{Limit: 1, After: page.Next})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if len(page.Posts) != 0 || page.Next != "" {
+			t.Errorf("wrong result, expected empty last page, got %#v", page)
+		}
+	})
+
+	mt.Run("top", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		firstID := primitive.NewObjectID()
+		secondID := primitive.NewObjectID()
+		startCursor := mtest.CreateCursorResponse(1, "reddit.posts", mtest.FirstBatch, bson.D{
+			{"_id", firstID},
+			{"create_date", time.Now().Format(time.RFC3339)},
+			{"upvotes_count", 10},
+			{"downvotes_count", 1},
+		}, bson.D{
+			{"_id", secondID},
+			{"create_date", time.Now().Format(time.RFC3339)},
+			{"upvotes_count", 3},
+			{"downvotes_count", 0},
+		})
+		endCursor := mtest.CreateCursorResponse(0, "reddit.posts", mtest.NextBatch)
+		mt.AddMockResponses(startCursor, endCursor)
+
+		page, err := postRepo.GetPage(post.PageOptions{Limit: 1, Sort: post.SortTop, Period: post.PeriodWeek})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if len(page.Posts) != 1 || page.Posts[0].ID != firstID.Hex() || page.Next == "" {
+			t.Errorf("wrong result, expected top post %s and next cursor, got %#v", firstID.Hex(), page)
+		}
+
+		command := mt.GetStartedEvent().Command
+		sortKeys, err := command.Lookup("sort").Document().Elements()
+		if err != nil || len(sortKeys) != 2 || sortKeys[0].Key() != "score" || sortKeys[1].Key() != "_id" {
+			t.Errorf("wrong result, expected sort by stored score, got %v", command.Lookup("sort"))
+		}
+		if limit := command.Lookup("limit").AsInt64(); limit != 2 {
+			t.Errorf("wrong result, expected limit %d, got %d", 2, limit)
+		}
+	})
+
+	mt.Run("invalid cursor", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		_, err := postRepo.GetPageByCategory("music", post.PageOptions{Limit: 1, After: "notCursor"})
+		if err != post.ErrInvalidCursor {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrInvalidCursor, err)
+			return
+		}
+	})
+
+	mt.Run("find error", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+		expectedError := "command failed"
+
+		mt.AddMockResponses(bson.D{{"ok", 0}})
+
+		_, err := postRepo.GetPageByAuthor(1, post.PageOptions{Limit: 1})
+		if err.Error() != expectedError {
+			t.Errorf("wrong result, expected error %v, got %v", expectedError, err.Error())
+			return
+		}
+	})
+}
+
+func TestPostUpdate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("bad id", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		err := postRepo.Update("bad_id", 1, "title", "text", "")
+
+		if err != post.ErrInvalidID {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrInvalidID, err)
+			return
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch))
+		id := primitive.NewObjectID()
+
+		err := postRepo.Update(id.Hex(), 1, "title", "text", "")
+
+		if err != post.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrNotExist, err)
+			return
+		}
+	})
+
+	mt.Run("error access", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch, bson.D{
+			{"_id", id},
+			{"title", "title"},
+			{"text", "text"},
+			{"author_id", 2},
+		}))
+
+		err := postRepo.Update(id.Hex(), 1, "new title", "new text", "")
+
+		if err != post.ErrNoEditAccess {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrNoEditAccess, err)
+			return
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch, bson.D{
+			{"_id", id},
+			{"title", "title"},
+			{"text", "text"},
+			{"author_id", 1},
+		}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := postRepo.Update(id.Hex(), 1, "new title", "new text", "")
+
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+	})
+}
+
+func TestPostGetRevisions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		id := primitive.NewObjectID()
+		expected := []*post.Revision{
+			{
+				Title:      "title",
+				Text:       "text",
+				CreateDate: "date",
+			},
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch, bson.D{
+			{"_id", id},
+			{"revisions", bson.A{
+				bson.D{
+					{"title", "title"},
+					{"text", "text"},
+					{"create_date", "date"},
+				},
+			}},
+		}))
+
+		revisions, err := postRepo.GetRevisions(id.Hex())
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if !reflect.DeepEqual(revisions, expected) {
+			t.Errorf("wrong result, expected %#v, got %#v", expected, revisions)
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		collection := mt.Coll
+		postRepo := post.MongoRepo{
+			Posts: collection,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch))
+		id := primitive.NewObjectID()
+
+		_, err := postRepo.GetRevisions(id.Hex())
+		if err != post.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrNotExist, err)
+		}
+	})
+}
+
+func TestPostRemove(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("bad id", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		err := postRepo.Remove("bad_id")
+		if err != post.ErrInvalidID {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrInvalidID, err)
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+
+		err := postRepo.Remove(primitive.NewObjectID().Hex())
+		if err != post.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrNotExist, err)
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		err := postRepo.Remove(primitive.NewObjectID().Hex())
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+}
+
+func TestPostSetLocked(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("not found", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		err := postRepo.SetLocked(primitive.NewObjectID().Hex(), true)
+		if err != post.ErrNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", post.ErrNotExist, err)
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := postRepo.SetLocked(primitive.NewObjectID().Hex(), true)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+}
+
+func TestPostGetAuthorStats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "count", Value: 3},
+			{Key: "karma", Value: 7},
+		}))
+
+		stats, err := postRepo.GetAuthorStats(1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		expected := &post.AuthorStats{Count: 3, Karma: 7}
+		if !reflect.DeepEqual(stats, expected) {
+			t.Errorf("wrong result, expected %#v, got %#v", expected, stats)
+		}
+	})
+
+	mt.Run("no posts", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch))
+
+		stats, err := postRepo.GetAuthorStats(1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if stats.Count != 0 || stats.Karma != 0 {
+			t.Errorf("wrong result, expected empty stats, got %#v", stats)
+		}
+	})
+}
+
+func TestMemoryPostGetAuthorStats(t *testing.T) {
+	repo := post.NewMemoryRepo()
+
+	id, err := repo.Create(&post.Post{AuthorID: 1, Category: "music", Type: "text", Title: "title"})
+	if err != nil {
+		t.Fatalf("unable create post: %v", err)
+	}
+	_, err = repo.Create(&post.Post{AuthorID: 2, Category: "music", Type: "text", Title: "other"})
+	if err != nil {
+		t.Fatalf("unable create post: %v", err)
+	}
+	err = repo.Upvote(id, 2)
+	if err != nil {
+		t.Fatalf("unable upvote: %v", err)
+	}
+	err = repo.Downvote(id, 3)
+	if err != nil {
+		t.Fatalf("unable downvote: %v", err)
+	}
+	err = repo.Upvote(id, 4)
+	if err != nil {
+		t.Fatalf("unable upvote: %v", err)
+	}
+
+	stats, err := repo.GetAuthorStats(1)
+	if err != nil {
+		t.Fatalf("unable get stats: %v", err)
+	}
+	expected := &post.AuthorStats{Count: 1, Karma: 1}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, stats)
+	}
+}
+
+func TestPostGetByCommentIDs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		postID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.posts", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: postID},
+			{Key: "title", Value: "title"},
+			{Key: "comment_ids", Value: bson.A{"1", "2"}},
+		}))
+
+		posts, err := postRepo.GetByCommentIDs([]string{"2"})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if len(posts) != 1 || posts[0].ID != postID.Hex() || !reflect.DeepEqual(posts[0].CommentIDs, []string{"1", "2"}) {
+			t.Errorf("wrong result, got %#v", posts)
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		postRepo := post.MongoRepo{
+			Posts: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+
+		_, err := postRepo.GetByCommentIDs([]string{"2"})
+		if err == nil {
+			t.Errorf("wrong result, expected error")
+		}
+	})
+}
+
+func TestMemoryPostAnonymize(t *testing.T) {
+	repo := post.NewMemoryRepo()
+
+	id, err := repo.Create(&post.Post{AuthorID: 1, Category: "music", Type: "text", Title: "title"})
+	if err != nil {
+		t.Fatalf("unable create post: %v", err)
+	}
+
+	err = repo.Anonymize(1)
+	if err != nil {
+		t.Fatalf("unable anonymize posts: %v", err)
+	}
+	p, err := repo.GetByID(id, 0)
+	if err != nil || p.AuthorID != post.DeletedAuthorID {
+		t.Errorf("wrong post author, got %#v, error %v", p, err)
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/community"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"reflect"
+	"testing"
+)
+
+func TestReportAdd(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	target := community.Target{
+		Type:      community.TargetPost,
+		ID:        "1",
+		PostID:    "1",
+		Community: "golang",
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		reportRepo := community.MongoReportRepo{
+			Reports: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := reportRepo.Add(target, 2, "spam")
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+		}
+	})
+
+	mt.Run("already reported", func(mt *mtest.T) {
+		reportRepo := community.MongoReportRepo{
+			Reports: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+
+		err := reportRepo.Add(target, 2, "spam")
+		if err != community.ErrAlreadyReported {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrAlreadyReported, err)
+		}
+	})
+}
+
+func TestReportGetQueue(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		reportRepo := community.MongoReportRepo{
+			Reports: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.reports", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "comment:10"},
+			{Key: "type", Value: community.TargetComment},
+			{Key: "target_id", Value: "10"},
+			{Key: "post_id", Value: "1"},
+			{Key: "community", Value: "golang"},
+			{Key: "count", Value: 1},
+			{Key: "reports", Value: bson.A{
+				bson.D{
+					{Key: "user_id", Value: 2},
+					{Key: "reason", Value: "abuse"},
+					{Key: "create_date", Value: "2022-10-10T10:10:10Z"},
+				},
+			}},
+		}))
+
+		expected := []*community.ReportedItem{
+			{
+				Target: community.Target{
+					Type:      community.TargetComment,
+					ID:        "10",
+					PostID:    "1",
+					Community: "golang",
+				},
+				Count: 1,
+				Reports: []*community.Report{
+					{
+						UserID:     2,
+						Reason:     "abuse",
+						CreateDate: "2022-10-10T10:10:10Z",
+					},
+				},
+			},
+		}
+
+		items, err := reportRepo.GetQueue("golang")
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if !reflect.DeepEqual(items, expected) {
+			t.Errorf("wrong result, expected %#v, got %#v", expected, items)
+		}
+	})
+}
+
+func TestReportClear(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("not exist", func(mt *mtest.T) {
+		reportRepo := community.MongoReportRepo{
+			Reports: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+
+		err := reportRepo.Clear(community.TargetPost, "1")
+		if err != community.ErrReportNotExist {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrReportNotExist, err)
+		}
+	})
+}
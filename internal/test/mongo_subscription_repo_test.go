@@ -0,0 +1,69 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/community"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"reflect"
+	"testing"
+)
+
+func TestSubscriptionGetByUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("no subscriptions", func(mt *mtest.T) {
+		subscriptionRepo := community.MongoSubscriptionRepo{
+			Subscriptions: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.subscriptions", mtest.FirstBatch))
+
+		names, err := subscriptionRepo.GetByUser(1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if len(names) != 0 {
+			t.Errorf("wrong result, expected no subscriptions, got %v", names)
+		}
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		subscriptionRepo := community.MongoSubscriptionRepo{
+			Subscriptions: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.subscriptions", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: 1},
+			{Key: "communities", Value: bson.A{"golang", "news"}},
+		}))
+
+		names, err := subscriptionRepo.GetByUser(1)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if !reflect.DeepEqual(names, []string{"golang", "news"}) {
+			t.Errorf("wrong result, expected %v, got %v", []string{"golang", "news"}, names)
+		}
+	})
+}
+
+func TestSubscriptionUnsubscribe(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("not subscribed", func(mt *mtest.T) {
+		subscriptionRepo := community.MongoSubscriptionRepo{
+			Subscriptions: mt.Coll,
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		err := subscriptionRepo.Unsubscribe(1, "golang")
+		if err != community.ErrNotSubscribed {
+			t.Errorf("wrong result, expected error %v, got %v", community.ErrNotSubscribed, err)
+		}
+	})
+}
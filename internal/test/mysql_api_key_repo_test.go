@@ -0,0 +1,212 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/session"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyAddCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.
+		ExpectQuery("SELECT COUNT\\(\\*\\) FROM api_keys WHERE user_id = ?").
+		WithArgs(uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.
+		ExpectExec("INSERT INTO api_keys").
+		WithArgs(sqlmock.AnyArg(), uint(1), "username", "bot", "read,vote", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+
+	repo := session.NewMySQLAPIKeyRepo(db)
+	key, token, err := repo.Add(1, "username", "bot", []session.Scope{session.ScopeRead, session.ScopeVote})
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if key.ID != 7 || key.Name != "bot" {
+		t.Errorf("wrong key: %+v", key)
+	}
+	if !strings.HasPrefix(token, session.APIKeyPrefix) {
+		t.Errorf("wrong token format: %s", token)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestAPIKeyAddTooMany(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.
+		ExpectQuery("SELECT COUNT\\(\\*\\) FROM api_keys WHERE user_id = ?").
+		WithArgs(uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(session.MaxAPIKeys))
+
+	repo := session.NewMySQLAPIKeyRepo(db)
+	_, _, err = repo.Add(1, "username", "bot", []session.Scope{session.ScopeRead})
+	if err != session.ErrTooManyAPIKeys {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrTooManyAPIKeys)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	columns := []string{"id", "user_id", "username", "name", "scopes", "create_date", "last_used"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(3, 1, "username", "bot", "read,comment", "2022-10-10T10:10:10Z", "2022-10-10T10:10:10Z")
+	mock.
+		ExpectQuery("SELECT id, user_id, username, name, scopes, create_date, last_used FROM api_keys WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	mock.
+		ExpectExec("UPDATE api_keys SET last_used = \\? WHERE id = \\?").
+		WithArgs(sqlmock.AnyArg(), uint(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// a key used moments ago isn't written again
+	recent := time.Now().Add(-time.Second).Format(time.RFC3339)
+	mock.
+		ExpectQuery("SELECT id, user_id, username, name, scopes, create_date, last_used FROM api_keys WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 1, "username", "bot", "read", "2022-10-10T10:10:10Z", recent))
+	mock.
+		ExpectQuery("SELECT id, user_id, username, name, scopes, create_date, last_used FROM api_keys WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	repo := session.NewMySQLAPIKeyRepo(db)
+	key, err := repo.Authenticate(session.APIKeyPrefix + "secret")
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	expected := []session.Scope{session.ScopeRead, session.ScopeComment}
+	if key.UserID != 1 || !reflect.DeepEqual(key.Scopes, expected) || key.LastUsed == "2022-10-10T10:10:10Z" {
+		t.Errorf("wrong key: %+v", key)
+	}
+
+	key, err = repo.Authenticate(session.APIKeyPrefix + "secret")
+	if err != nil || key.LastUsed != recent {
+		t.Errorf("wrong result, got key %+v, error %v", key, err)
+	}
+
+	_, err = repo.Authenticate(session.APIKeyPrefix + "unknown")
+	if err != session.ErrBadToken {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrBadToken)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestAPIKeyDeleteNotOwned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.
+		ExpectExec("DELETE FROM api_keys WHERE id = \\? AND user_id = \\?").
+		WithArgs(uint(3), uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := session.NewMySQLAPIKeyRepo(db)
+	err = repo.Delete(2, 3)
+	if err != session.ErrNoAPIKey {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrNoAPIKey)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := session.ParseScopes([]string{"read", "vote", "read"})
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	if !reflect.DeepEqual(scopes, []session.Scope{session.ScopeRead, session.ScopeVote}) {
+		t.Errorf("wrong scopes: %v", scopes)
+	}
+
+	if _, err = session.ParseScopes([]string{"admin"}); err != session.ErrBadScope {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrBadScope)
+	}
+	if _, err = session.ParseScopes(nil); err != session.ErrNoScopes {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrNoScopes)
+	}
+}
+
+func TestManagerCreateFromAPIKey(t *testing.T) {
+	keys := session.NewMemoryAPIKeyRepo()
+	manager := session.NewManager(nil, nil, keys, nil)
+
+	key, token, err := keys.Add(1, "username", "bot", []session.Scope{session.ScopeVote})
+	if err != nil {
+		t.Fatalf("unable add key: %v", err)
+	}
+
+	sess, err := manager.Create("Bearer " + token)
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	if sess.UserID != 1 || sess.Username != "username" || sess.APIKeyID != key.ID {
+		t.Errorf("wrong session: %+v", sess)
+	}
+	if !sess.Allows(session.ScopeVote) || sess.Allows(session.ScopePost) {
+		t.Errorf("wrong scopes: %v", sess.Scopes)
+	}
+
+	all, err := keys.GetAll(1)
+	if err != nil || len(all) != 1 || all[0].LastUsed == "" {
+		t.Errorf("last use wasn`t recorded: %+v", all)
+	}
+
+	if _, err = manager.Create("Bearer " + session.APIKeyPrefix + "unknown"); err != session.ErrBadToken {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrBadToken)
+	}
+}
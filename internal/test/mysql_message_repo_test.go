@@ -0,0 +1,195 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/message"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+var messageColumns = []string{"id", "thread_id", "sender_id", "recipient_id", "subject", "body", "create_date", "is_read"}
+
+func TestMessageCreateCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	m := &message.Message{
+		ThreadID:    3,
+		SenderID:    1,
+		RecipientID: 2,
+		Subject:     "hello",
+		Body:        "body",
+	}
+	mock.
+		ExpectExec("INSERT INTO messages").
+		WithArgs(m.ThreadID, m.SenderID, m.RecipientID, m.Subject, m.Body, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+
+	repo := &message.MySQLRepo{
+		DB: db,
+	}
+	id, err := repo.Create(m)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+		return
+	}
+	if id != 4 || m.ID != 4 || m.CreateDate == "" {
+		t.Errorf("wrong result, expected message with id 4 and create date, got %#v", m)
+	}
+}
+
+func TestMessageGetThreadCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	rows := sqlmock.
+		NewRows(messageColumns).
+		AddRow(3, 0, 1, 2, "hello", "first", "2022-10-10T10:10:10Z", true).
+		AddRow(4, 3, 2, 1, "hello", "second", "2022-10-10T10:11:10Z", false)
+	mock.
+		ExpectQuery(regexp.QuoteMeta("SELECT id, thread_id, sender_id, recipient_id, subject, body, create_date, is_read FROM messages WHERE (id = ? OR thread_id = ?)")).
+		WithArgs(3, 3, 1, 1).
+		WillReturnRows(rows)
+
+	repo := &message.MySQLRepo{
+		DB: db,
+	}
+	messages, err := repo.GetThread(3, 1)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+		return
+	}
+
+	expected := []*message.Message{
+		{ID: 3, SenderID: 1, RecipientID: 2, Subject: "hello", Body: "first", CreateDate: "2022-10-10T10:10:10Z", Read: true},
+		{ID: 4, ThreadID: 3, SenderID: 2, RecipientID: 1, Subject: "hello", Body: "second", CreateDate: "2022-10-10T10:11:10Z"},
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, messages)
+	}
+}
+
+func TestMessageGetThreadNotExist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM messages WHERE").
+		WithArgs(3, 3, 5, 5).
+		WillReturnRows(sqlmock.NewRows(messageColumns))
+
+	repo := &message.MySQLRepo{
+		DB: db,
+	}
+	_, err = repo.GetThread(3, 5)
+	if err != message.ErrNotExist {
+		t.Errorf("wrong result, expected error %v, got %v", message.ErrNotExist, err)
+	}
+}
+
+func TestMessageMarkReadNoAccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	rows := sqlmock.
+		NewRows(messageColumns).
+		AddRow(3, 0, 1, 2, "hello", "first", "2022-10-10T10:10:10Z", false)
+	mock.
+		ExpectQuery("SELECT (.+) FROM messages WHERE id = ?").
+		WithArgs(3, 1, 1).
+		WillReturnRows(rows)
+
+	repo := &message.MySQLRepo{
+		DB: db,
+	}
+	err = repo.MarkRead(3, 1)
+	if err != message.ErrNoAccess {
+		t.Errorf("wrong result, expected error %v, got %v", message.ErrNoAccess, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestMessageDeleteCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	rows := sqlmock.
+		NewRows(messageColumns).
+		AddRow(3, 0, 1, 2, "hello", "first", "2022-10-10T10:10:10Z", false)
+	mock.
+		ExpectQuery("SELECT (.+) FROM messages WHERE id = ?").
+		WithArgs(3, 2, 2).
+		WillReturnRows(rows)
+	mock.
+		ExpectExec("UPDATE messages SET deleted_by_sender").
+		WithArgs(2, 2, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := &message.MySQLRepo{
+		DB: db,
+	}
+	err = repo.Delete(3, 2)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
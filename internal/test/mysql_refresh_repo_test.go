@@ -0,0 +1,153 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/session"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestRefreshRotateCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	rows := sqlmock.
+		NewRows([]string{"family_id", "user_id", "username", "expiration_date", "used"}).
+		AddRow("family", 1, "username", exp, false)
+
+	mock.ExpectBegin()
+	mock.
+		ExpectQuery("SELECT family_id, user_id, username, expiration_date, used FROM refresh_tokens WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	mock.
+		ExpectExec("UPDATE refresh_tokens SET used = 1 WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.
+		ExpectExec("INSERT INTO refresh_tokens").
+		WithArgs(sqlmock.AnyArg(), "family", uint(1), "username", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := session.NewMySQLRefreshRepo(db, time.Hour)
+	rt, token, err := repo.Rotate("refresh")
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+		return
+	}
+	if token == "" || token == "refresh" || rt.FamilyID != "family" || rt.UserID != 1 {
+		t.Errorf("wrong result, got token %q for %#v", token, rt)
+	}
+}
+
+func TestRefreshRotateReused(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	rows := sqlmock.
+		NewRows([]string{"family_id", "user_id", "username", "expiration_date", "used"}).
+		AddRow("family", 1, "username", exp, true)
+
+	mock.ExpectBegin()
+	mock.
+		ExpectQuery("SELECT family_id, user_id, username, expiration_date, used FROM refresh_tokens WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	mock.
+		ExpectExec("DELETE FROM refresh_tokens WHERE family_id = ?").
+		WithArgs("family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	repo := session.NewMySQLRefreshRepo(db, time.Hour)
+	_, _, err = repo.Rotate("refresh")
+	if err != session.ErrTokenReused {
+		t.Errorf("expected error %v, got error %v", session.ErrTokenReused, err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestRefreshRotateNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.ExpectBegin()
+	mock.
+		ExpectQuery("SELECT family_id, user_id, username, expiration_date, used FROM refresh_tokens WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "user_id", "username", "expiration_date", "used"}))
+	mock.ExpectRollback()
+
+	repo := session.NewMySQLRefreshRepo(db, time.Hour)
+	_, _, err = repo.Rotate("refresh")
+	if err != session.ErrBadToken {
+		t.Errorf("expected error %v, got error %v", session.ErrBadToken, err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestMemoryRefreshReuseRevokesFamily(t *testing.T) {
+	repo := session.NewMemoryRefreshRepo(time.Hour)
+
+	first, err := repo.Add("username", 1)
+	if err != nil {
+		t.Fatalf("unable add refresh token: %v", err)
+	}
+
+	_, second, err := repo.Rotate(first)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+
+	_, _, err = repo.Rotate(first)
+	if err != session.ErrTokenReused {
+		t.Errorf("expected error %v, got error %v", session.ErrTokenReused, err)
+		return
+	}
+
+	_, _, err = repo.Rotate(second)
+	if err != session.ErrBadToken {
+		t.Errorf("expected error %v, got error %v", session.ErrBadToken, err)
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/user"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestResetAddCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.
+		ExpectExec("DELETE FROM password_resets WHERE user_id = ?").
+		WithArgs(uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.
+		ExpectExec("INSERT INTO password_resets").
+		WithArgs(sqlmock.AnyArg(), uint(1), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := user.NewMySQLResetRepo(db, time.Hour)
+	token, err := repo.Add(1)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if token == "" {
+		t.Errorf("wrong result, got empty token")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestResetConsume(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := user.NewMySQLResetRepo(db, time.Hour)
+
+	rows := sqlmock.NewRows([]string{"user_id", "expiration_date"}).
+		AddRow(1, time.Now().Add(time.Hour).Unix())
+	mock.ExpectBegin()
+	mock.
+		ExpectQuery("SELECT user_id, expiration_date FROM password_resets WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	mock.
+		ExpectExec("DELETE FROM password_resets WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	userID, err := repo.Consume("token")
+	if err != nil || userID != 1 {
+		t.Errorf("wrong result, got user %d, error %v", userID, err)
+		return
+	}
+
+	// expired tokens are dropped as well
+	rows = sqlmock.NewRows([]string{"user_id", "expiration_date"}).
+		AddRow(1, time.Now().Add(-time.Hour).Unix())
+	mock.ExpectBegin()
+	mock.
+		ExpectQuery("SELECT user_id, expiration_date FROM password_resets WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	mock.
+		ExpectExec("DELETE FROM password_resets WHERE token_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err = repo.Consume("token")
+	if err != user.ErrBadResetToken {
+		t.Errorf("expected error %v, got error %v", user.ErrBadResetToken, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestMemoryResetRepo(t *testing.T) {
+	repo := user.NewMemoryResetRepo(time.Hour)
+
+	first, err := repo.Add(1)
+	if err != nil {
+		t.Fatalf("unable add token: %v", err)
+	}
+	second, err := repo.Add(1)
+	if err != nil {
+		t.Fatalf("unable add token: %v", err)
+	}
+
+	if _, err = repo.Consume(first); err != user.ErrBadResetToken {
+		t.Errorf("expected replaced token to fail, got error %v", err)
+	}
+	userID, err := repo.Consume(second)
+	if err != nil || userID != 1 {
+		t.Errorf("wrong result, got user %d, error %v", userID, err)
+	}
+	if _, err = repo.Consume(second); err != user.ErrBadResetToken {
+		t.Errorf("expected used token to fail, got error %v", err)
+	}
+}
@@ -0,0 +1,372 @@
+package test
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"github.com/vlasdash/redditclone/internal/session"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sessionTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionColumn accepts any value that fits the char(64) key of the sessions
+// table and remembers it.
+type sessionColumn struct {
+	value string
+}
+
+func (c *sessionColumn) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok || len(s) > 64 {
+		return false
+	}
+	c.value = s
+
+	return true
+}
+
+func TestSessionAddGetRoundTrip(t *testing.T) {
+	edKey, err := session.GenerateSigningKey("ed")
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	rsaKey := newTestRSAKey(t, "rsa", time.Time{})
+
+	for _, signingKey := range []*session.SigningKey{edKey, rsaKey} {
+		keys, err := session.NewKeySet([]*session.SigningKey{signingKey}, 0)
+		if err != nil {
+			t.Fatalf("unable create key set: %v", err)
+		}
+		testSessionRoundTrip(t, keys)
+	}
+}
+
+func testSessionRoundTrip(t *testing.T, keys *session.KeySet) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := session.NewMySQLRepo(db, session.NewJWTGenerator(keys, 0))
+	var userID uint = 1
+	key := &sessionColumn{}
+	mock.
+		ExpectExec("INSERT INTO sessions").
+		WithArgs(key, "username", userID, sqlmock.AnyArg(), sqlmock.AnyArg(), "firefox", "127.0.0.1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	token, err := repo.Add("username", userID, session.Client{UserAgent: "firefox", IP: "127.0.0.1"})
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if key.value != sessionTokenHash(token) {
+		t.Errorf("wrong result, expected stored hash %s, got %s", sessionTokenHash(token), key.value)
+		return
+	}
+
+	exp := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	rows := sqlmock.
+		NewRows([]string{"username", "user_id", "expiration_date", "create_date", "user_agent", "ip"}).
+		AddRow("username", userID, exp, "2022-10-10T10:10:10Z", "firefox", "127.0.0.1")
+	mock.
+		ExpectQuery("SELECT username, user_id, expiration_date, create_date, user_agent, ip FROM sessions WHERE token_hash = ?").
+		WithArgs(key.value).
+		WillReturnRows(rows)
+
+	sess, err := repo.Get("Bearer " + token)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+		return
+	}
+	if sess.UserID != userID || sess.Token != key.value {
+		t.Errorf("wrong result, expected session of user %d with token hash, got %#v", userID, sess)
+	}
+}
+
+func TestSessionGetAllCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	var userID uint = 1
+	active := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	expired := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	rows := sqlmock.
+		NewRows([]string{"token_hash", "username", "user_id", "expiration_date", "create_date", "user_agent", "ip"}).
+		AddRow("first", "username", userID, active, "2022-10-10T10:10:10Z", "firefox", "127.0.0.1").
+		AddRow("second", "username", userID, expired, "2022-10-09T10:10:10Z", "curl", "127.0.0.1")
+
+	mock.
+		ExpectQuery("SELECT token_hash, username, user_id, expiration_date, create_date, user_agent, ip FROM sessions WHERE user_id = ?").
+		WithArgs(userID).
+		WillReturnRows(rows)
+
+	repo := &session.MySQLRepo{
+		DB: db,
+	}
+	sessions, err := repo.GetAll(userID)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+		return
+	}
+	if len(sessions) != 1 || sessions[0].Token != "first" || sessions[0].UserAgent != "firefox" {
+		t.Errorf("wrong result, expected only active session, got %#v", sessions)
+	}
+}
+
+func TestSessionDeleteCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.
+		ExpectExec("DELETE FROM sessions WHERE token_hash = ?").
+		WithArgs(sessionTokenHash("token")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := &session.MySQLRepo{
+		DB: db,
+	}
+	err = repo.Delete("Bearer token")
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestSessionDeleteError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := &session.MySQLRepo{
+		DB: db,
+	}
+	err = repo.Delete("token")
+	if err != session.ErrBadToken {
+		t.Errorf("expected error %v, got error %v", session.ErrBadToken, err)
+		return
+	}
+
+	mock.
+		ExpectExec("DELETE FROM sessions WHERE token_hash = ?").
+		WithArgs(sessionTokenHash("token")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Delete("Bearer token")
+	if err != session.ErrBadToken {
+		t.Errorf("expected error %v, got error %v", session.ErrBadToken, err)
+		return
+	}
+
+	mock.
+		ExpectExec("DELETE FROM sessions WHERE token_hash = ?").
+		WithArgs(sessionTokenHash("token")).
+		WillReturnError(fmt.Errorf("something went wrong"))
+
+	err = repo.Delete("Bearer token")
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestSessionDeleteAllCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	var userID uint = 1
+	mock.
+		ExpectExec("DELETE FROM sessions WHERE user_id = ?").
+		WithArgs(userID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	repo := &session.MySQLRepo{
+		DB: db,
+	}
+	err = repo.DeleteAll(userID)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestJWTSessionRevoke(t *testing.T) {
+	keys := newTestKeySet(t)
+	repo := session.NewJWTRepo(session.NewJWTGenerator(keys, 0), keys)
+
+	var userID uint = 1
+	first, err := repo.Add("username", userID, session.Client{UserAgent: "firefox"})
+	if err != nil {
+		t.Fatalf("unable add session: %v", err)
+	}
+	second, err := repo.Add("username", userID, session.Client{UserAgent: "curl"})
+	if err != nil {
+		t.Fatalf("unable add session: %v", err)
+	}
+
+	sessions, err := repo.GetAll(userID)
+	if err != nil || len(sessions) != 2 {
+		t.Errorf("wrong result, expected 2 sessions, got %#v, error %v", sessions, err)
+		return
+	}
+
+	err = repo.Delete("Bearer " + first)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if _, err = repo.Get("Bearer " + first); err != session.ErrTokenRevoked {
+		t.Errorf("expected error %v, got error %v", session.ErrTokenRevoked, err)
+		return
+	}
+	if _, err = repo.Get("Bearer " + second); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+
+	err = repo.DeleteAll(userID)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if _, err = repo.Get("Bearer " + second); err != session.ErrTokenRevoked {
+		t.Errorf("expected error %v, got error %v", session.ErrTokenRevoked, err)
+		return
+	}
+	sessions, err = repo.GetAll(userID)
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("wrong result, expected no sessions, got %#v, error %v", sessions, err)
+	}
+}
+
+func TestSessionDeleteOthersCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	var userID uint = 1
+	mock.
+		ExpectExec("DELETE FROM sessions WHERE user_id = \\? AND token_hash <> \\?").
+		WithArgs(userID, "token").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	repo := &session.MySQLRepo{
+		DB: db,
+	}
+	err = repo.DeleteOthers(userID, "token")
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestJWTSessionDeleteOthers(t *testing.T) {
+	keys := newTestKeySet(t)
+	repo := session.NewJWTRepo(session.NewJWTGenerator(keys, 0), keys)
+
+	var userID uint = 1
+	current, err := repo.Add("username", userID, session.Client{UserAgent: "firefox"})
+	if err != nil {
+		t.Fatalf("unable add session: %v", err)
+	}
+	other, err := repo.Add("username", userID, session.Client{UserAgent: "curl"})
+	if err != nil {
+		t.Fatalf("unable add session: %v", err)
+	}
+
+	err = repo.DeleteOthers(userID, current)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if _, err = repo.Get("Bearer " + current); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if _, err = repo.Get("Bearer " + other); err != session.ErrTokenRevoked {
+		t.Errorf("expected error %v, got error %v", session.ErrTokenRevoked, err)
+		return
+	}
+	sessions, err := repo.GetAll(userID)
+	if err != nil || len(sessions) != 1 {
+		t.Errorf("wrong result, expected 1 session, got %#v, error %v", sessions, err)
+	}
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/twofactor"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTwoFactorGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := twofactor.NewMySQLTwoFactorRepo(db)
+
+	mock.
+		ExpectQuery("SELECT secret, enabled, last_step FROM two_factor WHERE").
+		WithArgs(uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"secret", "enabled", "last_step"}).AddRow("secret", true, 10))
+	mock.
+		ExpectQuery("SELECT code_hash FROM recovery_codes WHERE").
+		WithArgs(uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"code_hash"}).AddRow("first").AddRow("second"))
+
+	s, err := repo.Get(1)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	expected := &twofactor.Settings{
+		UserID:        1,
+		Secret:        "secret",
+		Enabled:       true,
+		RecoveryCodes: []string{"first", "second"},
+		LastStep:      10,
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("wrong result: got %+v, expected %+v", s, expected)
+	}
+
+	mock.
+		ExpectQuery("SELECT secret, enabled, last_step FROM two_factor WHERE").
+		WithArgs(uint(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"secret", "enabled", "last_step"}))
+
+	_, err = repo.Get(2)
+	if err != twofactor.ErrNotEnrolled {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrNotEnrolled)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestTwoFactorSave(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := twofactor.NewMySQLTwoFactorRepo(db)
+
+	mock.ExpectBegin()
+	mock.
+		ExpectExec("INSERT INTO two_factor").
+		WithArgs(uint(1), "secret", true, int64(10)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.
+		ExpectExec("DELETE FROM recovery_codes WHERE user_id = ?").
+		WithArgs(uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.
+		ExpectExec("INSERT INTO recovery_codes").
+		WithArgs(uint(1), "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.Save(&twofactor.Settings{
+		UserID:        1,
+		Secret:        "secret",
+		Enabled:       true,
+		RecoveryCodes: []string{"hash"},
+		LastStep:      10,
+	})
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestTwoFactorUseStepReplay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := twofactor.NewMySQLTwoFactorRepo(db)
+
+	mock.
+		ExpectExec("UPDATE two_factor SET last_step").
+		WithArgs(int64(11), uint(1), int64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.
+		ExpectExec("UPDATE two_factor SET last_step").
+		WithArgs(int64(11), uint(1), int64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err = repo.UseStep(1, 11); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	if err = repo.UseStep(1, 11); err != twofactor.ErrInvalidCode {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrInvalidCode)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestTwoFactorUseRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := twofactor.NewMySQLTwoFactorRepo(db)
+
+	mock.
+		ExpectExec("DELETE FROM recovery_codes WHERE user_id = \\? AND code_hash = \\?").
+		WithArgs(uint(1), twofactor.HashCode("aaaaa-bbbbb")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.UseRecoveryCode(1, "aaaaa-bbbbb")
+	if err != twofactor.ErrInvalidCode {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrInvalidCode)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestChallengeGetExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := twofactor.NewMySQLChallengeRepo(db, time.Minute)
+
+	rows := sqlmock.NewRows([]string{"user_id", "username", "expiration_date", "attempts"}).
+		AddRow(1, "username", time.Now().Add(-time.Minute).Unix(), 0)
+	mock.
+		ExpectQuery("SELECT user_id, username, expiration_date, attempts FROM login_challenges WHERE").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	_, err = repo.Get("token")
+	if err != twofactor.ErrBadChallenge {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrBadChallenge)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
@@ -3,9 +3,11 @@ package test
 import (
 	"database/sql"
 	"fmt"
+	"github.com/vlasdash/redditclone/internal/session"
 	"github.com/vlasdash/redditclone/internal/user"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -23,16 +25,16 @@ func TestUserGetByIDCorrect(t *testing.T) {
 	}()
 
 	var userID uint = 1
-	rows := sqlmock.NewRows([]string{"id", "username", "password"})
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "email", "role", "banned"})
 	expected := []*user.User{
-		{userID, "username", "password"},
+		{userID, "username", "password", "user@example.com", user.RoleUser, false},
 	}
 	for _, u := range expected {
-		rows = rows.AddRow(u.ID, u.Username, u.Password)
+		rows = rows.AddRow(u.ID, u.Username, u.Password, u.Email, u.Role, u.Banned)
 	}
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE id = ?").
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE id = ?").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -68,7 +70,7 @@ func TestUserGetByIDError(t *testing.T) {
 
 	var userID uint = 1
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE id = ?").
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE id = ?").
 		WithArgs(userID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -95,7 +97,7 @@ func TestUserGetByIDError(t *testing.T) {
 		AddRow(1, "username")
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE id = ?").
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE id = ?").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -123,16 +125,16 @@ func TestUserGetByUsernameCorrect(t *testing.T) {
 	}()
 
 	username := "username"
-	rows := sqlmock.NewRows([]string{"id", "username", "password"})
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "email", "role", "banned"})
 	expected := []*user.User{
-		{1, username, "password"},
+		{1, username, "password", "", user.RoleAdmin, false},
 	}
 	for _, u := range expected {
-		rows = rows.AddRow(u.ID, u.Username, u.Password)
+		rows = rows.AddRow(u.ID, u.Username, u.Password, u.Email, u.Role, u.Banned)
 	}
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = ?").
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE username = ?").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -168,7 +170,7 @@ func TestUserGetByUsernameError(t *testing.T) {
 
 	username := "username"
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = ?").
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE username = ?").
 		WithArgs(username).
 		WillReturnError(sql.ErrNoRows)
 
@@ -195,7 +197,7 @@ func TestUserGetByUsernameError(t *testing.T) {
 		AddRow(1, "username")
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = ?").
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE username = ?").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -231,7 +233,7 @@ func TestUserCreateCorrect(t *testing.T) {
 	var expectedID uint = 1
 	mock.
 		ExpectExec("INSERT INTO users").
-		WithArgs(username, password).
+		WithArgs(username, password, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	id, err := repo.Create(username, password)
@@ -268,7 +270,7 @@ func TestUserCreateError(t *testing.T) {
 	password := "password"
 	mock.
 		ExpectExec("INSERT INTO users").
-		WithArgs(username, password).
+		WithArgs(username, password, sqlmock.AnyArg()).
 		WillReturnError(fmt.Errorf("something went wrong"))
 
 	_, err = repo.Create(username, password)
@@ -283,7 +285,7 @@ func TestUserCreateError(t *testing.T) {
 
 	mock.
 		ExpectExec("INSERT INTO users").
-		WithArgs(username, password).
+		WithArgs(username, password, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("bad insertion")))
 
 	_, err = repo.Create(username, password)
@@ -296,3 +298,449 @@ func TestUserCreateError(t *testing.T) {
 		return
 	}
 }
+
+func TestUserGetProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+	expected := &user.Profile{
+		UserID:     1,
+		Bio:        "bio",
+		AvatarURL:  "https://example.com/a.png",
+		CreateDate: "2022-10-10T10:10:10Z",
+	}
+	rows := sqlmock.NewRows([]string{"id", "bio", "avatar_url", "create_date"}).
+		AddRow(expected.UserID, expected.Bio, expected.AvatarURL, expected.CreateDate)
+	mock.
+		ExpectQuery("SELECT id, bio, avatar_url, create_date FROM users WHERE id = ?").
+		WithArgs(expected.UserID).
+		WillReturnRows(rows)
+
+	profile, err := repo.GetProfile(expected.UserID)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if !reflect.DeepEqual(profile, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, profile)
+	}
+
+	mock.
+		ExpectQuery("SELECT id, bio, avatar_url, create_date FROM users WHERE id = ?").
+		WithArgs(uint(2)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetProfile(2)
+	if err != user.ErrNoExist {
+		t.Errorf("wrong result, expected error %v, got %v", user.ErrNoExist, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestUserUpdateProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+	profile := &user.Profile{
+		UserID: 1,
+		Bio:    "bio",
+	}
+
+	mock.
+		ExpectExec("UPDATE users SET").
+		WithArgs(profile.Bio, profile.AvatarURL, profile.UserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateProfile(profile)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+
+	// nothing changed, the user has to exist anyway
+	mock.
+		ExpectExec("UPDATE users SET").
+		WithArgs(profile.Bio, profile.AvatarURL, profile.UserID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE id = ?").
+		WithArgs(profile.UserID).
+		WillReturnError(sql.ErrNoRows)
+
+	err = repo.UpdateProfile(profile)
+	if err != user.ErrNoExist {
+		t.Errorf("wrong result, expected error %v, got %v", user.ErrNoExist, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	cases := []struct {
+		profile  *user.Profile
+		expected error
+	}{
+		{profile: &user.Profile{}, expected: nil},
+		{profile: &user.Profile{Bio: "hi", AvatarURL: "https://example.com/a.png"}, expected: nil},
+		{profile: &user.Profile{Bio: strings.Repeat("я", user.MaxBioLength)}, expected: nil},
+		{profile: &user.Profile{Bio: strings.Repeat("я", user.MaxBioLength+1)}, expected: user.ErrInvalidBio},
+		{profile: &user.Profile{AvatarURL: "javascript:alert(1)"}, expected: user.ErrInvalidAvatarURL},
+		{profile: &user.Profile{AvatarURL: "/static/a.png"}, expected: user.ErrInvalidAvatarURL},
+	}
+
+	for _, c := range cases {
+		if err := c.profile.Validate(); err != c.expected {
+			t.Errorf("wrong result for %#v, expected %v, got %v", c.profile, c.expected, err)
+		}
+	}
+}
+
+func TestUserUpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+
+	mock.
+		ExpectExec("UPDATE users SET").
+		WithArgs("hash", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err = repo.UpdatePassword(1, "hash")
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+
+	mock.
+		ExpectExec("UPDATE users SET").
+		WithArgs("hash", 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.UpdatePassword(2, "hash")
+	if err != user.ErrNoExist {
+		t.Errorf("wrong result, expected error %v, got %v", user.ErrNoExist, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestUserDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+
+	mock.
+		ExpectExec("DELETE FROM users WHERE id = ?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err = repo.Delete(1)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+
+	mock.
+		ExpectExec("DELETE FROM users WHERE id = ?").
+		WithArgs(1).
+		WillReturnError(fmt.Errorf("db error"))
+	err = repo.Delete(1)
+	if err == nil {
+		t.Errorf("wrong result, expected error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestUserGetByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "email", "role", "banned"}).
+		AddRow(1, "username", "password", "user@example.com", "user", true)
+	mock.
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE email = ?").
+		WithArgs("user@example.com").
+		WillReturnRows(rows)
+
+	u, err := repo.GetByEmail("user@example.com")
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	expected := &user.User{ID: 1, Username: "username", Password: "password", Email: "user@example.com", Role: user.RoleUser, Banned: true}
+	if !reflect.DeepEqual(u, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, u)
+	}
+
+	mock.
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE email = ?").
+		WithArgs("other@example.com").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetByEmail("other@example.com")
+	if err != user.ErrNoExist {
+		t.Errorf("wrong result, expected error %v, got %v", user.ErrNoExist, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestUserUpdateEmailTaken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "email", "role", "banned"}).
+		AddRow(2, "other", "password", "user@example.com", "user", false)
+	mock.
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users WHERE email = ?").
+		WithArgs("user@example.com").
+		WillReturnRows(rows)
+
+	err = repo.UpdateEmail(1, "user@example.com")
+	if err != user.ErrEmailTaken {
+		t.Errorf("wrong result, expected error %v, got %v", user.ErrEmailTaken, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	cases := []struct {
+		email    string
+		expected error
+	}{
+		{email: "", expected: nil},
+		{email: "user@example.com", expected: nil},
+		{email: "user", expected: user.ErrInvalidEmail},
+		{email: "User <user@example.com>", expected: user.ErrInvalidEmail},
+		{email: strings.Repeat("a", user.MaxEmailLength) + "@example.com", expected: user.ErrInvalidEmail},
+	}
+
+	for _, c := range cases {
+		if err := user.ValidateEmail(c.email); err != c.expected {
+			t.Errorf("wrong result for %q, expected %v, got %v", c.email, c.expected, err)
+		}
+	}
+}
+
+func TestUserGetAllCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "email", "role", "banned"}).
+		AddRow(1, "admin", "password", "", "admin", false).
+		AddRow(2, "spammer", "password", "", "user", true)
+	mock.
+		ExpectQuery("SELECT id, username, password, email, role, banned FROM users ORDER BY id").
+		WillReturnRows(rows)
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+	users, err := repo.GetAll()
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	expected := []*user.User{
+		{ID: 1, Username: "admin", Password: "password", Role: user.RoleAdmin},
+		{ID: 2, Username: "spammer", Password: "password", Role: user.RoleUser, Banned: true},
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, users)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestUserSetBannedCorrect(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	mock.
+		ExpectExec("UPDATE users SET `banned` = \\? WHERE id = \\?").
+		WithArgs(true, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.
+		ExpectExec("UPDATE users SET `role` = \\? WHERE id = \\?").
+		WithArgs(user.RoleModerator, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := &user.MySQLRepo{
+		DB: db,
+	}
+	if err = repo.SetBanned(2, true); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err = repo.SetRole(2, user.RoleModerator); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	cases := []struct {
+		role   user.Role
+		remove bool
+		users  bool
+	}{
+		{role: user.RoleUser},
+		{role: user.RoleModerator, remove: true},
+		{role: user.RoleAdmin, remove: true, users: true},
+		{role: ""},
+	}
+
+	for _, c := range cases {
+		if c.role.Can(user.PermRemoveContent) != c.remove || c.role.Can(user.PermManageUsers) != c.users {
+			t.Errorf("wrong permissions for role %q", c.role)
+		}
+	}
+	if _, err := user.ParseRole("owner"); err != user.ErrInvalidRole {
+		t.Errorf("expected error %v, got %v", user.ErrInvalidRole, err)
+	}
+}
+
+func TestEnsureAdmins(t *testing.T) {
+	userRepo := user.NewMemoryRepo()
+	id, err := userRepo.Create("root", "password")
+	if err != nil {
+		t.Fatalf("unable create user: %v", err)
+	}
+
+	missing, err := user.EnsureAdmins(userRepo, []string{"root", "later"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"later"}) {
+		t.Errorf("wrong missing admins: %v", missing)
+	}
+	u, err := userRepo.GetByID(id)
+	if err != nil || u.Role != user.RoleAdmin {
+		t.Errorf("expected admin role, got %v, error %v", u, err)
+	}
+}
+
+func TestManagerBannedUser(t *testing.T) {
+	userRepo := user.NewMemoryRepo()
+	id, err := userRepo.Create("username", "password")
+	if err != nil {
+		t.Fatalf("unable create user: %v", err)
+	}
+	manager := session.NewManager(nil, userRepo, nil, nil)
+
+	sess := &session.Session{UserID: id, Username: "username"}
+	ok, err := manager.HasUserExist(sess)
+	if !ok || err != nil || sess.Role != user.RoleUser {
+		t.Errorf("wrong result, got %v, error %v, role %q", ok, err, sess.Role)
+		return
+	}
+
+	if err = userRepo.SetBanned(id, true); err != nil {
+		t.Fatalf("unable ban user: %v", err)
+	}
+	if _, err = manager.HasUserExist(sess); err != session.ErrUserBanned {
+		t.Errorf("expected error %v, got %v", session.ErrUserBanned, err)
+	}
+}
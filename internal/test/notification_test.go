@@ -0,0 +1,92 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/notification"
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "no mentions here", expected: []string{}},
+		{text: "u/alice said hi", expected: []string{"alice"}},
+		{text: "ask /u/bob or u/carol_2, u/bob again", expected: []string{"bob", "carol_2"}},
+		{text: "see example.com/u/dave and stu/u/eve", expected: []string{}},
+		{text: "(u/frank-x)", expected: []string{"frank-x"}},
+	}
+
+	for _, c := range cases {
+		names := notification.ParseMentions(c.text)
+		if !reflect.DeepEqual(names, c.expected) {
+			t.Errorf("wrong result for %q, expected %v, got %v", c.text, c.expected, names)
+		}
+	}
+}
+
+func TestReachedMilestone(t *testing.T) {
+	cases := map[int]int{
+		-5:    0,
+		9:     0,
+		10:    10,
+		49:    10,
+		51:    50,
+		1000:  1000,
+		99999: 10000,
+	}
+
+	for score, expected := range cases {
+		if milestone := notification.ReachedMilestone(score); milestone != expected {
+			t.Errorf("wrong result for score %d, expected %d, got %d", score, expected, milestone)
+		}
+	}
+}
+
+func TestMemoryNotificationRepo(t *testing.T) {
+	repo := notification.NewMemoryRepo()
+
+	key := notification.MilestoneKey("post", "", 10)
+	err := repo.Add(&notification.Notification{UserID: 1, Type: notification.TypeMilestone, PostID: "post", Score: 10, Key: key})
+	if err != nil {
+		t.Fatalf("unable add notification: %v", err)
+	}
+	err = repo.Add(&notification.Notification{UserID: 1, Type: notification.TypeMilestone, PostID: "post", Score: 10, Key: key})
+	if err != notification.ErrAlreadyExist {
+		t.Errorf("wrong result, expected error %v, got %v", notification.ErrAlreadyExist, err)
+	}
+	reply := &notification.Notification{UserID: 1, Type: notification.TypePostReply, ActorID: 2, PostID: "post"}
+	err = repo.Add(reply)
+	if err != nil {
+		t.Fatalf("unable add notification: %v", err)
+	}
+
+	count, err := repo.CountUnread(1)
+	if err != nil || count != 2 {
+		t.Errorf("wrong result, expected 2 unread, got %d (%v)", count, err)
+	}
+
+	err = repo.MarkRead(reply.ID, 2)
+	if err != notification.ErrNotExist {
+		t.Errorf("wrong result, expected error %v, got %v", notification.ErrNotExist, err)
+	}
+	err = repo.MarkRead(reply.ID, 1)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+
+	unread, err := repo.GetByUser(1, notification.Filter{UnreadOnly: true})
+	if err != nil || len(unread) != 1 || unread[0].Type != notification.TypeMilestone {
+		t.Errorf("wrong result, expected only the milestone unread, got %#v (%v)", unread, err)
+	}
+
+	err = repo.MarkAllRead(1)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	count, _ = repo.CountUnread(1)
+	if count != 0 {
+		t.Errorf("wrong result, expected 0 unread, got %d", count)
+	}
+}
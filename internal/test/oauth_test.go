@@ -0,0 +1,176 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/oauth"
+	"github.com/vlasdash/redditclone/internal/session"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"testing"
+	"time"
+)
+
+func TestValidateRedirectURI(t *testing.T) {
+	tests := []struct {
+		URI   string
+		Valid bool
+	}{
+		{URI: "https://app.example.com/callback", Valid: true},
+		{URI: "http://localhost:3000/callback", Valid: true},
+		{URI: "http://127.0.0.1/callback", Valid: true},
+		{URI: "http://app.example.com/callback", Valid: false},
+		{URI: "https://app.example.com/callback#token", Valid: false},
+		{URI: "/callback", Valid: false},
+		{URI: "javascript:alert(1)", Valid: false},
+	}
+
+	for _, test := range tests {
+		err := oauth.ValidateRedirectURI(test.URI)
+		if (err == nil) != test.Valid {
+			t.Errorf("wrong result for %s: got %v", test.URI, err)
+		}
+	}
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	// RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if err := oauth.ValidateChallenge(challenge, oauth.S256); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	if err := oauth.ValidateChallenge(challenge, "plain"); err != oauth.ErrBadCodeChallenge {
+		t.Errorf("wrong error: got %v, expected %v", err, oauth.ErrBadCodeChallenge)
+	}
+	if !oauth.VerifyPKCE(verifier, challenge) {
+		t.Errorf("verifier doesn't match the challenge")
+	}
+	if oauth.VerifyPKCE(verifier[1:]+"A", challenge) {
+		t.Errorf("wrong verifier matches the challenge")
+	}
+}
+
+func TestMemoryOAuthTokenRepo(t *testing.T) {
+	repo := oauth.NewMemoryTokenRepo(time.Minute, time.Hour)
+
+	pair, err := repo.Issue(&oauth.Grant{ClientID: "client", UserID: 1, Username: "username", Scopes: []session.Scope{session.ScopeRead}})
+	if err != nil {
+		t.Fatalf("unable issue tokens: %v", err)
+	}
+
+	sess, err := repo.Verify(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	if sess.UserID != 1 || sess.ClientID != "client" || !sess.Allows(session.ScopeRead) || sess.Allows(session.ScopeVote) {
+		t.Errorf("wrong session: %+v", sess)
+	}
+	if _, err = repo.Verify(pair.RefreshToken); err != session.ErrBadToken {
+		t.Errorf("refresh token accepted as access token: %v", err)
+	}
+
+	if _, err = repo.Refresh(pair.RefreshToken, "other"); err != session.ErrBadToken {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrBadToken)
+	}
+	rotated, err := repo.Refresh(pair.RefreshToken, "client")
+	if err != nil {
+		t.Fatalf("unable refresh tokens: %v", err)
+	}
+	if _, err = repo.Verify(pair.AccessToken); err != session.ErrBadToken {
+		t.Errorf("old access token still works: %v", err)
+	}
+	if _, err = repo.Refresh(pair.RefreshToken, "client"); err != session.ErrBadToken {
+		t.Errorf("old refresh token still works: %v", err)
+	}
+
+	if err = repo.Revoke(rotated.RefreshToken, "client"); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	if _, err = repo.Verify(rotated.AccessToken); err != session.ErrBadToken {
+		t.Errorf("access token survived revocation: %v", err)
+	}
+	if err = repo.RevokeClient(1, "client"); err != oauth.ErrNoGrant {
+		t.Errorf("wrong error: got %v, expected %v", err, oauth.ErrNoGrant)
+	}
+}
+
+func TestOAuthCodeConsume(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := oauth.NewMySQLCodeRepo(db, time.Minute)
+
+	rows := sqlmock.NewRows([]string{"client_id", "user_id", "username", "redirect_uri", "scopes", "challenge", "expiration_date"}).
+		AddRow("client", 1, "username", "https://app.example.com/cb", "read,vote", "challenge", time.Now().Add(time.Minute).Unix())
+	mock.ExpectBegin()
+	mock.
+		ExpectQuery("SELECT client_id, user_id, username, redirect_uri, scopes, challenge, expiration_date FROM oauth_codes WHERE code_hash = \\? FOR UPDATE").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+	mock.
+		ExpectExec("DELETE FROM oauth_codes WHERE code_hash = ?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	code, err := repo.Consume("code")
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if code.ClientID != "client" || len(code.Scopes) != 2 || code.Scopes[1] != session.ScopeVote {
+		t.Errorf("wrong code: %+v", code)
+	}
+
+	mock.ExpectBegin()
+	mock.
+		ExpectQuery("SELECT client_id, user_id, username, redirect_uri, scopes, challenge, expiration_date FROM oauth_codes WHERE code_hash = \\? FOR UPDATE").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"client_id", "user_id", "username", "redirect_uri", "scopes", "challenge", "expiration_date"}))
+	mock.ExpectRollback()
+
+	_, err = repo.Consume("code")
+	if err != oauth.ErrBadCode {
+		t.Errorf("wrong error: got %v, expected %v", err, oauth.ErrBadCode)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
+
+func TestOAuthTokenVerifyExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Logf("cant create mock: %v", err)
+		return
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			return
+		}
+	}()
+	repo := oauth.NewMySQLTokenRepo(db, time.Minute, time.Hour)
+
+	rows := sqlmock.NewRows([]string{"client_id", "user_id", "username", "scopes", "expiration_date"}).
+		AddRow("client", 1, "username", "read", time.Now().Add(-time.Minute).Unix())
+	mock.
+		ExpectQuery("SELECT client_id, user_id, username, scopes, expiration_date FROM oauth_tokens WHERE token_hash = \\? AND refresh = 0").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	_, err = repo.Verify(session.OAuthTokenPrefix + "token")
+	if err != session.ErrTokenExpired {
+		t.Errorf("wrong error: got %v, expected %v", err, session.ErrTokenExpired)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectation error: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/pagination"
+	"testing"
+)
+
+func TestPaginationIDLess(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"2", "10", true},
+		{"10", "2", false},
+		{"634f1a2b0000000000000000", "634f1a2c0000000000000000", true},
+		{"1", "1", false},
+	}
+
+	for _, c := range cases {
+		if less := pagination.IDLess(c.a, c.b); less != c.expected {
+			t.Errorf("wrong result for %s < %s, expected %v, got %v", c.a, c.b, c.expected, less)
+		}
+	}
+}
+
+func TestPaginationCursor(t *testing.T) {
+	type cursor struct {
+		ID  string  `json:"id"`
+		Key float64 `json:"key"`
+	}
+
+	expected := cursor{ID: "1", Key: 0.1}
+	decoded := cursor{}
+	err := pagination.DecodeCursor(pagination.EncodeCursor(&expected), &decoded)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+		return
+	}
+	if decoded != expected {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, decoded)
+	}
+
+	if err = pagination.DecodeCursor("not cursor", &decoded); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
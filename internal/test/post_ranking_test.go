@@ -0,0 +1,145 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/post"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func rankedIDs(page *post.Page) []string {
+	ids := make([]string, 0, len(page.Posts))
+	for _, p := range page.Posts {
+		ids = append(ids, p.ID)
+	}
+
+	return ids
+}
+
+func TestPostRank(t *testing.T) {
+	now := time.Date(2022, 10, 10, 12, 0, 0, 0, time.UTC)
+	posts := []*post.Post{
+		{
+			ID:             "1",
+			CreateDate:     now.AddDate(0, 0, -10).Format(time.RFC3339),
+			UpvotesCount:   100,
+			DownvotesCount: 0,
+		},
+		{
+			ID:             "2",
+			CreateDate:     now.Add(-2 * time.Hour).Format(time.RFC3339),
+			UpvotesCount:   10,
+			DownvotesCount: 8,
+			Views:          300,
+		},
+		{
+			ID:             "3",
+			CreateDate:     now.Add(-1 * time.Hour).Format(time.RFC3339),
+			UpvotesCount:   5,
+			DownvotesCount: 0,
+		},
+	}
+
+	cases := []struct {
+		opts     post.PageOptions
+		expected []string
+	}{
+		{post.PageOptions{Sort: post.SortNew}, []string{"3", "2", "1"}},
+		{post.PageOptions{Sort: post.SortTop, Period: post.PeriodAll}, []string{"1", "3", "2"}},
+		{post.PageOptions{Sort: post.SortTop, Period: post.PeriodDay}, []string{"3", "2"}},
+		{post.PageOptions{Sort: post.SortHot}, []string{"3", "2", "1"}},
+		{post.PageOptions{Sort: post.SortRising}, []string{"2", "3"}},
+		{post.PageOptions{Sort: post.SortControversial}, []string{"2", "3", "1"}},
+	}
+
+	for _, c := range cases {
+		page, err := post.Rank(posts, c.opts, now)
+		if err != nil {
+			t.Errorf("wrong result for %s, got error: %v", c.opts.Sort, err)
+			continue
+		}
+		if ids := rankedIDs(page); !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("wrong result for %s, expected %v, got %v", c.opts.Sort, c.expected, ids)
+		}
+	}
+}
+
+func TestPostRankPagination(t *testing.T) {
+	now := time.Date(2022, 10, 10, 12, 0, 0, 0, time.UTC)
+	posts := make([]*post.Post, 0, 5)
+	for _, id := range []string{"1", "2", "3", "10", "11"} {
+		posts = append(posts, &post.Post{
+			ID:           id,
+			CreateDate:   now.Format(time.RFC3339),
+			UpvotesCount: 1,
+		})
+	}
+
+	opts := post.PageOptions{
+		Limit: 2,
+		Sort:  post.SortTop,
+	}
+	ids := make([]string, 0, len(posts))
+	for {
+		page, err := post.Rank(posts, opts, now)
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		ids = append(ids, rankedIDs(page)...)
+		if page.Next == "" {
+			break
+		}
+		opts.After = page.Next
+	}
+
+	expected := []string{"11", "10", "3", "2", "1"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("wrong result, expected %v, got %v", expected, ids)
+	}
+
+	opts.Sort = post.SortHot
+	_, err := post.Rank(posts, opts, now)
+	if err != post.ErrInvalidCursor {
+		t.Errorf("wrong result, expected error %v, got %v", post.ErrInvalidCursor, err)
+	}
+}
+
+func TestPostRankRisingSnapshot(t *testing.T) {
+	now := time.Date(2022, 10, 10, 12, 0, 0, 0, time.UTC)
+	posts := []*post.Post{
+		{ID: "1", CreateDate: now.Add(-20 * time.Hour).Format(time.RFC3339), UpvotesCount: 100},
+		{ID: "2", CreateDate: now.Add(-1 * time.Hour).Format(time.RFC3339), UpvotesCount: 10},
+		{ID: "3", CreateDate: now.Add(-6 * time.Hour).Format(time.RFC3339), UpvotesCount: 30},
+		{ID: "4", CreateDate: now.Add(-30 * time.Minute).Format(time.RFC3339), UpvotesCount: 2},
+	}
+
+	first, err := post.Rank(posts, post.PageOptions{Sort: post.SortRising}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := rankedIDs(first)
+
+	// every next page is asked for hours later, when the scores have moved and
+	// the oldest post has left the rising window
+	opts := post.PageOptions{
+		Limit: 1,
+		Sort:  post.SortRising,
+	}
+	ids := make([]string, 0, len(posts))
+	for at := now; ; at = at.Add(3 * time.Hour) {
+		page, err := post.Rank(posts, opts, at)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, rankedIDs(page)...)
+		if page.Next == "" {
+			break
+		}
+		opts.After = page.Next
+	}
+
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("wrong result, expected %v, got %v", expected, ids)
+	}
+}
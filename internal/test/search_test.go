@@ -0,0 +1,170 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/search"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemorySearcher(t *testing.T) {
+	searcher := search.NewMemorySearcher()
+	created := time.Date(2022, 10, 10, 10, 10, 10, 0, time.UTC)
+	docs := []*search.Document{
+		{
+			Type:       search.TypePost,
+			ID:         "1",
+			PostID:     "1",
+			Category:   "programming",
+			AuthorID:   1,
+			Title:      "Golang generics",
+			Text:       "how do you use them",
+			CreateDate: created,
+		},
+		{
+			Type:       search.TypePost,
+			ID:         "2",
+			PostID:     "2",
+			Category:   "news",
+			AuthorID:   2,
+			Title:      "Release notes",
+			Text:       "golang 1.19 is out",
+			CreateDate: created,
+		},
+		{
+			Type:     search.TypeComment,
+			ID:       "10",
+			PostID:   "1",
+			Category: "programming",
+			AuthorID: 2,
+			Text:     "generics in golang are fine",
+		},
+	}
+	for _, doc := range docs {
+		err := searcher.Index(doc)
+		if err != nil {
+			t.Fatalf("unable index document: %v", err)
+		}
+	}
+
+	results, err := searcher.Search(search.Query{Text: "GoLang"})
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	if len(results) != 3 || results[0].ID != "1" {
+		t.Errorf("wrong result, expected title match first out of 3, got %#v", results)
+	}
+
+	results, err = searcher.Search(search.Query{Text: "golang", Category: "programming", AuthorID: 2})
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "10" || results[0].Type != search.TypeComment {
+		t.Errorf("wrong result, expected only comment 10, got %#v", results)
+	}
+
+	results, err = searcher.Search(search.Query{Text: "golang", Since: created.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "10" {
+		t.Errorf("wrong result, expected only the recent comment, got %#v", results)
+	}
+
+	err = searcher.Index(&search.Document{
+		Type:     search.TypePost,
+		ID:       "2",
+		PostID:   "2",
+		Category: "news",
+		AuthorID: 2,
+		Title:    "Release notes",
+		Text:     "rust 1.64 is out",
+	})
+	if err != nil {
+		t.Fatalf("unable index document: %v", err)
+	}
+	results, err = searcher.Search(search.Query{Text: "rust"})
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	expected := created.Format(time.RFC3339)
+	if len(results) != 1 || results[0].CreateDate != expected {
+		t.Errorf("wrong result, expected reindexed post with date %s, got %#v", expected, results)
+	}
+
+	err = searcher.RemoveByPost("1")
+	if err != nil {
+		t.Fatalf("unable remove documents: %v", err)
+	}
+	results, err = searcher.Search(search.Query{Text: "golang"})
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("wrong result, expected no documents, got %#v", results)
+	}
+
+	_, err = searcher.Search(search.Query{Text: " ? "})
+	if err != search.ErrEmptyQuery {
+		t.Errorf("wrong result, expected error %v, got %v", search.ErrEmptyQuery, err)
+	}
+}
+
+func TestMongoSearcherSearch(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		searcher := search.MongoSearcher{
+			Documents: mt.Coll,
+		}
+
+		created := time.Date(2022, 10, 10, 10, 10, 10, 0, time.UTC)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.search", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "post:1"},
+			{Key: "type", Value: search.TypePost},
+			{Key: "target_id", Value: "1"},
+			{Key: "post_id", Value: "1"},
+			{Key: "category", Value: "programming"},
+			{Key: "author_id", Value: 1},
+			{Key: "title", Value: "Golang generics"},
+			{Key: "create_date", Value: created},
+			{Key: "score", Value: 1.5},
+		}))
+
+		expected := []*search.Result{
+			{
+				Type:       search.TypePost,
+				ID:         "1",
+				PostID:     "1",
+				Category:   "programming",
+				AuthorID:   1,
+				Title:      "Golang generics",
+				CreateDate: created.Format(time.RFC3339),
+				Score:      1.5,
+			},
+		}
+
+		results, err := searcher.Search(search.Query{Text: "golang"})
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			return
+		}
+		if !reflect.DeepEqual(results, expected) {
+			t.Errorf("wrong result, expected %#v, got %#v", expected, results)
+		}
+	})
+
+	mt.Run("empty query", func(mt *mtest.T) {
+		searcher := search.MongoSearcher{
+			Documents: mt.Coll,
+		}
+
+		_, err := searcher.Search(search.Query{Text: ""})
+		if err != search.ErrEmptyQuery {
+			t.Errorf("wrong result, expected error %v, got %v", search.ErrEmptyQuery, err)
+		}
+	})
+}
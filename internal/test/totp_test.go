@@ -0,0 +1,163 @@
+package test
+
+import (
+	"github.com/vlasdash/redditclone/internal/twofactor"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// base32 of the RFC 6238 SHA1 test key "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		Time int64
+		Code string
+	}{
+		{Time: 59, Code: "287082"},
+		{Time: 1111111109, Code: "081804"},
+		{Time: 1234567890, Code: "005924"},
+		{Time: 20000000000, Code: "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := twofactor.Code(rfcSecret, twofactor.Step(time.Unix(test.Time, 0)))
+		if err != nil {
+			t.Errorf("wrong result, got error: %v", err)
+			continue
+		}
+		if code != test.Code {
+			t.Errorf("wrong code at %d: got %s, expected %s", test.Time, code, test.Code)
+		}
+	}
+}
+
+func TestTOTPValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	step, err := twofactor.Validate(rfcSecret, "005924", now)
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	if step != twofactor.Step(now) {
+		t.Errorf("wrong step: got %d, expected %d", step, twofactor.Step(now))
+	}
+
+	// a code from the previous step is still accepted
+	_, err = twofactor.Validate(rfcSecret, "005924", now.Add(twofactor.Period*time.Second))
+	if err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+
+	_, err = twofactor.Validate(rfcSecret, "005924", now.Add(3*twofactor.Period*time.Second))
+	if err != twofactor.ErrInvalidCode {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrInvalidCode)
+	}
+
+	_, err = twofactor.Validate(rfcSecret, "5924", now)
+	if err != twofactor.ErrInvalidCode {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrInvalidCode)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	secret, err := twofactor.GenerateSecret()
+	if err != nil {
+		t.Fatalf("unable generate secret: %v", err)
+	}
+
+	u, err := url.Parse(twofactor.URI("user name", secret))
+	if err != nil {
+		t.Fatalf("unable parse uri: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("wrong uri: %s", u)
+	}
+	if u.Path != "/"+twofactor.Issuer+":user name" {
+		t.Errorf("wrong label: %s", u.Path)
+	}
+	if u.Query().Get("secret") != secret || u.Query().Get("issuer") != twofactor.Issuer {
+		t.Errorf("wrong query: %s", u.RawQuery)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := twofactor.NewRecoveryCodes()
+	if err != nil {
+		t.Fatalf("unable generate codes: %v", err)
+	}
+	if len(codes) != twofactor.RecoveryCodeCount || len(hashes) != twofactor.RecoveryCodeCount {
+		t.Fatalf("wrong count of codes: %d", len(codes))
+	}
+
+	if twofactor.HashCode(" "+strings.ToUpper(codes[0])+" ") != hashes[0] {
+		t.Errorf("hash depends on the way code is typed")
+	}
+	if twofactor.HashCode(strings.ReplaceAll(codes[0], "-", "")) != hashes[0] {
+		t.Errorf("hash depends on the dash")
+	}
+}
+
+func TestMemoryTwoFactorRepo(t *testing.T) {
+	repo := twofactor.NewMemoryTwoFactorRepo()
+
+	_, err := repo.Get(1)
+	if err != twofactor.ErrNotEnrolled {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrNotEnrolled)
+	}
+
+	err = repo.Save(&twofactor.Settings{
+		UserID:        1,
+		Secret:        rfcSecret,
+		Enabled:       true,
+		RecoveryCodes: []string{twofactor.HashCode("aaaaa-bbbbb")},
+		LastStep:      10,
+	})
+	if err != nil {
+		t.Fatalf("unable save settings: %v", err)
+	}
+
+	if err = repo.UseStep(1, 10); err != twofactor.ErrInvalidCode {
+		t.Errorf("replayed step accepted: %v", err)
+	}
+	if err = repo.UseStep(1, 11); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+
+	if err = repo.UseRecoveryCode(1, "AAAAABBBBB"); err != nil {
+		t.Errorf("wrong result, got error: %v", err)
+	}
+	if err = repo.UseRecoveryCode(1, "aaaaa-bbbbb"); err != twofactor.ErrInvalidCode {
+		t.Errorf("recovery code used twice: %v", err)
+	}
+}
+
+func TestMemoryChallengeRepo(t *testing.T) {
+	repo := twofactor.NewMemoryChallengeRepo(time.Minute)
+
+	token, err := repo.Add(1, "username")
+	if err != nil {
+		t.Fatalf("unable add challenge: %v", err)
+	}
+
+	c, err := repo.Get(token)
+	if err != nil {
+		t.Fatalf("wrong result, got error: %v", err)
+	}
+	if c.UserID != 1 || c.Username != "username" {
+		t.Errorf("wrong challenge: %+v", c)
+	}
+
+	for i := 0; i < twofactor.MaxAttempts; i++ {
+		_ = repo.Fail(token)
+	}
+	if _, err = repo.Get(token); err != twofactor.ErrBadChallenge {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrBadChallenge)
+	}
+
+	if _, err = repo.Get("unknown"); err != twofactor.ErrBadChallenge {
+		t.Errorf("wrong error: got %v, expected %v", err, twofactor.ErrBadChallenge)
+	}
+}
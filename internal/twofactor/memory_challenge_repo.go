@@ -0,0 +1,83 @@
+package twofactor
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryChallengeRepo struct {
+	Lifetime   time.Duration
+	challenges map[string]*Challenge
+	mu         *sync.Mutex
+}
+
+var _ ChallengeRepo = (*MemoryChallengeRepo)(nil)
+
+func NewMemoryChallengeRepo(lifetime time.Duration) *MemoryChallengeRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultChallengeLifetime
+	}
+
+	return &MemoryChallengeRepo{
+		Lifetime:   lifetime,
+		challenges: make(map[string]*Challenge),
+		mu:         &sync.Mutex{},
+	}
+}
+
+func (r *MemoryChallengeRepo) Add(userID uint, username string) (string, error) {
+	token, hash, err := newChallengeToken()
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for h, c := range r.challenges {
+		if c.ExpirationDate < now.Unix() {
+			delete(r.challenges, h)
+		}
+	}
+	r.challenges[hash] = &Challenge{
+		UserID:         userID,
+		Username:       username,
+		ExpirationDate: now.Add(r.Lifetime).Unix(),
+	}
+
+	return token, nil
+}
+
+func (r *MemoryChallengeRepo) Get(token string) (*Challenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.challenges[hashToken(token)]
+	if !ok || time.Now().Unix() > c.ExpirationDate || c.Attempts >= MaxAttempts {
+		return nil, ErrBadChallenge
+	}
+	result := *c
+
+	return &result, nil
+}
+
+func (r *MemoryChallengeRepo) Fail(token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.challenges[hashToken(token)]; ok {
+		c.Attempts++
+	}
+
+	return nil
+}
+
+func (r *MemoryChallengeRepo) Delete(token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.challenges, hashToken(token))
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package twofactor
+
+import (
+	"sync"
+)
+
+type MemoryTwoFactorRepo struct {
+	settings map[uint]*Settings
+	mu       *sync.RWMutex
+}
+
+var _ TwoFactorRepo = (*MemoryTwoFactorRepo)(nil)
+
+func NewMemoryTwoFactorRepo() *MemoryTwoFactorRepo {
+	return &MemoryTwoFactorRepo{
+		settings: make(map[uint]*Settings),
+		mu:       &sync.RWMutex{},
+	}
+}
+
+func (r *MemoryTwoFactorRepo) Get(userID uint) (*Settings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.settings[userID]
+	if !ok {
+		return nil, ErrNotEnrolled
+	}
+
+	result := *s
+	result.RecoveryCodes = append([]string(nil), s.RecoveryCodes...)
+
+	return &result, nil
+}
+
+func (r *MemoryTwoFactorRepo) Save(s *Settings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *s
+	saved.RecoveryCodes = append([]string(nil), s.RecoveryCodes...)
+	r.settings[s.UserID] = &saved
+
+	return nil
+}
+
+func (r *MemoryTwoFactorRepo) Delete(userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.settings[userID]; !ok {
+		return ErrNotEnrolled
+	}
+	delete(r.settings, userID)
+
+	return nil
+}
+
+func (r *MemoryTwoFactorRepo) UseStep(userID uint, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.settings[userID]
+	if !ok || s.LastStep >= step {
+		return ErrInvalidCode
+	}
+	s.LastStep = step
+
+	return nil
+}
+
+func (r *MemoryTwoFactorRepo) UseRecoveryCode(userID uint, code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.settings[userID]
+	if !ok {
+		return ErrInvalidCode
+	}
+
+	hash := HashCode(code)
+	for i, h := range s.RecoveryCodes {
+		if h == hash {
+			s.RecoveryCodes = append(s.RecoveryCodes[:i], s.RecoveryCodes[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrInvalidCode
+}
@@ -0,0 +1,84 @@
+package twofactor
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MySQLChallengeRepo struct {
+	DB       *sql.DB
+	Lifetime time.Duration
+}
+
+var _ ChallengeRepo = (*MySQLChallengeRepo)(nil)
+
+func NewMySQLChallengeRepo(db *sql.DB, lifetime time.Duration) *MySQLChallengeRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultChallengeLifetime
+	}
+
+	return &MySQLChallengeRepo{
+		DB:       db,
+		Lifetime: lifetime,
+	}
+}
+
+func (r *MySQLChallengeRepo) Add(userID uint, username string) (string, error) {
+	token, hash, err := newChallengeToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.DB.Exec(
+		"INSERT INTO login_challenges (`token_hash`, `user_id`, `username`, `expiration_date`, `attempts`) VALUES (?, ?, ?, ?, ?)",
+		hash,
+		userID,
+		username,
+		time.Now().Add(r.Lifetime).Unix(),
+		0,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (r *MySQLChallengeRepo) Get(token string) (*Challenge, error) {
+	c := &Challenge{}
+	row := r.DB.QueryRow(
+		"SELECT user_id, username, expiration_date, attempts FROM login_challenges WHERE token_hash = ?",
+		hashToken(token),
+	)
+	err := row.Scan(&c.UserID, &c.Username, &c.ExpirationDate, &c.Attempts)
+	if err == sql.ErrNoRows {
+		return nil, ErrBadChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().Unix() > c.ExpirationDate || c.Attempts >= MaxAttempts {
+		return nil, ErrBadChallenge
+	}
+
+	return c, nil
+}
+
+func (r *MySQLChallengeRepo) Fail(token string) error {
+	_, err := r.DB.Exec(
+		"UPDATE login_challenges SET attempts = attempts + 1 WHERE token_hash = ?",
+		hashToken(token),
+	)
+
+	return err
+}
+
+func (r *MySQLChallengeRepo) Delete(token string) error {
+	_, err := r.DB.Exec(
+		"DELETE FROM login_challenges WHERE token_hash = ?",
+		hashToken(token),
+	)
+
+	return err
+}
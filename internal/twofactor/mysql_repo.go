@@ -0,0 +1,155 @@
+package twofactor
+
+import (
+	"database/sql"
+)
+
+type MySQLTwoFactorRepo struct {
+	DB *sql.DB
+}
+
+var _ TwoFactorRepo = (*MySQLTwoFactorRepo)(nil)
+
+func NewMySQLTwoFactorRepo(db *sql.DB) *MySQLTwoFactorRepo {
+	return &MySQLTwoFactorRepo{
+		DB: db,
+	}
+}
+
+func (r *MySQLTwoFactorRepo) Get(userID uint) (*Settings, error) {
+	s := &Settings{UserID: userID}
+	row := r.DB.QueryRow(
+		"SELECT secret, enabled, last_step FROM two_factor WHERE user_id = ?",
+		userID,
+	)
+	err := row.Scan(&s.Secret, &s.Enabled, &s.LastStep)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(
+		"SELECT code_hash FROM recovery_codes WHERE user_id = ?",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash string
+		if err = rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		s.RecoveryCodes = append(s.RecoveryCodes, hash)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *MySQLTwoFactorRepo) Save(s *Settings) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.Exec(
+		"INSERT INTO two_factor (`user_id`, `secret`, `enabled`, `last_step`) VALUES (?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE secret = VALUES(secret), enabled = VALUES(enabled), last_step = VALUES(last_step)",
+		s.UserID,
+		s.Secret,
+		s.Enabled,
+		s.LastStep,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", s.UserID)
+	if err != nil {
+		return err
+	}
+	for _, hash := range s.RecoveryCodes {
+		_, err = tx.Exec(
+			"INSERT INTO recovery_codes (`user_id`, `code_hash`) VALUES (?, ?)",
+			s.UserID,
+			hash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *MySQLTwoFactorRepo) Delete(userID uint) error {
+	_, err := r.DB.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.DB.Exec("DELETE FROM two_factor WHERE user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotEnrolled
+	}
+
+	return nil
+}
+
+func (r *MySQLTwoFactorRepo) UseStep(userID uint, step int64) error {
+	result, err := r.DB.Exec(
+		"UPDATE two_factor SET last_step = ? WHERE user_id = ? AND last_step < ?",
+		step,
+		userID,
+		step,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInvalidCode
+	}
+
+	return nil
+}
+
+func (r *MySQLTwoFactorRepo) UseRecoveryCode(userID uint, code string) error {
+	result, err := r.DB.Exec(
+		"DELETE FROM recovery_codes WHERE user_id = ? AND code_hash = ?",
+		userID,
+		HashCode(code),
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInvalidCode
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package twofactor
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	Issuer = "redditclone"
+
+	RecoveryCodeCount = 10
+	// MaxAttempts is the number of wrong codes a challenge survives
+	MaxAttempts = 5
+
+	DefaultChallengeLifetime = 5 * time.Minute
+)
+
+var (
+	ErrNotEnrolled    = errors.New("two-factor authentication is not set up")
+	ErrAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidCode    = errors.New("invalid authentication code")
+	ErrBadChallenge   = errors.New("login challenge is invalid or expired")
+	ErrUnableGenerate = errors.New("can`t create two-factor secret")
+)
+
+// Settings of a user. Recovery codes are kept as hashes, LastStep is the time
+// step of the last accepted code, so a code can't be replayed.
+type Settings struct {
+	UserID        uint
+	Secret        string
+	Enabled       bool
+	RecoveryCodes []string
+	LastStep      int64
+}
+
+type Challenge struct {
+	UserID         uint
+	Username       string
+	ExpirationDate int64
+	Attempts       int
+}
+
+type TwoFactorRepo interface {
+	Get(userID uint) (*Settings, error)
+	Save(s *Settings) error
+	Delete(userID uint) error
+	// UseStep fails with ErrInvalidCode unless step is later than LastStep
+	UseStep(userID uint, step int64) error
+	// UseRecoveryCode removes the code, so each one works once
+	UseRecoveryCode(userID uint, code string) error
+}
+
+// ChallengeRepo keeps the state between the password and the code step of a
+// login.
+type ChallengeRepo interface {
+	Add(userID uint, username string) (token string, err error)
+	Get(token string) (*Challenge, error)
+	Fail(token string) error
+	Delete(token string) error
+}
@@ -2,21 +2,24 @@ package user
 
 import (
 	"sync"
+	"time"
 )
 
 type MemoryRepo struct {
-	idCount uint
-	users   []*User
-	mu      *sync.RWMutex
+	idCount  uint
+	users    []*User
+	profiles map[uint]*Profile
+	mu       *sync.RWMutex
 }
 
 var _ UserRepo = (*MemoryRepo)(nil)
 
 func NewMemoryRepo() *MemoryRepo {
 	return &MemoryRepo{
-		idCount: 0,
-		users:   make([]*User, 0, 2),
-		mu:      &sync.RWMutex{},
+		idCount:  0,
+		users:    make([]*User, 0, 2),
+		profiles: make(map[uint]*Profile),
+		mu:       &sync.RWMutex{},
 	}
 }
 
@@ -29,7 +32,12 @@ func (r *MemoryRepo) Create(username string, password string) (id uint, err erro
 		ID:       r.idCount,
 		Username: username,
 		Password: password,
+		Role:     RoleUser,
 	})
+	r.profiles[r.idCount] = &Profile{
+		UserID:     r.idCount,
+		CreateDate: time.Now().Format(time.RFC3339),
+	}
 
 	return r.idCount, nil
 }
@@ -63,3 +71,144 @@ func (r *MemoryRepo) GetByID(id uint) (*User, error) {
 
 	return nil, ErrNoExist
 }
+
+func (r *MemoryRepo) GetProfile(id uint) (*Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profiles[id]
+	if !ok {
+		return nil, ErrNoExist
+	}
+	copyProfile := *profile
+
+	return &copyProfile, nil
+}
+
+func (r *MemoryRepo) UpdateProfile(profile *Profile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.profiles[profile.UserID]
+	if !ok {
+		return ErrNoExist
+	}
+	existing.Bio = profile.Bio
+	existing.AvatarURL = profile.AvatarURL
+
+	return nil
+}
+
+func (r *MemoryRepo) UpdatePassword(id uint, password string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.ID != id {
+			continue
+		}
+
+		user.Password = password
+		return nil
+	}
+
+	return ErrNoExist
+}
+
+func (r *MemoryRepo) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, user := range r.users {
+		if user.ID != id {
+			continue
+		}
+
+		r.users = append(r.users[:i], r.users[i+1:]...)
+		delete(r.profiles, id)
+		return nil
+	}
+
+	return ErrNoExist
+}
+
+func (r *MemoryRepo) GetByEmail(email string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if email == "" || user.Email != email {
+			continue
+		}
+
+		return user, nil
+	}
+
+	return nil, ErrNoExist
+}
+
+func (r *MemoryRepo) UpdateEmail(id uint, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found *User
+	for _, user := range r.users {
+		if email != "" && user.Email == email && user.ID != id {
+			return ErrEmailTaken
+		}
+		if user.ID == id {
+			found = user
+		}
+	}
+	if found == nil {
+		return ErrNoExist
+	}
+	found.Email = email
+
+	return nil
+}
+
+func (r *MemoryRepo) GetAll() ([]*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*User, 0, len(r.users))
+	for _, user := range r.users {
+		copyUser := *user
+		users = append(users, &copyUser)
+	}
+
+	return users, nil
+}
+
+func (r *MemoryRepo) SetRole(id uint, role Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.ID != id {
+			continue
+		}
+
+		user.Role = role
+		return nil
+	}
+
+	return ErrNoExist
+}
+
+func (r *MemoryRepo) SetBanned(id uint, banned bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.ID != id {
+			continue
+		}
+
+		user.Banned = banned
+		return nil
+	}
+
+	return ErrNoExist
+}
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"sync"
+	"time"
+)
+
+type resetToken struct {
+	userID         uint
+	expirationDate int64
+}
+
+type MemoryResetRepo struct {
+	Lifetime time.Duration
+	tokens   map[string]*resetToken
+	mu       *sync.Mutex
+}
+
+var _ ResetRepo = (*MemoryResetRepo)(nil)
+
+func NewMemoryResetRepo(lifetime time.Duration) *MemoryResetRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultResetTokenLifetime
+	}
+
+	return &MemoryResetRepo{
+		Lifetime: lifetime,
+		tokens:   make(map[string]*resetToken),
+		mu:       &sync.Mutex{},
+	}
+}
+
+func (r *MemoryResetRepo) Add(userID uint) (string, error) {
+	token, hash, err := newResetToken()
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for h, t := range r.tokens {
+		if t.userID == userID || t.expirationDate < now.Unix() {
+			delete(r.tokens, h)
+		}
+	}
+	r.tokens[hash] = &resetToken{
+		userID:         userID,
+		expirationDate: now.Add(r.Lifetime).Unix(),
+	}
+
+	return token, nil
+}
+
+func (r *MemoryResetRepo) Consume(token string) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash := hashResetToken(token)
+	t, ok := r.tokens[hash]
+	if !ok {
+		return 0, ErrBadResetToken
+	}
+	delete(r.tokens, hash)
+
+	if time.Now().Unix() > t.expirationDate {
+		return 0, ErrBadResetToken
+	}
+
+	return t.userID, nil
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"database/sql"
 	_ "github.com/go-sql-driver/mysql"
+	"time"
 )
 
 type MySQLRepo struct {
@@ -19,9 +20,10 @@ func NewMySQLRepo(db *sql.DB) *MySQLRepo {
 
 func (r *MySQLRepo) Create(username string, password string) (id uint, err error) {
 	result, err := r.DB.Exec(
-		"INSERT INTO users (`username`, `password`) VALUES (?, ?)",
+		"INSERT INTO users (`username`, `password`, `create_date`) VALUES (?, ?, ?)",
 		username,
 		password,
+		time.Now().Format(time.RFC3339),
 	)
 	if err != nil {
 		return 0, err
@@ -37,12 +39,12 @@ func (r *MySQLRepo) Create(username string, password string) (id uint, err error
 
 func (r *MySQLRepo) GetByUsername(username string) (*User, error) {
 	row := r.DB.QueryRow(
-		"SELECT id, username, password FROM users WHERE username = ?",
+		"SELECT id, username, password, email, role, banned FROM users WHERE username = ?",
 		username,
 	)
 
 	user := &User{}
-	err := row.Scan(&user.ID, &user.Username, &user.Password)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Role, &user.Banned)
 	if err == sql.ErrNoRows {
 		return nil, ErrNoExist
 	}
@@ -55,12 +57,12 @@ func (r *MySQLRepo) GetByUsername(username string) (*User, error) {
 
 func (r *MySQLRepo) GetByID(id uint) (*User, error) {
 	row := r.DB.QueryRow(
-		"SELECT id, username, password FROM users WHERE id = ?",
+		"SELECT id, username, password, email, role, banned FROM users WHERE id = ?",
 		id,
 	)
 
 	user := &User{}
-	err := row.Scan(&user.ID, &user.Username, &user.Password)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Role, &user.Banned)
 	if err == sql.ErrNoRows {
 		return nil, ErrNoExist
 	}
@@ -70,3 +72,203 @@ func (r *MySQLRepo) GetByID(id uint) (*User, error) {
 
 	return user, nil
 }
+
+func (r *MySQLRepo) GetProfile(id uint) (*Profile, error) {
+	row := r.DB.QueryRow(
+		"SELECT id, bio, avatar_url, create_date FROM users WHERE id = ?",
+		id,
+	)
+
+	profile := &Profile{}
+	err := row.Scan(&profile.UserID, &profile.Bio, &profile.AvatarURL, &profile.CreateDate)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (r *MySQLRepo) UpdateProfile(profile *Profile) error {
+	result, err := r.DB.Exec(
+		"UPDATE users SET `bio` = ?, `avatar_url` = ? WHERE id = ?",
+		profile.Bio,
+		profile.AvatarURL,
+		profile.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// MySQL reports zero affected rows when the values did not change, so the
+	// user is checked separately
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		_, err = r.GetByID(profile.UserID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *MySQLRepo) UpdatePassword(id uint, password string) error {
+	result, err := r.DB.Exec(
+		"UPDATE users SET `password` = ? WHERE id = ?",
+		password,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoExist
+	}
+
+	return nil
+}
+
+func (r *MySQLRepo) Delete(id uint) error {
+	result, err := r.DB.Exec(
+		"DELETE FROM users WHERE id = ?",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoExist
+	}
+
+	return nil
+}
+
+func (r *MySQLRepo) GetByEmail(email string) (*User, error) {
+	row := r.DB.QueryRow(
+		"SELECT id, username, password, email, role, banned FROM users WHERE email = ? AND email <> ''",
+		email,
+	)
+
+	user := &User{}
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Role, &user.Banned)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *MySQLRepo) UpdateEmail(id uint, email string) error {
+	if email != "" {
+		u, err := r.GetByEmail(email)
+		if err == nil && u.ID != id {
+			return ErrEmailTaken
+		}
+		if err != nil && err != ErrNoExist {
+			return err
+		}
+	}
+
+	result, err := r.DB.Exec(
+		"UPDATE users SET `email` = ? WHERE id = ?",
+		email,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		_, err = r.GetByID(id)
+		return err
+	}
+
+	return nil
+}
+
+func (r *MySQLRepo) GetAll() ([]*User, error) {
+	rows, err := r.DB.Query(
+		"SELECT id, username, password, email, role, banned FROM users ORDER BY id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*User, 0)
+	for rows.Next() {
+		user := &User{}
+		err = rows.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Role, &user.Banned)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *MySQLRepo) SetRole(id uint, role Role) error {
+	result, err := r.DB.Exec(
+		"UPDATE users SET `role` = ? WHERE id = ?",
+		role,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		_, err = r.GetByID(id)
+		return err
+	}
+
+	return nil
+}
+
+func (r *MySQLRepo) SetBanned(id uint, banned bool) error {
+	result, err := r.DB.Exec(
+		"UPDATE users SET `banned` = ? WHERE id = ?",
+		banned,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		_, err = r.GetByID(id)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package user
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MySQLResetRepo struct {
+	DB       *sql.DB
+	Lifetime time.Duration
+}
+
+var _ ResetRepo = (*MySQLResetRepo)(nil)
+
+func NewMySQLResetRepo(db *sql.DB, lifetime time.Duration) *MySQLResetRepo {
+	if lifetime <= 0 {
+		lifetime = DefaultResetTokenLifetime
+	}
+
+	return &MySQLResetRepo{
+		DB:       db,
+		Lifetime: lifetime,
+	}
+}
+
+func (r *MySQLResetRepo) Add(userID uint) (string, error) {
+	token, hash, err := newResetToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.DB.Exec(
+		"DELETE FROM password_resets WHERE user_id = ?",
+		userID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = r.DB.Exec(
+		"INSERT INTO password_resets (`token_hash`, `user_id`, `expiration_date`) VALUES (?, ?, ?)",
+		hash,
+		userID,
+		time.Now().Add(r.Lifetime).Unix(),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (r *MySQLResetRepo) Consume(token string) (uint, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	hash := hashResetToken(token)
+	row := tx.QueryRow(
+		"SELECT user_id, expiration_date FROM password_resets WHERE token_hash = ? FOR UPDATE",
+		hash,
+	)
+
+	var userID uint
+	var expirationDate int64
+	err = row.Scan(&userID, &expirationDate)
+	if err == sql.ErrNoRows {
+		return 0, ErrBadResetToken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("DELETE FROM password_resets WHERE token_hash = ?", hash)
+	if err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if time.Now().Unix() > expirationDate {
+		return 0, ErrBadResetToken
+	}
+
+	return userID, nil
+}
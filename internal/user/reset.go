@@ -0,0 +1,39 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const DefaultResetTokenLifetime = 30 * time.Minute
+
+var (
+	ErrBadResetToken            = errors.New("reset token is invalid or expired")
+	ErrUnableGenerateResetToken = errors.New("can`t create reset token")
+)
+
+// ResetRepo keeps one-time tokens for password resets. Issuing a token drops
+// the ones the user got before, and a token works only once.
+type ResetRepo interface {
+	Add(userID uint) (token string, err error)
+	Consume(token string) (userID uint, err error)
+}
+
+func newResetToken() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", ErrUnableGenerateResetToken
+	}
+	token = hex.EncodeToString(buf)
+
+	return token, hashResetToken(token), nil
+}
+
+// only hashes are stored, like for refresh tokens
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,25 +2,172 @@ package user
 
 import (
 	"errors"
+	"net/mail"
+	"net/url"
+	"unicode/utf8"
 )
 
+const (
+	MaxBioLength       = 500
+	MaxAvatarURLLength = 500
+	MaxEmailLength     = 254
+
+	// DeletedID is left as the author of posts and comments of removed accounts.
+	DeletedID       uint = 0
+	DeletedUsername      = "[deleted]"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission int
+
+const (
+	// PermRemoveContent removes posts and comments in any community.
+	PermRemoveContent Permission = iota
+	// PermManageUsers lists accounts, bans them and changes their roles.
+	PermManageUsers
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleModerator: {PermRemoveContent},
+	RoleAdmin:     {PermRemoveContent, PermManageUsers},
+}
+
 var (
-	ErrNoExist = errors.New("user doesn`t exist")
+	ErrNoExist          = errors.New("user doesn`t exist")
+	ErrInvalidBio       = errors.New("bio is too long")
+	ErrInvalidAvatarURL = errors.New("avatar url must be an http or https link")
+	ErrInvalidEmail     = errors.New("email is invalid")
+	ErrEmailTaken       = errors.New("email is already in use")
+	ErrInvalidRole      = errors.New("role must be user, moderator or admin")
 )
 
 type User struct {
 	ID       uint   `json:"id,string"`
 	Username string `json:"username"`
 	Password string `json:"-"`
+	Email    string `json:"-"`
+	Role     Role   `json:"-"`
+	Banned   bool   `json:"-"`
+}
+
+type Profile struct {
+	UserID     uint
+	Bio        string
+	AvatarURL  string
+	CreateDate string
 }
 
 type UserRepo interface {
 	GetByUsername(username string) (*User, error)
 	GetByID(id uint) (*User, error)
 	Create(username string, password string) (id uint, err error)
+	GetProfile(id uint) (*Profile, error)
+	UpdateProfile(profile *Profile) error
+	UpdatePassword(id uint, password string) error
+	GetByEmail(email string) (*User, error)
+	UpdateEmail(id uint, email string) error
+	Delete(id uint) error
+	GetAll() ([]*User, error)
+	SetRole(id uint, role Role) error
+	SetBanned(id uint, banned bool) error
 }
 
 type PasswordHasher interface {
 	IsPassword(hash string, password string) bool
 	GetHashPassword(password string) (string, error)
 }
+
+// Deleted returns the placeholder shown instead of a removed account.
+func Deleted() *User {
+	return &User{
+		ID:       DeletedID,
+		Username: DeletedUsername,
+	}
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if role != RoleUser && role != RoleModerator && role != RoleAdmin {
+		return "", ErrInvalidRole
+	}
+
+	return role, nil
+}
+
+// EnsureAdmins gives the admin role to the listed accounts, it is the only way
+// to get the first admin of a fresh install. Usernames that aren't registered
+// yet are returned so they can be promoted on a later start.
+func EnsureAdmins(repo UserRepo, usernames []string) (missing []string, err error) {
+	for _, username := range usernames {
+		u, err := repo.GetByUsername(username)
+		if err == ErrNoExist {
+			missing = append(missing, username)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if u.Role == RoleAdmin {
+			continue
+		}
+
+		err = repo.SetRole(u.ID, RoleAdmin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return missing, nil
+}
+
+func (r Role) Can(p Permission) bool {
+	for _, permission := range rolePermissions[r] {
+		if permission == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Profile) Validate() error {
+	if utf8.RuneCountInString(p.Bio) > MaxBioLength {
+		return ErrInvalidBio
+	}
+
+	if p.AvatarURL == "" {
+		return nil
+	}
+	if len(p.AvatarURL) > MaxAvatarURLLength {
+		return ErrInvalidAvatarURL
+	}
+	u, err := url.Parse(p.AvatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidAvatarURL
+	}
+
+	return nil
+}
+
+// ValidateEmail accepts a bare address, an empty one removes the email.
+func ValidateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	if len(email) > MaxEmailLength {
+		return ErrInvalidEmail
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return ErrInvalidEmail
+	}
+
+	return nil
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/user"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+var (
+	errBanAdmin = errors.New("admins can`t be banned")
+	errOwnRole  = errors.New("can`t change your own role")
+)
+
+// AdminHandler manages accounts site-wide, its routes are behind the
+// user.PermManageUsers check.
+type AdminHandler struct {
+	UserRepo user.UserRepo
+	Logger   *logrus.Entry
+}
+
+type AdminUserResponse struct {
+	ID       uint      `json:"id,string"`
+	Username string    `json:"username"`
+	Role     user.Role `json:"role"`
+	Banned   bool      `json:"banned"`
+}
+
+type AdminRoleRequest struct {
+	Role string `json:"role"`
+}
+
+func NewAdminHandler(ur user.UserRepo, log *logrus.Entry) *AdminHandler {
+	return &AdminHandler{
+		UserRepo: ur,
+		Logger:   log,
+	}
+}
+
+func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.UserRepo.GetAll()
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get users from repository: ", err)
+		http.Error(w, "unable get users", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]*AdminUserResponse, 0, len(users))
+	for _, u := range users {
+		result = append(result, &AdminUserResponse{
+			ID:       u.ID,
+			Username: u.Username,
+			Role:     u.Role,
+			Banned:   u.Banned,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get users: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// target reads the account from the id in the path.
+func (h *AdminHandler) target(w http.ResponseWriter, r *http.Request) (*user.User, bool) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		err = user.ErrNoExist
+	}
+	var u *user.User
+	if err == nil {
+		u, err = h.UserRepo.GetByID(uint(id))
+	}
+	if err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at admin: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return nil, false
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return nil, false
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get user at admin: ", err)
+		http.Error(w, "unable get user", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return u, true
+}
+
+func (h *AdminHandler) setBanned(w http.ResponseWriter, r *http.Request, banned bool) {
+	u, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	if banned && u.Role == user.RoleAdmin {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": errBanAdmin.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at ban user: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	err := h.UserRepo.SetBanned(u.ID, banned)
+	if err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at ban user: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable update user ban: ", err)
+		http.Error(w, "unable update user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at ban user: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *AdminHandler) Ban(w http.ResponseWriter, r *http.Request) {
+	h.setBanned(w, r, true)
+}
+
+func (h *AdminHandler) Unban(w http.ResponseWriter, r *http.Request) {
+	h.setBanned(w, r, false)
+}
+
+func (h *AdminHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at set role: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at set role: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &AdminRoleRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at set role: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	role, err := user.ParseRole(req.Role)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at set role: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	u, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	// keeps the last admin from locking everyone out
+	if u.ID == sess.UserID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": errOwnRole.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at set role: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	err = h.UserRepo.SetRole(u.ID, role)
+	if err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at set role: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable update user role: ", err)
+		http.Error(w, "unable update user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at set role: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
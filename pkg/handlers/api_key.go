@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type APIKeyHandler struct {
+	APIKeyRepo session.APIKeyRepo
+	Logger     *logrus.Entry
+}
+
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type APIKeyResponse struct {
+	ID         uint            `json:"id,string"`
+	Name       string          `json:"name"`
+	Scopes     []session.Scope `json:"scopes"`
+	CreateDate string          `json:"created"`
+	LastUsed   string          `json:"lastUsed,omitempty"`
+}
+
+func NewAPIKeyHandler(kr session.APIKeyRepo, log *logrus.Entry) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyRepo: kr,
+		Logger:     log,
+	}
+}
+
+func apiKeyResponse(k *session.APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Scopes:     k.Scopes,
+		CreateDate: k.CreateDate,
+		LastUsed:   k.LastUsed,
+	}
+}
+
+// Create returns the key itself only once, afterwards just its hash is known.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at create api key: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at create api key: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &APIKeyRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at create api key: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	err = session.ValidateAPIKeyName(req.Name)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at create api key: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	scopes, err := session.ParseScopes(req.Scopes)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at create api key: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	key, token, err := h.APIKeyRepo.Add(sess.UserID, sess.Username, req.Name, scopes)
+	if err == session.ErrTooManyAPIKeys {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at create api key: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable add api key to repository: ", err)
+		http.Error(w, "unable create api key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"key":   apiKeyResponse(key),
+		"token": token,
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at create api key: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusCreated,
+	}).Info()
+}
+
+func (h *APIKeyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	keys, err := h.APIKeyRepo.GetAll(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get api keys from repository: ", err)
+		http.Error(w, "unable get api keys", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]*APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, apiKeyResponse(k))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get api keys: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *APIKeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": session.ErrNoAPIKey.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at delete api key: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	err = h.APIKeyRepo.Delete(sess.UserID, uint(id))
+	if err == session.ErrNoAPIKey {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at delete api key: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable delete api key from repository: ", err)
+		http.Error(w, "unable delete api key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at delete api key: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
@@ -2,23 +2,48 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/limiter"
 	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/twofactor"
 	"github.com/vlasdash/redditclone/internal/user"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+var errTooManyAttempts = errors.New("too many attempts, try again later")
+
 type AuthorizationHandler struct {
-	UserRepo    user.UserRepo
-	SessionRepo session.SessionRepo
-	Logger      *logrus.Entry
-	Hasher      user.PasswordHasher
+	UserRepo      user.UserRepo
+	SessionRepo   session.SessionRepo
+	Logger        *logrus.Entry
+	Hasher        user.PasswordHasher
+	RefreshRepo   session.RefreshRepo
+	TwoFactorRepo twofactor.TwoFactorRepo
+	ChallengeRepo twofactor.ChallengeRepo
+	// LoginLimiter counts logins and two-factor codes by username until one
+	// login succeeds and failed ones by address, RegisterLimiter every
+	// registration by address
+	LoginLimiter    *limiter.Limiter
+	RegisterLimiter *limiter.Limiter
 }
 
 type AuthorizationRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+}
+
+type SessionResponse struct {
+	CreateDate     string `json:"created"`
+	ExpirationDate string `json:"expires"`
+	UserAgent      string `json:"userAgent"`
+	IP             string `json:"ip"`
+	Current        bool   `json:"current"`
 }
 
 type ResponseError struct {
@@ -28,12 +53,107 @@ type ResponseError struct {
 	Message  string `json:"msg"`
 }
 
-func NewAuthorizationHandler(ur user.UserRepo, sr session.SessionRepo, log *logrus.Entry, ph user.PasswordHasher) *AuthorizationHandler {
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func NewAuthorizationHandler(ur user.UserRepo, sr session.SessionRepo, log *logrus.Entry, ph user.PasswordHasher, rr session.RefreshRepo, tfr twofactor.TwoFactorRepo, cr twofactor.ChallengeRepo, ll *limiter.Limiter, rl *limiter.Limiter) *AuthorizationHandler {
 	return &AuthorizationHandler{
-		UserRepo:    ur,
-		SessionRepo: sr,
-		Logger:      log,
-		Hasher:      ph,
+		UserRepo:        ur,
+		SessionRepo:     sr,
+		Logger:          log,
+		Hasher:          ph,
+		RefreshRepo:     rr,
+		TwoFactorRepo:   tfr,
+		ChallengeRepo:   cr,
+		LoginLimiter:    ll,
+		RegisterLimiter: rl,
+	}
+}
+
+func clientFromRequest(r *http.Request) session.Client {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return session.Client{
+		UserAgent: r.UserAgent(),
+		IP:        ip,
+	}
+}
+
+// tooManyAttempts answers 429, Retry-After is wait rounded up to seconds.
+func tooManyAttempts(w http.ResponseWriter, r *http.Request, logger *logrus.Entry, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.FormatInt(int64((wait+time.Second-1)/time.Second), 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": errTooManyAttempts.Error(),
+	})
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at too many attempts: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusTooManyRequests,
+	}).Info()
+}
+
+// reserveAttempt counts the attempt before it is made, it answers 429 and
+// returns false when the caller has to wait.
+func reserveAttempt(w http.ResponseWriter, r *http.Request, logger *logrus.Entry, l *limiter.Limiter, keys ...limiter.Key) bool {
+	wait, err := l.Reserve(time.Now(), keys...)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable check attempts: ", err)
+		http.Error(w, "unable check attempts", http.StatusInternalServerError)
+		return false
+	}
+	if wait > 0 {
+		tooManyAttempts(w, r, logger, wait)
+		return false
+	}
+
+	return true
+}
+
+// releaseAttempts and resetAttempts don't fail the request, the counter store
+// being down only tightens the limit.
+func (h *AuthorizationHandler) releaseAttempts(r *http.Request, l *limiter.Limiter, keys ...limiter.Key) {
+	err := l.Release(keys...)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}).Error("unable release attempts: ", err)
+	}
+}
+
+func (h *AuthorizationHandler) resetAttempts(r *http.Request, l *limiter.Limiter, keys ...limiter.Key) {
+	err := l.Reset(keys...)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}).Error("unable reset attempts: ", err)
 	}
 }
 
@@ -74,6 +194,11 @@ func (h *AuthorizationHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ip := limiter.IP(clientFromRequest(r).IP)
+	if !reserveAttempt(w, r, h.Logger, h.LoginLimiter, limiter.Username(req.Username), ip) {
+		return
+	}
+
 	u, err := h.UserRepo.GetByUsername(req.Username)
 	if err == user.ErrNoExist {
 		w.WriteHeader(http.StatusUnauthorized)
@@ -125,7 +250,99 @@ func (h *AuthorizationHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.SessionRepo.Add(u.Username, u.ID)
+	// the address only counts failures, but one known password must not clear
+	// its count; the username is reset once the whole login succeeds
+	h.releaseAttempts(r, h.LoginLimiter, ip)
+
+	if u.Banned {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": session.ErrUserBanned.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at login: ", err)
+			http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+			return
+		}
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusForbidden,
+		}).Info()
+		return
+	}
+
+	settings, err := h.TwoFactorRepo.Get(u.ID)
+	if err != nil && err != twofactor.ErrNotEnrolled {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get two-factor settings at login: ", err)
+		http.Error(w, "unable get two-factor settings", http.StatusInternalServerError)
+		return
+	}
+	// the session is issued by LoginTwoFactor once the code is checked
+	if err == nil && settings.Enabled {
+		challenge, err := h.ChallengeRepo.Add(u.ID, u.Username)
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable create login challenge: ", err)
+			http.Error(w, "unable generate token", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"twoFactorRequired": true,
+			"challenge":         challenge,
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at login: ", err)
+			http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusOK,
+		}).Info()
+		return
+	}
+
+	token, err := h.SessionRepo.Add(u.Username, u.ID, clientFromRequest(r))
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error(err.Error())
+		http.Error(w, "unable generate token", http.StatusInternalServerError)
+		return
+	}
+
+	refreshToken, err := h.RefreshRepo.Add(u.Username, u.ID)
 	if err != nil {
 		h.Logger.WithFields(logrus.Fields{
 			"method":      r.Method,
@@ -136,11 +353,13 @@ func (h *AuthorizationHandler) Login(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unable generate token", http.StatusInternalServerError)
 		return
 	}
+	h.resetAttempts(r, h.LoginLimiter, limiter.Username(u.Username))
 
 	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"token": token,
+		"token":        token,
+		"refreshToken": refreshToken,
 	})
 	if err != nil {
 		h.Logger.WithFields(logrus.Fields{
@@ -198,6 +417,10 @@ func (h *AuthorizationHandler) Register(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !reserveAttempt(w, r, h.Logger, h.RegisterLimiter, limiter.IP(clientFromRequest(r).IP)) {
+		return
+	}
+
 	_, err = h.UserRepo.GetByUsername(req.Username)
 	if err != user.ErrNoExist {
 		w.WriteHeader(http.StatusUnprocessableEntity)
@@ -231,6 +454,83 @@ func (h *AuthorizationHandler) Register(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// the email is optional, it is only needed to reset a forgotten password
+	if user.ValidateEmail(req.Email) != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"errors": []ResponseError{
+				{
+					Location: "body",
+					Param:    "email",
+					Value:    req.Email,
+					Message:  "is invalid",
+				},
+			},
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at register: ", err)
+			http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+			return
+		}
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnprocessableEntity,
+		}).Info()
+		return
+	}
+	if req.Email != "" {
+		_, err = h.UserRepo.GetByEmail(req.Email)
+		if err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": []ResponseError{
+					{
+						Location: "body",
+						Param:    "email",
+						Value:    req.Email,
+						Message:  "already exists",
+					},
+				},
+			})
+			if err != nil {
+				h.Logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusInternalServerError,
+				}).Error("unable send json to client at register: ", err)
+				http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+				return
+			}
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusUnprocessableEntity,
+			}).Info()
+			return
+		}
+		if err != user.ErrNoExist {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable get user from db: ", err)
+			http.Error(w, "unable get user from db", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	passwordHash, err := h.Hasher.GetHashPassword(req.Password)
 	if err != nil {
 		h.Logger.WithFields(logrus.Fields{
@@ -255,7 +555,33 @@ func (h *AuthorizationHandler) Register(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	token, err := h.SessionRepo.Add(req.Username, userID)
+	if req.Email != "" {
+		err = h.UserRepo.UpdateEmail(userID, req.Email)
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable set email at register: ", err)
+			http.Error(w, "unable create user", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	token, err := h.SessionRepo.Add(req.Username, userID, clientFromRequest(r))
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error(err.Error())
+		http.Error(w, "unable generate token", http.StatusInternalServerError)
+		return
+	}
+
+	refreshToken, err := h.RefreshRepo.Add(req.Username, userID)
 	if err != nil {
 		h.Logger.WithFields(logrus.Fields{
 			"method":      r.Method,
@@ -270,7 +596,8 @@ func (h *AuthorizationHandler) Register(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusCreated)
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"token": token,
+		"token":        token,
+		"refreshToken": refreshToken,
 	})
 	if err != nil {
 		h.Logger.WithFields(logrus.Fields{
@@ -290,3 +617,409 @@ func (h *AuthorizationHandler) Register(w http.ResponseWriter, r *http.Request)
 		"status_code": http.StatusCreated,
 	}).Info()
 }
+
+func (h *AuthorizationHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at logout: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at logout: ", err)
+		http.Error(w, "can't read request", http.StatusInternalServerError)
+		return
+	}
+
+	// the refresh token is optional, clients that never stored one just drop the session
+	req := &RefreshRequest{}
+	if len(body) != 0 {
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable unmarshal json from client at logout: ", err)
+			http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err = h.SessionRepo.Delete(r.Header.Get("Authorization"))
+	if err == session.ErrBadToken || err == session.ErrTokenRevoked {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at logout: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable revoke session at logout: ", err)
+		http.Error(w, "unable revoke session", http.StatusInternalServerError)
+		return
+	}
+
+	if req.RefreshToken != "" {
+		err = h.RefreshRepo.DeleteFamily(req.RefreshToken)
+		if err != nil && err != session.ErrBadToken {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable revoke refresh token at logout: ", err)
+			http.Error(w, "unable revoke session", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at logout: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *AuthorizationHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	err = h.SessionRepo.DeleteAll(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable revoke sessions at logout all: ", err)
+		http.Error(w, "unable revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.RefreshRepo.DeleteAll(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable revoke refresh tokens at logout all: ", err)
+		http.Error(w, "unable revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at logout all: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *AuthorizationHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	sessions, err := h.SessionRepo.GetAll(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get sessions from repository: ", err)
+		http.Error(w, "unable get sessions", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]*SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, &SessionResponse{
+			CreateDate:     s.CreateDate,
+			ExpirationDate: s.ExpirationDate,
+			UserAgent:      s.UserAgent,
+			IP:             s.IP,
+			Current:        s.Token == sess.Token,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get sessions: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *AuthorizationHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at refresh: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at refresh: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &RefreshRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at refresh: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	rt, refreshToken, err := h.RefreshRepo.Rotate(req.RefreshToken)
+	if err == session.ErrBadToken || err == session.ErrTokenExpired || err == session.ErrTokenReused {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at refresh: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable rotate refresh token: ", err)
+		http.Error(w, "unable refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	// the account may have been deleted or banned since the token was issued,
+	// then the whole family is dropped rather than left for the next request
+	u, err := h.UserRepo.GetByID(rt.UserID)
+	if err != nil && err != user.ErrNoExist {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get user from db: ", err)
+		http.Error(w, "unable get user from db", http.StatusInternalServerError)
+		return
+	}
+	if err == user.ErrNoExist || u.Username != rt.Username || u.Banned {
+		statusCode, refusal := http.StatusUnauthorized, session.ErrBadToken
+		if err == nil && u.Banned {
+			statusCode, refusal = http.StatusForbidden, session.ErrUserBanned
+		}
+
+		err = h.RefreshRepo.DeleteFamily(refreshToken)
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable delete refresh token family: ", err)
+			http.Error(w, "unable refresh token", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": refusal.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at refresh: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": statusCode,
+		}).Info()
+		return
+	}
+
+	token, err := h.SessionRepo.Add(rt.Username, rt.UserID, clientFromRequest(r))
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error(err.Error())
+		http.Error(w, "unable generate token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":        token,
+		"refreshToken": refreshToken,
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at refresh: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/notification"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+const (
+	DefaultCommentDepth = 5
+	MaxCommentDepth     = 10
+)
+
+var errInvalidDepth = errors.New("depth must be a positive number")
+
+// buildCommentTree returns replies of the comment with rootID (top level
+// comments for empty rootID) nested up to depth levels. Replies below the
+// limit are cut off and their parent gets a continuation token in More.
+func buildCommentTree(comments []*CommentResponse, rootID string, depth int) []*CommentResponse {
+	exists := make(map[string]bool, len(comments))
+	for _, c := range comments {
+		exists[c.ID] = true
+	}
+
+	children := make(map[string][]*CommentResponse, len(comments))
+	for _, c := range comments {
+		parentID := c.ParentID
+		// replies of removed comments are kept at the top level
+		if !exists[parentID] {
+			parentID = ""
+		}
+
+		children[parentID] = append(children[parentID], c)
+	}
+
+	var attach func(parentID string, level int) []*CommentResponse
+	attach = func(parentID string, level int) []*CommentResponse {
+		nodes := children[parentID]
+		for _, c := range nodes {
+			if len(children[c.ID]) == 0 {
+				continue
+			}
+			if level >= depth {
+				c.More = c.ID
+				continue
+			}
+
+			c.Replies = attach(c.ID, level+1)
+		}
+
+		return nodes
+	}
+
+	nodes := attach(rootID, 1)
+	if nodes == nil {
+		nodes = make([]*CommentResponse, 0)
+	}
+
+	return nodes
+}
+
+func parseCommentDepth(r *http.Request) (int, error) {
+	depth := r.URL.Query().Get("depth")
+	if depth == "" {
+		return DefaultCommentDepth, nil
+	}
+
+	d, err := strconv.Atoi(depth)
+	if err != nil || d <= 0 {
+		return 0, errInvalidDepth
+	}
+	if d > MaxCommentDepth {
+		d = MaxCommentDepth
+	}
+
+	return d, nil
+}
+
+func (h *PostHandler) AddReply(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+	parentID := vars["comment_id"]
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at add reply: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable read body", "unable read body at add reply: ", err)
+		return
+	}
+
+	req := &CommentRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		serverError(w, r, h.Logger, "can't unmarshal request from json", "unable unmarshal json from client at add reply: ", err)
+		return
+	}
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add reply")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable get post at add reply: ", err)
+		return
+	}
+
+	hasParent := false
+	for _, id := range p.CommentIDs {
+		if id == parentID {
+			hasParent = true
+			break
+		}
+	}
+	if !hasParent {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, comment.ErrNotExist.Error(), "add reply")
+		return
+	}
+
+	err = h.checkCanComment(p, sess.UserID)
+	if err == post.ErrLocked || err == community.ErrBanned {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add reply")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable check post", "unable check post at add reply: ", err)
+		return
+	}
+
+	commentID, err := h.CommentRepo.AddReply(sess.UserID, parentID, req.Body)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable add comment to bd", "unable add reply to bd: ", err)
+		return
+	}
+
+	err = h.PostRepo.AddComment(postID, commentID)
+	if err != nil {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add reply")
+		return
+	}
+
+	logIndexError(h.Logger, r, h.Searcher.Index(commentDocument(p, commentID, sess.UserID, req.Body)))
+	parent, err := h.CommentRepo.GetByID(parentID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}).Error("unable get parent comment at add reply: ", err)
+		parent = &comment.Comment{}
+	}
+	h.notify(r, &notification.Notification{
+		UserID:    parent.AuthorID,
+		Type:      notification.TypeCommentReply,
+		ActorID:   sess.UserID,
+		PostID:    postID,
+		CommentID: commentID,
+	})
+	h.notifyMentions(r, sess.UserID, postID, commentID, req.Body, parent.AuthorID)
+	publishPost(h.Publisher, p, &event.Event{
+		Type:      event.TypeCommentAdded,
+		PostID:    postID,
+		CommentID: commentID,
+	})
+
+	p, err = h.PostRepo.GetByID(postID, viewsUpdate)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable add reply: ", err)
+		return
+	}
+
+	posts := make([]*post.Post, 0, 1)
+	posts = append(posts, p)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
+	if err != nil || len(resp) != 1 {
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at add reply: ", err)
+		return
+	}
+
+	sendJSON(w, r, h.Logger, http.StatusCreated, resp[0], "add reply")
+}
+
+func (h *PostHandler) GetCommentThread(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID := vars["id"]
+	commentID := vars["comment_id"]
+
+	depth, err := parseCommentDepth(r)
+	if err != nil {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get comment thread")
+		return
+	}
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get comment thread")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post from repository", "unable get post from repository: ", err)
+		return
+	}
+
+	comments, err := h.getComments(p, viewerID(r))
+	if err != nil {
+		serverError(w, r, h.Logger, "unable create response", "unable get comments at get comment thread: ", err)
+		return
+	}
+
+	var root *CommentResponse
+	for _, c := range comments {
+		if c.ID == commentID {
+			root = c
+			break
+		}
+	}
+	if root == nil {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, comment.ErrNotExist.Error(), "get comment thread")
+		return
+	}
+	root.Replies = buildCommentTree(comments, commentID, depth)
+
+	sendJSON(w, r, h.Logger, http.StatusOK, root, "get comment thread")
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"net/http"
+)
+
+func (h *PostHandler) voteComment(w http.ResponseWriter, r *http.Request, vote func(id string, voter uint) error) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+	commentID := vars["comment_id"]
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "comment vote")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable get post at comment vote: ", err)
+		return
+	}
+
+	hasComment := false
+	for _, id := range p.CommentIDs {
+		if id == commentID {
+			hasComment = true
+			break
+		}
+	}
+	if !hasComment {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, comment.ErrNotExist.Error(), "comment vote")
+		return
+	}
+
+	err = vote(commentID, sess.UserID)
+	if err == comment.ErrNotExist || err == comment.ErrInvalidID || err == comment.ErrVoteNotExist {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "comment vote")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable vote for comment", "unable vote for comment: ", err)
+		return
+	}
+
+	c, err := h.CommentRepo.GetByID(commentID)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get comment", "unable get comment at comment vote: ", err)
+		return
+	}
+
+	h.notifyMilestone(r, c.AuthorID, postID, c.ID, c.UpvotesCount-c.DownvotesCount)
+	h.Publisher.Publish(&event.Event{
+		Type:      event.TypeCommentVoted,
+		PostID:    postID,
+		CommentID: c.ID,
+		Data: &event.VoteData{
+			Score:          c.UpvotesCount - c.DownvotesCount,
+			UpvotesCount:   c.UpvotesCount,
+			DownvotesCount: c.DownvotesCount,
+		},
+	}, event.PostTopic(postID))
+
+	resp, err := h.commentResponse(c, sess.UserID)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at comment vote: ", err)
+		return
+	}
+
+	sendJSON(w, r, h.Logger, http.StatusOK, resp, "comment vote")
+}
+
+func (h *PostHandler) UpvoteComment(w http.ResponseWriter, r *http.Request) {
+	h.voteComment(w, r, h.CommentRepo.Upvote)
+}
+
+func (h *PostHandler) DownvoteComment(w http.ResponseWriter, r *http.Request) {
+	h.voteComment(w, r, h.CommentRepo.Downvote)
+}
+
+func (h *PostHandler) UnvoteComment(w http.ResponseWriter, r *http.Request) {
+	h.voteComment(w, r, h.CommentRepo.Unvote)
+}
@@ -0,0 +1,784 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/session"
+	"io/ioutil"
+	"net/http"
+)
+
+type CommunityHandler struct {
+	CommunityRepo    community.CommunityRepo
+	SubscriptionRepo community.SubscriptionRepo
+	Logger           *logrus.Entry
+}
+
+type CommunityRequest struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Rules        []string `json:"rules"`
+	AllowedTypes []string `json:"allowedTypes"`
+	Restricted   bool     `json:"restricted"`
+}
+
+func NewCommunityHandler(cr community.CommunityRepo, sr community.SubscriptionRepo, log *logrus.Entry) *CommunityHandler {
+	return &CommunityHandler{
+		CommunityRepo:    cr,
+		SubscriptionRepo: sr,
+		Logger:           log,
+	}
+}
+
+func (h *CommunityHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	communities, err := h.CommunityRepo.GetAll()
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get communities from repository: ", err)
+		http.Error(w, "unable get communities", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(communities)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get communities: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *CommunityHandler) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	name, err := community.NormalizeName(vars["name"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	c, err := h.CommunityRepo.GetByName(name)
+	if err == community.ErrNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get community from repository: ", err)
+		http.Error(w, "unable get community", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(c)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get community: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *CommunityHandler) Create(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at create community: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at create community: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &CommunityRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at create community: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	name, err := community.NormalizeName(req.Name)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at create community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	c := &community.Community{
+		Name:    name,
+		OwnerID: sess.UserID,
+		Settings: community.Settings{
+			Description:  req.Description,
+			Rules:        req.Rules,
+			AllowedTypes: req.AllowedTypes,
+			Restricted:   req.Restricted,
+		},
+	}
+	err = c.Settings.Validate()
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at create community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	err = h.CommunityRepo.Create(c)
+	if err == community.ErrAlreadyExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at create community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable create community: ", err)
+		http.Error(w, "unable create community", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(c)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at create community: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusCreated,
+	}).Info()
+}
+
+func (h *CommunityHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	name, err := community.NormalizeName(vars["name"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at update community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at update community: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at update community: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	settings := &community.Settings{}
+	err = json.Unmarshal(body, settings)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at update community: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	err = settings.Validate()
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at update community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	err = h.CommunityRepo.UpdateSettings(name, sess.UserID, *settings)
+	if err == community.ErrNotExist || err == community.ErrNoAccess {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at update community: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable update community: ", err)
+		http.Error(w, "unable update community", http.StatusInternalServerError)
+		return
+	}
+
+	c, err := h.CommunityRepo.GetByName(name)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get community at update community: ", err)
+		http.Error(w, "unable get community", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(c)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at update community: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *CommunityHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	name, err := community.NormalizeName(vars["name"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at subscribe: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	_, err = h.CommunityRepo.GetByName(name)
+	if err == community.ErrNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at subscribe: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get community at subscribe: ", err)
+		http.Error(w, "unable get community", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.SubscriptionRepo.Subscribe(sess.UserID, name)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable add subscription to repository: ", err)
+		http.Error(w, "unable subscribe", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at subscribe: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *CommunityHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	name, err := community.NormalizeName(vars["name"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at unsubscribe: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	err = h.SubscriptionRepo.Unsubscribe(sess.UserID, name)
+	if err == community.ErrNotSubscribed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at unsubscribe: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable delete subscription from repository: ", err)
+		http.Error(w, "unable unsubscribe", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at unsubscribe: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *CommunityHandler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	names, err := h.SubscriptionRepo.GetByUser(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get subscriptions from repository: ", err)
+		http.Error(w, "unable get subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(names)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get subscriptions: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/user"
+	"net/http"
+	"strconv"
+)
+
+const (
+	OverviewPost    = "post"
+	OverviewComment = "comment"
+)
+
+type PostLink struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+}
+
+type UserCommentResponse struct {
+	*CommentResponse
+	Post *PostLink `json:"post"`
+}
+
+type UserCommentsResponse struct {
+	Comments []*UserCommentResponse `json:"comments"`
+	Next     string                 `json:"next,omitempty"`
+}
+
+type OverviewItem struct {
+	Kind    string               `json:"kind"`
+	Post    *PostResponse        `json:"post,omitempty"`
+	Comment *UserCommentResponse `json:"comment,omitempty"`
+}
+
+type OverviewResponse struct {
+	Items []*OverviewItem `json:"items"`
+	Next  string          `json:"next,omitempty"`
+}
+
+// overviewCursor keeps separate positions in the posts and in the comments
+// of the user, since the overview reads both lists side by side.
+type overviewCursor struct {
+	Post    string `json:"post,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+func encodeOverviewCursor(c *overviewCursor) string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeOverviewCursor(s string) (*overviewCursor, error) {
+	c := &overviewCursor{}
+	if s == "" {
+		return c, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, post.ErrInvalidCursor
+	}
+	err = json.Unmarshal(data, c)
+	if err != nil {
+		return nil, post.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+func parseHistoryLimit(r *http.Request) (int, error) {
+	limit := r.URL.Query().Get("limit")
+	if limit == "" {
+		return comment.DefaultPageLimit, nil
+	}
+
+	l, err := strconv.Atoi(limit)
+	if err != nil || l <= 0 {
+		return 0, errInvalidLimit
+	}
+	if l > comment.MaxPageLimit {
+		l = comment.MaxPageLimit
+	}
+
+	return l, nil
+}
+
+func parseCommentPageOptions(r *http.Request) (opts comment.PageOptions, err error) {
+	opts.After = r.URL.Query().Get("after")
+	opts.Sort, err = comment.ParseSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit, err = parseHistoryLimit(r)
+
+	return opts, err
+}
+
+func isHistoryRequestError(err error) bool {
+	return err == errInvalidLimit || err == comment.ErrInvalidSort || err == comment.ErrInvalidCursor || err == post.ErrInvalidCursor
+}
+
+func (h *PostHandler) userCommentResponses(comments []*comment.Comment, viewer uint) ([]*UserCommentResponse, error) {
+	resp := make([]*UserCommentResponse, 0, len(comments))
+	if len(comments) == 0 {
+		return resp, nil
+	}
+
+	ids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	posts, err := h.PostRepo.GetByCommentIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make(map[string]*PostLink, len(comments))
+	for _, p := range posts {
+		link := &PostLink{
+			ID:       p.ID,
+			Title:    p.Title,
+			Category: p.Category,
+		}
+		for _, id := range p.CommentIDs {
+			links[id] = link
+		}
+	}
+
+	for _, c := range comments {
+		commentResp, err := h.commentResponse(c, viewer)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, &UserCommentResponse{
+			CommentResponse: commentResp,
+			Post:            links[c.ID],
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *PostHandler) GetCommentsByUsername(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	u, err := h.UserRepo.GetByUsername(vars["username"])
+	if err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get user comments: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get user from db: ", err)
+		http.Error(w, "unable get user from db", http.StatusInternalServerError)
+		return
+	}
+
+	opts, err := parseCommentPageOptions(r)
+	var page *comment.Page
+	if err == nil {
+		page, err = h.CommentRepo.GetPageByAuthor(u.ID, opts)
+	}
+	if isHistoryRequestError(err) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get user comments: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get comments of user from repository: ", err)
+		http.Error(w, "unable get comments from server", http.StatusInternalServerError)
+		return
+	}
+
+	comments, err := h.userCommentResponses(page.Comments, viewerID(r))
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable create response to client at get user comments: ", err)
+		http.Error(w, "unable create response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(&UserCommentsResponse{
+		Comments: comments,
+		Next:     page.Next,
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get user comments: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// GetOverview mixes posts and comments of the user, newest first.
+func (h *PostHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	u, err := h.UserRepo.GetByUsername(vars["username"])
+	if err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get overview: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get user from db: ", err)
+		http.Error(w, "unable get user from db", http.StatusInternalServerError)
+		return
+	}
+
+	limit, err := parseHistoryLimit(r)
+	var after *overviewCursor
+	if err == nil {
+		after, err = decodeOverviewCursor(r.URL.Query().Get("after"))
+	}
+	var postPage *post.Page
+	if err == nil {
+		postPage, err = h.PostRepo.GetPageByAuthor(u.ID, post.PageOptions{
+			Limit: limit,
+			After: after.Post,
+			Sort:  post.SortNew,
+		})
+	}
+	var commentPage *comment.Page
+	if err == nil {
+		commentPage, err = h.CommentRepo.GetPageByAuthor(u.ID, comment.PageOptions{
+			Limit: limit,
+			After: after.Comment,
+			Sort:  comment.SortNew,
+		})
+	}
+	if isHistoryRequestError(err) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get overview: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get overview of user from repository: ", err)
+		http.Error(w, "unable get overview from server", http.StatusInternalServerError)
+		return
+	}
+
+	posts, comments := postPage.Posts, commentPage.Comments
+	kinds := make([]string, 0, limit)
+	i, j := 0, 0
+	for len(kinds) < limit && (i < len(posts) || j < len(comments)) {
+		if j == len(comments) || (i < len(posts) && posts[i].CreateDate >= comments[j].CreateDate) {
+			kinds = append(kinds, OverviewPost)
+			i++
+		} else {
+			kinds = append(kinds, OverviewComment)
+			j++
+		}
+	}
+
+	viewer := viewerID(r)
+	postsResp, err := h.createResponse(posts[:i], DefaultCommentDepth, viewer)
+	var commentsResp []*UserCommentResponse
+	if err == nil {
+		commentsResp, err = h.userCommentResponses(comments[:j], viewer)
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable create response to client at get overview: ", err)
+		http.Error(w, "unable create response", http.StatusInternalServerError)
+		return
+	}
+
+	resp := &OverviewResponse{
+		Items: make([]*OverviewItem, 0, len(kinds)),
+	}
+	for _, kind := range kinds {
+		item := &OverviewItem{
+			Kind: kind,
+		}
+		if kind == OverviewPost {
+			item.Post, postsResp = postsResp[0], postsResp[1:]
+		} else {
+			item.Comment, commentsResp = commentsResp[0], commentsResp[1:]
+		}
+
+		resp.Items = append(resp.Items, item)
+	}
+
+	if i < len(posts) || j < len(comments) || postPage.Next != "" || commentPage.Next != "" {
+		next := &overviewCursor{
+			Post:    after.Post,
+			Comment: after.Comment,
+		}
+		if i > 0 {
+			next.Post = post.NextCursor(posts[i-1], post.SortNew)
+		}
+		if j > 0 {
+			next.Comment = comment.NextCursor(comments[j-1], comment.SortNew)
+		}
+
+		resp.Next = encodeOverviewCursor(next)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get overview: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
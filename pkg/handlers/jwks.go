@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
+	"net/http"
+	"time"
+)
+
+// jwksMaxAge is how long verifiers may cache the key set. Upcoming keys are
+// published before they sign anything, so it only has to be well below the
+// time between a new key being configured and becoming active.
+const jwksMaxAge = "max-age=300"
+
+type JWKSHandler struct {
+	Keys   *session.KeySet
+	Logger *logrus.Entry
+}
+
+func NewJWKSHandler(keys *session.KeySet, log *logrus.Entry) *JWKSHandler {
+	return &JWKSHandler{
+		Keys:   keys,
+		Logger: log,
+	}
+}
+
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", jwksMaxAge)
+	w.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(w).Encode(h.Keys.JWKS(time.Now()))
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get jwks: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
@@ -0,0 +1,1274 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/search"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/user"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+var errNotModerator = errors.New("hasn`t moderator rights in community")
+
+type ModerationHandler struct {
+	PostRepo      post.PostRepo
+	CommentRepo   comment.CommentRepo
+	CommunityRepo community.CommunityRepo
+	ModLogRepo    community.ModLogRepo
+	ReportRepo    community.ReportRepo
+	UserRepo      user.UserRepo
+	Searcher      search.Searcher
+	Publisher     event.Publisher
+	Logger        *logrus.Entry
+}
+
+type ModerationRequest struct {
+	Username string `json:"username"`
+	Reason   string `json:"reason"`
+}
+
+func NewModerationHandler(pr post.PostRepo, cr comment.CommentRepo, comr community.CommunityRepo, mlr community.ModLogRepo, rr community.ReportRepo, ur user.UserRepo, s search.Searcher, pub event.Publisher, log *logrus.Entry) *ModerationHandler {
+	return &ModerationHandler{
+		PostRepo:      pr,
+		CommentRepo:   cr,
+		CommunityRepo: comr,
+		ModLogRepo:    mlr,
+		ReportRepo:    rr,
+		UserRepo:      ur,
+		Searcher:      s,
+		Publisher:     pub,
+		Logger:        log,
+	}
+}
+
+func (h *ModerationHandler) readRequest(w http.ResponseWriter, r *http.Request) (*ModerationRequest, bool) {
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at moderation: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at moderation: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	// the reason is optional, so an empty body is fine
+	req := &ModerationRequest{}
+	if len(body) != 0 {
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable unmarshal json from client at moderation: ", err)
+			http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+			return nil, false
+		}
+	}
+
+	return req, true
+}
+
+// moderatedPost skips the community moderator check for site staff.
+func (h *ModerationHandler) moderatedPost(w http.ResponseWriter, r *http.Request, postID string, moderatorID uint, staff bool) (*post.Post, *community.Community, bool) {
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at moderation: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return nil, nil, false
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return nil, nil, false
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get post at moderation: ", err)
+		http.Error(w, "unable get post by id", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	c, err := h.CommunityRepo.GetByName(p.Category)
+	if err == community.ErrNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at moderation: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return nil, nil, false
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return nil, nil, false
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get community at moderation: ", err)
+		http.Error(w, "unable get community", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	if !staff && !c.IsModerator(moderatorID) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": errNotModerator.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at moderation: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return nil, nil, false
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return nil, nil, false
+	}
+
+	return p, c, true
+}
+
+func (h *ModerationHandler) logAction(r *http.Request, action *community.ModAction) {
+	err := h.ModLogRepo.Add(action)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}).Error("unable write moderation log: ", err)
+	}
+}
+
+func (h *ModerationHandler) clearReports(r *http.Request, targetType string, targetID string) {
+	err := h.ReportRepo.Clear(targetType, targetID)
+	if err != nil && err != community.ErrReportNotExist {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}).Error("unable clear reports: ", err)
+	}
+}
+
+func (h *ModerationHandler) removePost(w http.ResponseWriter, r *http.Request, staff bool) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+
+	req, ok := h.readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	p, c, ok := h.moderatedPost(w, r, postID, sess.UserID, staff)
+	if !ok {
+		return
+	}
+
+	err = h.PostRepo.Remove(postID)
+	if err == post.ErrNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at remove post: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable remove post: ", err)
+		http.Error(w, "unable remove post", http.StatusInternalServerError)
+		return
+	}
+
+	h.clearReports(r, community.TargetPost, p.ID)
+	logIndexError(h.Logger, r, h.Searcher.RemoveByPost(p.ID))
+	publishPost(h.Publisher, p, &event.Event{
+		Type:   event.TypePostDeleted,
+		PostID: p.ID,
+	})
+	h.logAction(r, &community.ModAction{
+		Community:   c.Name,
+		ModeratorID: sess.UserID,
+		Action:      community.ActionRemovePost,
+		TargetID:    p.ID,
+		PostID:      p.ID,
+		Reason:      req.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at remove post: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *ModerationHandler) removeComment(w http.ResponseWriter, r *http.Request, staff bool) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+	commentID := vars["comment_id"]
+
+	req, ok := h.readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	p, c, ok := h.moderatedPost(w, r, postID, sess.UserID, staff)
+	if !ok {
+		return
+	}
+
+	hasComment := false
+	for _, id := range p.CommentIDs {
+		if id == commentID {
+			hasComment = true
+			break
+		}
+	}
+	if !hasComment {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": comment.ErrNotExist.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at remove comment: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	err = h.CommentRepo.Remove(commentID)
+	if err == comment.ErrNotExist || err == comment.ErrInvalidID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at remove comment: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable remove comment: ", err)
+		http.Error(w, "unable remove comment", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.PostRepo.DeleteComment(postID, commentID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable remove comment from post: ", err)
+		http.Error(w, "unable remove comment", http.StatusInternalServerError)
+		return
+	}
+
+	h.clearReports(r, community.TargetComment, commentID)
+	logIndexError(h.Logger, r, h.Searcher.Remove(search.TypeComment, commentID))
+	publishPost(h.Publisher, p, &event.Event{
+		Type:      event.TypeCommentDeleted,
+		PostID:    p.ID,
+		CommentID: commentID,
+	})
+	h.logAction(r, &community.ModAction{
+		Community:   c.Name,
+		ModeratorID: sess.UserID,
+		Action:      community.ActionRemoveComment,
+		TargetID:    commentID,
+		PostID:      p.ID,
+		Reason:      req.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at remove comment: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *ModerationHandler) RemovePost(w http.ResponseWriter, r *http.Request) {
+	h.removePost(w, r, false)
+}
+
+func (h *ModerationHandler) RemoveComment(w http.ResponseWriter, r *http.Request) {
+	h.removeComment(w, r, false)
+}
+
+// AdminRemovePost is routed behind the user.PermRemoveContent check and works
+// in any community.
+func (h *ModerationHandler) AdminRemovePost(w http.ResponseWriter, r *http.Request) {
+	h.removePost(w, r, true)
+}
+
+func (h *ModerationHandler) AdminRemoveComment(w http.ResponseWriter, r *http.Request) {
+	h.removeComment(w, r, true)
+}
+
+func (h *ModerationHandler) setPostFlag(w http.ResponseWriter, r *http.Request, action string) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+
+	req, ok := h.readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	p, c, ok := h.moderatedPost(w, r, postID, sess.UserID, false)
+	if !ok {
+		return
+	}
+
+	switch action {
+	case community.ActionLock:
+		err = h.PostRepo.SetLocked(postID, true)
+	case community.ActionUnlock:
+		err = h.PostRepo.SetLocked(postID, false)
+	case community.ActionPin:
+		err = h.PostRepo.SetPinned(postID, true)
+	case community.ActionUnpin:
+		err = h.PostRepo.SetPinned(postID, false)
+	}
+	if err == post.ErrNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at moderation: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable update post at moderation: ", err)
+		http.Error(w, "unable update post", http.StatusInternalServerError)
+		return
+	}
+
+	h.logAction(r, &community.ModAction{
+		Community:   c.Name,
+		ModeratorID: sess.UserID,
+		Action:      action,
+		TargetID:    p.ID,
+		PostID:      p.ID,
+		Reason:      req.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at moderation: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *ModerationHandler) Lock(w http.ResponseWriter, r *http.Request) {
+	h.setPostFlag(w, r, community.ActionLock)
+}
+
+func (h *ModerationHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	h.setPostFlag(w, r, community.ActionUnlock)
+}
+
+func (h *ModerationHandler) Pin(w http.ResponseWriter, r *http.Request) {
+	h.setPostFlag(w, r, community.ActionPin)
+}
+
+func (h *ModerationHandler) Unpin(w http.ResponseWriter, r *http.Request) {
+	h.setPostFlag(w, r, community.ActionUnpin)
+}
+
+func (h *ModerationHandler) approve(w http.ResponseWriter, r *http.Request, targetType string) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+
+	req, ok := h.readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	p, c, ok := h.moderatedPost(w, r, postID, sess.UserID, false)
+	if !ok {
+		return
+	}
+
+	targetID, action := p.ID, community.ActionApprovePost
+	if targetType == community.TargetComment {
+		targetID, action = vars["comment_id"], community.ActionApproveComment
+	}
+
+	err = h.ReportRepo.Clear(targetType, targetID)
+	if err == community.ErrReportNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at approve: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable clear reports at approve: ", err)
+		http.Error(w, "unable clear reports", http.StatusInternalServerError)
+		return
+	}
+
+	h.logAction(r, &community.ModAction{
+		Community:   c.Name,
+		ModeratorID: sess.UserID,
+		Action:      action,
+		TargetID:    targetID,
+		PostID:      p.ID,
+		Reason:      req.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at approve: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *ModerationHandler) ApprovePost(w http.ResponseWriter, r *http.Request) {
+	h.approve(w, r, community.TargetPost)
+}
+
+func (h *ModerationHandler) ApproveComment(w http.ResponseWriter, r *http.Request) {
+	h.approve(w, r, community.TargetComment)
+}
+
+func (h *ModerationHandler) GetReports(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	name, err := community.NormalizeName(vars["name"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get reports: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	c, err := h.CommunityRepo.GetByName(name)
+	if err == community.ErrNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get reports: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get community at get reports: ", err)
+		http.Error(w, "unable get community", http.StatusInternalServerError)
+		return
+	}
+	if !c.IsModerator(sess.UserID) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": errNotModerator.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get reports: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	items, err := h.ReportRepo.GetQueue(name)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get report queue from repository: ", err)
+		http.Error(w, "unable get reports", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(items)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get reports: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *ModerationHandler) manageMember(w http.ResponseWriter, r *http.Request, action string) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	name, err := community.NormalizeName(vars["name"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at moderation: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	req, ok := h.readRequest(w, r)
+	if !ok {
+		return
+	}
+	if username, ok := vars["username"]; ok {
+		req.Username = username
+	}
+
+	u, err := h.UserRepo.GetByUsername(req.Username)
+	if err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at moderation: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get user at moderation: ", err)
+		http.Error(w, "unable get user", http.StatusInternalServerError)
+		return
+	}
+
+	switch action {
+	case community.ActionAddModerator:
+		err = h.CommunityRepo.AddModerator(name, sess.UserID, u.ID)
+	case community.ActionRemoveModerator:
+		err = h.CommunityRepo.RemoveModerator(name, sess.UserID, u.ID)
+	case community.ActionBan:
+		err = h.CommunityRepo.Ban(name, sess.UserID, u.ID)
+	case community.ActionUnban:
+		err = h.CommunityRepo.Unban(name, sess.UserID, u.ID)
+	}
+	if err == community.ErrNotExist || err == community.ErrNoAccess {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at moderation: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable update community members: ", err)
+		http.Error(w, "unable update community", http.StatusInternalServerError)
+		return
+	}
+
+	h.logAction(r, &community.ModAction{
+		Community:   name,
+		ModeratorID: sess.UserID,
+		Action:      action,
+		TargetID:    strconv.Itoa(int(u.ID)),
+		Reason:      req.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at moderation: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *ModerationHandler) AddModerator(w http.ResponseWriter, r *http.Request) {
+	h.manageMember(w, r, community.ActionAddModerator)
+}
+
+func (h *ModerationHandler) RemoveModerator(w http.ResponseWriter, r *http.Request) {
+	h.manageMember(w, r, community.ActionRemoveModerator)
+}
+
+func (h *ModerationHandler) Ban(w http.ResponseWriter, r *http.Request) {
+	h.manageMember(w, r, community.ActionBan)
+}
+
+func (h *ModerationHandler) Unban(w http.ResponseWriter, r *http.Request) {
+	h.manageMember(w, r, community.ActionUnban)
+}
+
+func (h *ModerationHandler) GetModLog(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	name, err := community.NormalizeName(vars["name"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get mod log: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	c, err := h.CommunityRepo.GetByName(name)
+	if err == community.ErrNotExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get mod log: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get community at get mod log: ", err)
+		http.Error(w, "unable get community", http.StatusInternalServerError)
+		return
+	}
+	if !c.IsModerator(sess.UserID) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": errNotModerator.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at get mod log: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	query := r.URL.Query()
+	filter := community.ModLogFilter{
+		Action: query.Get("action"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": errInvalidLimit.Error(),
+			})
+			if err != nil {
+				h.Logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusInternalServerError,
+				}).Error("unable send json to client at get mod log: ", err)
+				http.Error(w, "unable send json", http.StatusInternalServerError)
+				return
+			}
+
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusBadRequest,
+			}).Info()
+			return
+		}
+	}
+	if moderator := query.Get("moderator"); moderator != "" {
+		u, err := h.UserRepo.GetByUsername(moderator)
+		if err == user.ErrNoExist {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": err.Error(),
+			})
+			if err != nil {
+				h.Logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusInternalServerError,
+				}).Error("unable send json to client at get mod log: ", err)
+				http.Error(w, "unable send json", http.StatusInternalServerError)
+				return
+			}
+
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusBadRequest,
+			}).Info()
+			return
+		}
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable get user at get mod log: ", err)
+			http.Error(w, "unable get user", http.StatusInternalServerError)
+			return
+		}
+		filter.ModeratorID = u.ID
+	}
+
+	actions, err := h.ModLogRepo.GetByCommunity(name, filter)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get mod log from repository: ", err)
+		http.Error(w, "unable get mod log", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(actions)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get mod log: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/notification"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/user"
+	"net/http"
+	"strconv"
+)
+
+type NotificationHandler struct {
+	NotificationRepo notification.NotificationRepo
+	UserRepo         user.UserRepo
+	Logger           *logrus.Entry
+}
+
+type NotificationResponse struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	Actor      *user.User `json:"actor,omitempty"`
+	PostID     string     `json:"post"`
+	CommentID  string     `json:"comment,omitempty"`
+	Score      int        `json:"score,omitempty"`
+	Read       bool       `json:"read"`
+	CreateDate string     `json:"created"`
+}
+
+func NewNotificationHandler(nr notification.NotificationRepo, ur user.UserRepo, log *logrus.Entry) *NotificationHandler {
+	return &NotificationHandler{
+		NotificationRepo: nr,
+		UserRepo:         ur,
+		Logger:           log,
+	}
+}
+
+func (h *NotificationHandler) createResponse(notifications []*notification.Notification) ([]*NotificationResponse, error) {
+	users := make(map[uint]*user.User)
+	resp := make([]*NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		// milestones have no actor, and the actor may have left since
+		actor, ok := users[n.ActorID]
+		if !ok && n.ActorID != 0 {
+			u, err := h.UserRepo.GetByID(n.ActorID)
+			if err != nil && err != user.ErrNoExist {
+				return nil, err
+			}
+			actor = u
+			users[n.ActorID] = u
+		}
+
+		resp = append(resp, &NotificationResponse{
+			ID:         n.ID,
+			Type:       n.Type,
+			Actor:      actor,
+			PostID:     n.PostID,
+			CommentID:  n.CommentID,
+			Score:      n.Score,
+			Read:       n.Read,
+			CreateDate: n.CreateDate,
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *NotificationHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	query := r.URL.Query()
+	filter := notification.Filter{
+		UnreadOnly: query.Get("unread") == "true",
+	}
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": errInvalidLimit.Error(),
+			})
+			if err != nil {
+				h.Logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusInternalServerError,
+				}).Error("unable send json to client at get notifications: ", err)
+				http.Error(w, "unable send json", http.StatusInternalServerError)
+				return
+			}
+
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusBadRequest,
+			}).Info()
+			return
+		}
+	}
+
+	notifications, err := h.NotificationRepo.GetByUser(sess.UserID, filter)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get notifications from repository: ", err)
+		http.Error(w, "unable get notifications", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := h.createResponse(notifications)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable create response to client at get notifications: ", err)
+		http.Error(w, "unable create response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get notifications: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *NotificationHandler) CountUnread(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	count, err := h.NotificationRepo.CountUnread(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable count unread notifications: ", err)
+		http.Error(w, "unable count notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"count": count,
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at count unread notifications: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	err = h.NotificationRepo.MarkRead(vars["id"], sess.UserID)
+	if err == notification.ErrNotExist || err == notification.ErrInvalidID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at mark notification read: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable mark notification as read: ", err)
+		http.Error(w, "unable mark notification", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at mark notification read: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	err = h.NotificationRepo.MarkAllRead(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable mark notifications as read: ", err)
+		http.Error(w, "unable mark notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at mark notifications read: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// notifications, like the search index, must not fail the action that
+// triggered them
+func (h *PostHandler) notify(r *http.Request, n *notification.Notification) {
+	if n.UserID == n.ActorID || n.UserID == user.DeletedID {
+		return
+	}
+
+	err := h.NotificationRepo.Add(n)
+	if err == notification.ErrAlreadyExist {
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}).Error("unable add notification: ", err)
+		return
+	}
+
+	resp := &NotificationResponse{
+		ID:         n.ID,
+		Type:       n.Type,
+		PostID:     n.PostID,
+		CommentID:  n.CommentID,
+		Score:      n.Score,
+		CreateDate: n.CreateDate,
+	}
+	if sess, err := session.GetSessionFromContext(r.Context()); err == nil && n.ActorID == sess.UserID {
+		resp.Actor = &user.User{
+			ID:       sess.UserID,
+			Username: sess.Username,
+		}
+	}
+	h.Publisher.Publish(&event.Event{
+		Type:      event.TypeNotification,
+		PostID:    n.PostID,
+		CommentID: n.CommentID,
+		Data:      resp,
+	}, event.UserTopic(n.UserID))
+}
+
+// notifyMentions skips the user already notified about the reply itself.
+func (h *PostHandler) notifyMentions(r *http.Request, actorID uint, postID string, commentID string, text string, notified uint) {
+	for _, name := range notification.ParseMentions(text) {
+		u, err := h.UserRepo.GetByUsername(name)
+		if err == user.ErrNoExist {
+			continue
+		}
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable get mentioned user: ", err)
+			continue
+		}
+		if u.ID == notified {
+			continue
+		}
+
+		h.notify(r, &notification.Notification{
+			UserID:    u.ID,
+			Type:      notification.TypeMention,
+			ActorID:   actorID,
+			PostID:    postID,
+			CommentID: commentID,
+		})
+	}
+}
+
+func (h *PostHandler) notifyMilestone(r *http.Request, authorID uint, postID string, commentID string, score int) {
+	milestone := notification.ReachedMilestone(score)
+	if milestone == 0 {
+		return
+	}
+
+	h.notify(r, &notification.Notification{
+		UserID:    authorID,
+		Type:      notification.TypeMilestone,
+		PostID:    postID,
+		CommentID: commentID,
+		Score:     milestone,
+		Key:       notification.MilestoneKey(postID, commentID, milestone),
+	})
+}
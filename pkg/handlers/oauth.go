@@ -0,0 +1,908 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/oauth"
+	"github.com/vlasdash/redditclone/internal/session"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	errUnsupportedResponseType = errors.New("response_type must be code")
+	errBadClientSecret         = errors.New("client authentication failed")
+	errCodeMismatch            = errors.New("code wasn't issued to this client or redirect uri")
+	errBadVerifier             = errors.New("code_verifier doesn't match the code_challenge")
+)
+
+type OAuthHandler struct {
+	ClientRepo oauth.ClientRepo
+	CodeRepo   oauth.CodeRepo
+	TokenRepo  oauth.TokenRepo
+	Logger     *logrus.Entry
+}
+
+type ClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirectUris"`
+	Public       bool     `json:"public"`
+}
+
+type ClientResponse struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirectUris"`
+	Public       bool     `json:"public"`
+	CreateDate   string   `json:"created"`
+}
+
+type ConsentResponse struct {
+	ClientID    string          `json:"clientId"`
+	ClientName  string          `json:"clientName"`
+	Scopes      []session.Scope `json:"scopes"`
+	RedirectURI string          `json:"redirectUri"`
+}
+
+type AuthorizeRequest struct {
+	Approve bool `json:"approve"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+type authorizeParams struct {
+	client      *oauth.Client
+	redirectURI string
+	scopes      []session.Scope
+	state       string
+	challenge   string
+}
+
+func NewOAuthHandler(cr oauth.ClientRepo, cdr oauth.CodeRepo, tr oauth.TokenRepo, log *logrus.Entry) *OAuthHandler {
+	return &OAuthHandler{
+		ClientRepo: cr,
+		CodeRepo:   cdr,
+		TokenRepo:  tr,
+		Logger:     log,
+	}
+}
+
+func clientResponse(c *oauth.Client) *ClientResponse {
+	return &ClientResponse{
+		ID:           c.ID,
+		Name:         c.Name,
+		RedirectURIs: c.RedirectURIs,
+		Public:       c.Public(),
+		CreateDate:   c.CreateDate,
+	}
+}
+
+// parseAuthorize reads the query of RFC 6749 section 4.1.1 with the PKCE
+// parameters of RFC 7636, both consent endpoints get the same query.
+func (h *OAuthHandler) parseAuthorize(r *http.Request) (*authorizeParams, error) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" {
+		return nil, errUnsupportedResponseType
+	}
+
+	client, err := h.ClientRepo.Get(q.Get("client_id"))
+	if err != nil {
+		return nil, err
+	}
+	redirectURI := q.Get("redirect_uri")
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, oauth.ErrUnknownRedirect
+	}
+
+	scopes, err := oauth.ParseScope(q.Get("scope"))
+	if err != nil {
+		return nil, err
+	}
+	err = oauth.ValidateChallenge(q.Get("code_challenge"), q.Get("code_challenge_method"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &authorizeParams{
+		client:      client,
+		redirectURI: redirectURI,
+		scopes:      scopes,
+		state:       q.Get("state"),
+		challenge:   q.Get("code_challenge"),
+	}, nil
+}
+
+func isAuthorizeRequestError(err error) bool {
+	switch err {
+	case errUnsupportedResponseType, oauth.ErrNoClient, oauth.ErrUnknownRedirect,
+		session.ErrBadScope, session.ErrNoScopes, oauth.ErrBadCodeChallenge:
+		return true
+	}
+
+	return false
+}
+
+func redirectWith(uri string, values url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	q := u.Query()
+	for k, v := range values {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// authenticateClient takes the credentials from basic auth or from the form,
+// public clients only send client_id.
+func (h *OAuthHandler) authenticateClient(r *http.Request) (*oauth.Client, error) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	client, err := h.ClientRepo.Get(id)
+	if err == oauth.ErrNoClient {
+		return nil, errBadClientSecret
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !client.CheckSecret(secret) {
+		return nil, errBadClientSecret
+	}
+
+	return client, nil
+}
+
+// tokenError answers in the format of RFC 6749 section 5.2, OAuth clients
+// don't understand {"message": ...}.
+func (h *OAuthHandler) tokenError(w http.ResponseWriter, r *http.Request, status int, code string, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":             code,
+		"error_description": description,
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at oauth token: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": status,
+	}).Info()
+}
+
+func (h *OAuthHandler) exchangeCode(r *http.Request, client *oauth.Client) (*oauth.TokenPair, error) {
+	code, err := h.CodeRepo.Consume(r.PostForm.Get("code"))
+	if err != nil {
+		return nil, err
+	}
+	if code.ClientID != client.ID || code.RedirectURI != r.PostForm.Get("redirect_uri") {
+		return nil, errCodeMismatch
+	}
+	if !oauth.VerifyPKCE(r.PostForm.Get("code_verifier"), code.Challenge) {
+		return nil, errBadVerifier
+	}
+
+	return h.TokenRepo.Issue(&oauth.Grant{
+		ClientID: client.ID,
+		UserID:   code.UserID,
+		Username: code.Username,
+		Scopes:   code.Scopes,
+	})
+}
+
+func (h *OAuthHandler) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at register oauth client: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at register oauth client: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &ClientRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at register oauth client: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	err = oauth.ValidateClient(req.Name, req.RedirectURIs)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at register oauth client: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	client, secret, err := h.ClientRepo.Add(sess.UserID, req.Name, req.RedirectURIs, req.Public)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable add oauth client to repository: ", err)
+		http.Error(w, "unable register client", http.StatusInternalServerError)
+		return
+	}
+
+	// the secret is shown once, only its hash is kept
+	resp := map[string]interface{}{
+		"client": clientResponse(client),
+	}
+	if secret != "" {
+		resp["secret"] = secret
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at register oauth client: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusCreated,
+	}).Info()
+}
+
+func (h *OAuthHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	clients, err := h.ClientRepo.GetByOwner(sess.UserID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get oauth clients from repository: ", err)
+		http.Error(w, "unable get clients", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]*ClientResponse, 0, len(clients))
+	for _, c := range clients {
+		resp = append(resp, clientResponse(c))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at get oauth clients: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *OAuthHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+	vars := mux.Vars(r)
+
+	err = h.ClientRepo.Delete(sess.UserID, vars["id"])
+	if err == oauth.ErrNoClient {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at delete oauth client: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable delete oauth client from repository: ", err)
+		http.Error(w, "unable delete client", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.TokenRepo.DeleteByClient(vars["id"])
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable revoke tokens of deleted client: ", err)
+		http.Error(w, "unable delete client", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at delete oauth client: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// GetAuthorize describes the request, so the frontend can ask the user for
+// consent.
+func (h *OAuthHandler) GetAuthorize(w http.ResponseWriter, r *http.Request) {
+	_, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	params, err := h.parseAuthorize(r)
+	if isAuthorizeRequestError(err) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at oauth authorize: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable check oauth authorize request: ", err)
+		http.Error(w, "unable check request", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(&ConsentResponse{
+		ClientID:    params.client.ID,
+		ClientName:  params.client.Name,
+		Scopes:      params.scopes,
+		RedirectURI: params.redirectURI,
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at oauth authorize: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// Authorize records the decision of the user and returns where to send the
+// browser, with a code or with error=access_denied.
+func (h *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at oauth authorize: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at oauth authorize: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &AuthorizeRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at oauth authorize: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	params, err := h.parseAuthorize(r)
+	if isAuthorizeRequestError(err) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at oauth authorize: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable check oauth authorize request: ", err)
+		http.Error(w, "unable check request", http.StatusInternalServerError)
+		return
+	}
+
+	values := url.Values{}
+	if params.state != "" {
+		values.Set("state", params.state)
+	}
+	if !req.Approve {
+		values.Set("error", "access_denied")
+	} else {
+		code, err := h.CodeRepo.Add(&oauth.Code{
+			ClientID:    params.client.ID,
+			UserID:      sess.UserID,
+			Username:    sess.Username,
+			RedirectURI: params.redirectURI,
+			Scopes:      params.scopes,
+			Challenge:   params.challenge,
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable add authorization code to repository: ", err)
+			http.Error(w, "unable authorize client", http.StatusInternalServerError)
+			return
+		}
+		values.Set("code", code)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"redirect": redirectWith(params.redirectURI, values),
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at oauth authorize: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// Token is the endpoint of RFC 6749 section 3.2 for the authorization_code
+// and refresh_token grants.
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.tokenError(w, r, http.StatusBadRequest, "invalid_request", "unable parse form")
+		return
+	}
+
+	client, err := h.authenticateClient(r)
+	if err == errBadClientSecret {
+		h.tokenError(w, r, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get oauth client: ", err)
+		http.Error(w, "unable authenticate client", http.StatusInternalServerError)
+		return
+	}
+
+	var pair *oauth.TokenPair
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		pair, err = h.exchangeCode(r, client)
+	case "refresh_token":
+		pair, err = h.TokenRepo.Refresh(r.PostForm.Get("refresh_token"), client.ID)
+	default:
+		h.tokenError(w, r, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be authorization_code or refresh_token")
+		return
+	}
+	switch err {
+	case nil:
+	case oauth.ErrBadCode, errCodeMismatch, errBadVerifier, session.ErrBadToken, session.ErrTokenExpired:
+		h.tokenError(w, r, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	default:
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable issue oauth tokens: ", err)
+		http.Error(w, "unable issue tokens", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(&TokenResponse{
+		AccessToken:  pair.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    pair.ExpiresIn,
+		RefreshToken: pair.RefreshToken,
+		Scope:        oauth.FormatScope(pair.Scopes),
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at oauth token: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// Revoke follows RFC 7009, it answers 200 for unknown tokens too.
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.tokenError(w, r, http.StatusBadRequest, "invalid_request", "unable parse form")
+		return
+	}
+
+	client, err := h.authenticateClient(r)
+	if err == errBadClientSecret {
+		h.tokenError(w, r, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get oauth client: ", err)
+		http.Error(w, "unable authenticate client", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.TokenRepo.Revoke(r.PostForm.Get("token"), client.ID)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable revoke oauth token: ", err)
+		http.Error(w, "unable revoke token", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+// RevokeAuthorization lets the user take the access back from a client.
+func (h *OAuthHandler) RevokeAuthorization(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+	vars := mux.Vars(r)
+
+	err = h.TokenRepo.RevokeClient(sess.UserID, vars["client_id"])
+	if err == oauth.ErrNoGrant {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at revoke oauth authorization: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable revoke oauth tokens: ", err)
+		http.Error(w, "unable revoke authorization", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at revoke oauth authorization: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/vlasdash/redditclone/internal/post"
+	"net/http"
+	"strconv"
+)
+
+var errInvalidLimit = errors.New("limit must be a positive number")
+
+type PageResponse struct {
+	Posts  []*PostResponse `json:"posts"`
+	Pinned []*PostResponse `json:"pinned,omitempty"`
+	Next   string          `json:"next,omitempty"`
+}
+
+func parsePageOptions(r *http.Request) (opts post.PageOptions, paginated bool, err error) {
+	query := r.URL.Query()
+	limit := query.Get("limit")
+	opts.After = query.Get("after")
+	sortMode := query.Get("sort")
+	period := query.Get("t")
+	if limit == "" && opts.After == "" && sortMode == "" && period == "" {
+		return opts, false, nil
+	}
+
+	opts.Sort, err = post.ParseSort(sortMode)
+	if err != nil {
+		return opts, true, err
+	}
+	opts.Period, err = post.ParsePeriod(period)
+	if err != nil {
+		return opts, true, err
+	}
+
+	opts.Limit = post.DefaultPageLimit
+	if limit != "" {
+		opts.Limit, err = strconv.Atoi(limit)
+		if err != nil || opts.Limit <= 0 {
+			return opts, true, errInvalidLimit
+		}
+	}
+	if opts.Limit > post.MaxPageLimit {
+		opts.Limit = post.MaxPageLimit
+	}
+
+	return opts, true, nil
+}
+
+func (h *PostHandler) sendPage(w http.ResponseWriter, r *http.Request, page *post.Page, err error) {
+	if err == post.ErrInvalidCursor || err == post.ErrInvalidSort || err == post.ErrInvalidPeriod || err == errInvalidLimit {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get page")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get posts from server", "unable get page of posts from repository: ", err)
+		return
+	}
+
+	posts, err := h.createResponse(page.Posts, DefaultCommentDepth, viewerID(r))
+	var pinned []*PostResponse
+	if err == nil && len(page.Pinned) != 0 {
+		pinned, err = h.createResponse(page.Pinned, DefaultCommentDepth, viewerID(r))
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at get page: ", err)
+		return
+	}
+
+	sendJSON(w, r, h.Logger, http.StatusOK, &PageResponse{
+		Posts:  posts,
+		Pinned: pinned,
+		Next:   page.Next,
+	}, "get page")
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/limiter"
+	"github.com/vlasdash/redditclone/internal/mail"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/user"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const resetMailSubject = "Password reset"
+
+type PasswordResetHandler struct {
+	UserRepo    user.UserRepo
+	ResetRepo   user.ResetRepo
+	SessionRepo session.SessionRepo
+	RefreshRepo session.RefreshRepo
+	Hasher      user.PasswordHasher
+	Mailer      mail.Mailer
+	// ResetURL is the page of the frontend, the token is appended to it
+	ResetURL string
+	Logger   *logrus.Entry
+	// ResetLimiter counts the requests by email and address
+	ResetLimiter *limiter.Limiter
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func NewPasswordResetHandler(ur user.UserRepo, rsr user.ResetRepo, sr session.SessionRepo, rr session.RefreshRepo, ph user.PasswordHasher, m mail.Mailer, resetURL string, log *logrus.Entry, rl *limiter.Limiter) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		UserRepo:     ur,
+		ResetRepo:    rsr,
+		SessionRepo:  sr,
+		RefreshRepo:  rr,
+		Hasher:       ph,
+		Mailer:       m,
+		ResetURL:     resetURL,
+		Logger:       log,
+		ResetLimiter: rl,
+	}
+}
+
+func resetMail(u *user.User, link string) *mail.Message {
+	return &mail.Message{
+		To:      u.Email,
+		Subject: resetMailSubject,
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nsomebody asked to reset the password of your account. Follow the link to choose a new one:\n\n%s\n\nThe link works only once and expires soon. If it wasn`t you, just ignore this email.",
+			u.Username,
+			link,
+		),
+	}
+}
+
+// sendReset issues a reset token for u and emails the link, failures are only
+// logged since the client has already got its answer.
+func (h *PasswordResetHandler) sendReset(u *user.User, logger *logrus.Entry) {
+	token, err := h.ResetRepo.Add(u.ID)
+	if err != nil {
+		logger.Error("unable issue reset token: ", err)
+		return
+	}
+
+	err = h.Mailer.Send(resetMail(u, h.ResetURL+token))
+	if err != nil {
+		logger.Error("unable send reset email: ", err)
+	}
+}
+
+// Forgot answers the same way whether the email is known or not, so it can't be
+// used to find out who is registered.
+func (h *PasswordResetHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at forgot password: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at forgot password: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &ForgotPasswordRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at forgot password: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	err = user.ValidateEmail(req.Email)
+	if err == nil && req.Email == "" {
+		err = user.ErrInvalidEmail
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at forgot password: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	// unknown emails are counted too, the limit mustn't tell them apart
+	if !reserveAttempt(w, r, h.Logger, h.ResetLimiter, limiter.Email(req.Email), limiter.IP(clientFromRequest(r).IP)) {
+		return
+	}
+
+	u, err := h.UserRepo.GetByEmail(req.Email)
+	if err != nil && err != user.ErrNoExist {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get user from db: ", err)
+		http.Error(w, "unable get user from db", http.StatusInternalServerError)
+		return
+	}
+
+	// the token and the email are made in the background, the response must not
+	// take longer or fail only for registered emails
+	if err == nil {
+		go h.sendReset(u, h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at forgot password: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func (h *PasswordResetHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at reset password: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at reset password: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &ResetPasswordRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at reset password: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	if req.Password == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": errEmptyPassword.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at reset password: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	userID, err := h.ResetRepo.Consume(req.Token)
+	if err == user.ErrBadResetToken {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at reset password: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable consume reset token: ", err)
+		http.Error(w, "unable reset password", http.StatusInternalServerError)
+		return
+	}
+
+	passwordHash, err := h.Hasher.GetHashPassword(req.Password)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable take hash of password at reset password: ", err)
+		http.Error(w, "unable process hash", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.UserRepo.UpdatePassword(userID, passwordHash)
+	if err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": user.ErrBadResetToken.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at reset password: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable update password: ", err)
+		http.Error(w, "unable update password", http.StatusInternalServerError)
+		return
+	}
+
+	// whoever knew the old password must not stay logged in
+	err = h.SessionRepo.DeleteAll(userID)
+	if err == nil {
+		err = h.RefreshRepo.DeleteAll(userID)
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable revoke sessions at reset password: ", err)
+		http.Error(w, "unable revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at reset password: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
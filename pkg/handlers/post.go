@@ -5,7 +5,11 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/notification"
 	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/search"
 	"github.com/vlasdash/redditclone/internal/session"
 	"github.com/vlasdash/redditclone/internal/user"
 	"io/ioutil"
@@ -28,13 +32,23 @@ type PostResponse struct {
 	Votes            []*post.Vote       `json:"votes"`
 	Comments         []*CommentResponse `json:"comments"`
 	Author           *user.User         `json:"author"`
+	Edited           string             `json:"edited,omitempty"`
+	Locked           bool               `json:"locked,omitempty"`
+	Pinned           bool               `json:"pinned,omitempty"`
 }
 
 type CommentResponse struct {
-	ID         string     `json:"id"`
-	Author     *user.User `json:"author"`
-	CreateDate string     `json:"created"`
-	Body       string     `json:"body"`
+	ID         string             `json:"id"`
+	Author     *user.User         `json:"author"`
+	CreateDate string             `json:"created"`
+	Body       string             `json:"body"`
+	ParentID   string             `json:"parent_id,omitempty"`
+	Score      int                `json:"score"`
+	Votes      []*comment.Vote    `json:"votes"`
+	UserVote   int                `json:"userVote"`
+	Replies    []*CommentResponse `json:"replies,omitempty"`
+	More       string             `json:"more,omitempty"`
+	Edited     string             `json:"edited,omitempty"`
 }
 
 type CommentRequest struct {
@@ -42,22 +56,111 @@ type CommentRequest struct {
 }
 
 type PostHandler struct {
-	PostRepo    post.PostRepo
-	UserRepo    user.UserRepo
-	CommentRepo comment.CommentRepo
-	Logger      *logrus.Entry
+	PostRepo         post.PostRepo
+	UserRepo         user.UserRepo
+	CommentRepo      comment.CommentRepo
+	CommunityRepo    community.CommunityRepo
+	SubscriptionRepo community.SubscriptionRepo
+	Searcher         search.Searcher
+	NotificationRepo notification.NotificationRepo
+	Publisher        event.Publisher
+	Logger           *logrus.Entry
 }
 
-func NewPostHandler(pr post.PostRepo, ur user.UserRepo, cr comment.CommentRepo, log *logrus.Entry) *PostHandler {
+func NewPostHandler(pr post.PostRepo, ur user.UserRepo, cr comment.CommentRepo, log *logrus.Entry, comr community.CommunityRepo, sr community.SubscriptionRepo, s search.Searcher, nr notification.NotificationRepo, pub event.Publisher) *PostHandler {
 	return &PostHandler{
-		PostRepo:    pr,
-		CommentRepo: cr,
-		UserRepo:    ur,
-		Logger:      log,
+		PostRepo:         pr,
+		CommentRepo:      cr,
+		UserRepo:         ur,
+		Logger:           log,
+		CommunityRepo:    comr,
+		SubscriptionRepo: sr,
+		Searcher:         s,
+		NotificationRepo: nr,
+		Publisher:        pub,
 	}
 }
 
-func (h *PostHandler) createResponse(posts []*post.Post) ([]*PostResponse, error) {
+func (h *PostHandler) checkCanComment(p *post.Post, userID uint) error {
+	if p.Locked {
+		return post.ErrLocked
+	}
+
+	// posts from before communities existed have no community to be banned from
+	c, err := h.CommunityRepo.GetByName(p.Category)
+	if err == community.ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if c.IsBanned(userID) {
+		return community.ErrBanned
+	}
+
+	return nil
+}
+
+func viewerID(r *http.Request) uint {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		return 0
+	}
+
+	return sess.UserID
+}
+
+func (h *PostHandler) commentResponse(c *comment.Comment, viewer uint) (*CommentResponse, error) {
+	commentResp := &CommentResponse{
+		ID:         c.ID,
+		CreateDate: c.CreateDate,
+		Body:       c.Body,
+		ParentID:   c.ParentID,
+		Score:      c.UpvotesCount - c.DownvotesCount,
+		Votes:      c.Votes,
+		Edited:     c.Edited,
+	}
+	for _, v := range c.Votes {
+		if v.UserID == viewer {
+			commentResp.UserVote = v.Value
+			break
+		}
+	}
+
+	var err error
+	commentResp.Author, err = authorByID(h.UserRepo, c.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	commentResp.Author.Password = ""
+
+	return commentResp, nil
+}
+
+func (h *PostHandler) getComments(p *post.Post, viewer uint) ([]*CommentResponse, error) {
+	comments := make([]*CommentResponse, 0, len(p.CommentIDs))
+	for _, id := range p.CommentIDs {
+		c, err := h.CommentRepo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		commentResp, err := h.commentResponse(c, viewer)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, commentResp)
+	}
+
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreateDate < comments[j].CreateDate
+	})
+
+	return comments, nil
+}
+
+func (h *PostHandler) createResponse(posts []*post.Post, depth int, viewer uint) ([]*PostResponse, error) {
 	resp := make([]*PostResponse, 0, len(posts))
 
 	for _, p := range posts {
@@ -71,6 +174,9 @@ func (h *PostHandler) createResponse(posts []*post.Post) ([]*PostResponse, error
 			Type:       p.Type,
 			Views:      p.Views,
 			Votes:      p.Votes,
+			Edited:     p.Edited,
+			Locked:     p.Locked,
+			Pinned:     p.Pinned,
 		}
 
 		r.Score = p.UpvotesCount - p.DownvotesCount
@@ -80,33 +186,13 @@ func (h *PostHandler) createResponse(posts []*post.Post) ([]*PostResponse, error
 			r.UpvotePercentage = 0
 		}
 
-		r.Comments = make([]*CommentResponse, 0, len(p.CommentIDs))
-		for _, id := range p.CommentIDs {
-			c, err := h.CommentRepo.GetByID(id)
-			if err != nil {
-				return nil, err
-			}
-
-			commentResp := &CommentResponse{
-				ID:         c.ID,
-				CreateDate: c.CreateDate,
-				Body:       c.Body,
-			}
-			commentResp.Author, err = h.UserRepo.GetByID(c.AuthorID)
-			if err != nil {
-				return nil, err
-			}
-			commentResp.Author.Password = ""
-
-			r.Comments = append(r.Comments, commentResp)
+		comments, err := h.getComments(p, viewer)
+		if err != nil {
+			return nil, err
 		}
+		r.Comments = buildCommentTree(comments, "", depth)
 
-		sort.SliceStable(r.Comments, func(i, j int) bool {
-			return r.Comments[i].CreateDate < r.Comments[j].CreateDate
-		})
-
-		var err error
-		r.Author, err = h.UserRepo.GetByID(p.AuthorID)
+		r.Author, err = authorByID(h.UserRepo, p.AuthorID)
 		if err != nil {
 			return nil, err
 		}
@@ -119,62 +205,60 @@ func (h *PostHandler) createResponse(posts []*post.Post) ([]*PostResponse, error
 }
 
 func (h *PostHandler) GetList(w http.ResponseWriter, r *http.Request) {
-	posts, err := h.PostRepo.GetAll()
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable get posts from memory repository: ", err)
-
-		http.Error(w, "unable get posts from server", http.StatusInternalServerError)
+	opts, paginated, err := parsePageOptions(r)
+	if paginated {
+		var page *post.Page
+		if err == nil {
+			page, err = h.PostRepo.GetPage(opts)
+		}
+
+		h.sendPage(w, r, page, err)
 		return
 	}
 
-	resp, err := h.createResponse(posts)
+	posts, err := h.PostRepo.GetAll()
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at get post all: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable get posts from server", "unable get posts from memory repository: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at get all posts: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at get post all: ", err)
 		return
 	}
 
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusOK, resp, "get all posts")
+}
+
+func (h *PostHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	opts, paginated, err := parsePageOptions(r)
+	if !paginated {
+		opts.Limit = post.DefaultPageLimit
+	}
+
+	// anonymous users and users without subscriptions get the default communities
+	categories := community.DefaultNames
+	if viewer := viewerID(r); err == nil && viewer != 0 {
+		var names []string
+		names, err = h.SubscriptionRepo.GetByUser(viewer)
+		if len(names) != 0 {
+			categories = names
+		}
+	}
+
+	var page *post.Page
+	if err == nil {
+		page, err = h.PostRepo.GetPageByCategories(categories, opts)
+	}
+
+	h.sendPage(w, r, page, err)
 }
 
 func (h *PostHandler) Add(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetSessionFromContext(r.Context())
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusUnauthorized,
-		}).Info()
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
 		http.Redirect(w, r, "/api/login", http.StatusUnauthorized)
 		return
 	}
@@ -192,91 +276,70 @@ func (h *PostHandler) Add(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable read body at add post: ", err)
-		http.Error(w, "unable read body", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable read body", "unable read body at add post: ", err)
 		return
 	}
 
 	req := &post.Post{}
 	err = json.Unmarshal(body, req)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable unmarshal json from client at add post: ", err)
-		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "can't unmarshal request from json", "unable unmarshal json from client at add post: ", err)
 		return
 	}
 
 	req.AuthorID = sess.UserID
 
+	req.Category, err = community.NormalizeName(req.Category)
+	if err != nil {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add post")
+		return
+	}
+
+	c, err := h.CommunityRepo.GetByName(req.Category)
+	if err == community.ErrNotExist {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add post")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get community", "unable get community at add post: ", err)
+		return
+	}
+
+	err = c.CanPost(sess.UserID, req.Type)
+	if err != nil {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add post")
+		return
+	}
+
 	id, err := h.PostRepo.Create(req)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create post: ", err)
-		http.Error(w, "unable create post", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create post", "unable create post: ", err)
 		return
 	}
 
 	viewsUpdate := 0
 	p, err := h.PostRepo.GetByID(id, viewsUpdate)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create post: ", err)
-		http.Error(w, "unable create post", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create post", "unable create post: ", err)
 		return
 	}
 
+	logIndexError(h.Logger, r, h.Searcher.Index(postDocument(p)))
+	h.notifyMentions(r, sess.UserID, p.ID, "", p.Title+" "+p.Text, 0)
+	publishPost(h.Publisher, p, &event.Event{
+		Type:   event.TypePostAdded,
+		PostID: p.ID,
+	})
+
 	posts := make([]*post.Post, 0, 1)
 	posts = append(posts, p)
-	resp, err := h.createResponse(posts)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil || len(resp) != 1 {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at add post: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at add post: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp[0])
-
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at add post: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
-		return
-	}
-
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusCreated,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusCreated, resp[0], "add post")
 }
 
 func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
@@ -284,135 +347,71 @@ func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 	postID := vars["id"]
 	viewsUpdate := 1
 
+	depth, err := parseCommentDepth(r)
+	if err != nil {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get post")
+		return
+	}
+
 	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
 	if err == post.ErrNotExist {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at get post: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
-
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusBadRequest,
-		}).Info()
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get post")
 		return
 	}
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable get post from repository: ", err)
-		http.Error(w, "unable get post get post from repository", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable get post get post from repository", "unable get post from repository: ", err)
 		return
 	}
 
 	posts := make([]*post.Post, 0, 1)
 	posts = append(posts, p)
-	resp, err := h.createResponse(posts)
+	resp, err := h.createResponse(posts, depth, viewerID(r))
 	if err != nil || len(resp) != 1 {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at get post: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp[0])
-
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at get post: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at get post: ", err)
 		return
 	}
 
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusOK, resp[0], "get post")
 }
 
 func (h *PostHandler) GetByCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	category := vars["category"]
 
-	posts, err := h.PostRepo.GetByCategory(category)
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable get posts from memory repository: ", err)
-
-		http.Error(w, "unable get posts from server:", http.StatusInternalServerError)
+	opts, paginated, err := parsePageOptions(r)
+	if paginated {
+		var page *post.Page
+		if err == nil {
+			page, err = h.PostRepo.GetPageByCategory(category, opts)
+		}
+
+		h.sendPage(w, r, page, err)
 		return
 	}
 
-	resp, err := h.createResponse(posts)
+	posts, err := h.PostRepo.GetByCategory(category)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at get post by categore: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable get posts from server:", "unable get posts from memory repository: ", err)
 		return
 	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Pinned && !posts[j].Pinned
+	})
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at get posts by category: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at get post by categore: ", err)
 		return
 	}
+
+	sendJSON(w, r, h.Logger, http.StatusOK, resp, "get posts by category")
 }
 
 func (h *PostHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetSessionFromContext(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusUnauthorized,
-		}).Info()
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
 		return
 	}
 
@@ -432,121 +431,87 @@ func (h *PostHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable read body at add comment: ", err)
-		http.Error(w, "unable read body", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable read body", "unable read body at add comment: ", err)
 		return
 	}
 
 	req := &CommentRequest{}
 	err = json.Unmarshal(body, req)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable unmarshal json from client at add comment: ", err)
-		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "can't unmarshal request from json", "unable unmarshal json from client at add comment: ", err)
+		return
+	}
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add comment")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable get post at add comment: ", err)
+		return
+	}
+
+	err = h.checkCanComment(p, sess.UserID)
+	if err == post.ErrLocked || err == community.ErrBanned {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add comment")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable check post", "unable check post at add comment: ", err)
 		return
 	}
 
 	commentID, err := h.CommentRepo.Add(sess.UserID, req.Body)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable add comment to bd: ", err)
-		http.Error(w, "unable add comment to bd", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable add comment to bd", "unable add comment to bd: ", err)
 		return
 	}
 
 	err = h.PostRepo.AddComment(postID, commentID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at add comment: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "add comment")
 		return
 	}
 
-	viewsUpdate := 0
-	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	logIndexError(h.Logger, r, h.Searcher.Index(commentDocument(p, commentID, sess.UserID, req.Body)))
+	h.notify(r, &notification.Notification{
+		UserID:    p.AuthorID,
+		Type:      notification.TypePostReply,
+		ActorID:   sess.UserID,
+		PostID:    postID,
+		CommentID: commentID,
+	})
+	h.notifyMentions(r, sess.UserID, postID, commentID, req.Body, p.AuthorID)
+	publishPost(h.Publisher, p, &event.Event{
+		Type:      event.TypeCommentAdded,
+		PostID:    postID,
+		CommentID: commentID,
+	})
+
+	p, err = h.PostRepo.GetByID(postID, viewsUpdate)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable add comment: ", err)
-		http.Error(w, "unable get post by id", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable get post by id", "unable add comment: ", err)
 		return
 	}
 
 	posts := make([]*post.Post, 0, 1)
 	posts = append(posts, p)
-	resp, err := h.createResponse(posts)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil || len(resp) != 1 {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at add comment: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at add comment: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp[0])
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at add comment: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
-		return
-	}
-
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusCreated,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusCreated, resp[0], "add comment")
 }
 
 func (h *PostHandler) Upvote(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetSessionFromContext(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusUnauthorized,
-		}).Info()
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
 		return
 	}
 
@@ -555,94 +520,48 @@ func (h *PostHandler) Upvote(w http.ResponseWriter, r *http.Request) {
 
 	err = h.PostRepo.Upvote(postID, sess.UserID)
 	if err == post.ErrNotExist {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at vote: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "vote")
 		return
 	}
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable upvote: ", err)
-		http.Error(w, "unable upvote", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable upvote", "unable upvote: ", err)
 		return
 	}
 
 	viewsUpdate := 0
 	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable upvote: ", err)
-		http.Error(w, "unable upvote", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable upvote", "unable upvote: ", err)
 		return
 	}
 
+	h.notifyMilestone(r, p.AuthorID, p.ID, "", p.UpvotesCount-p.DownvotesCount)
+	publishPost(h.Publisher, p, &event.Event{
+		Type:   event.TypePostVoted,
+		PostID: p.ID,
+		Data: &event.VoteData{
+			Score:          p.UpvotesCount - p.DownvotesCount,
+			UpvotesCount:   p.UpvotesCount,
+			DownvotesCount: p.DownvotesCount,
+		},
+	})
+
 	posts := make([]*post.Post, 0, 1)
 	posts = append(posts, p)
-	resp, err := h.createResponse(posts)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil || len(resp) != 1 {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at vote: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at vote: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp[0])
-
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-		}).Error("unable send json to client at vote: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
-		return
-	}
-
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusOK, resp[0], "vote")
 }
 
 func (h *PostHandler) Downvote(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetSessionFromContext(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusUnauthorized,
-		}).Info()
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
 		return
 	}
 
@@ -651,101 +570,48 @@ func (h *PostHandler) Downvote(w http.ResponseWriter, r *http.Request) {
 
 	err = h.PostRepo.Downvote(postID, sess.UserID)
 	if err == post.ErrNotExist {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at vote: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
-
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusBadRequest,
-		}).Info()
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "vote")
 		return
 	}
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable downvote: ", err)
-		http.Error(w, "unable downvote", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable downvote", "unable downvote: ", err)
 		return
 	}
 
 	viewsUpdate := 0
 	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable downvote: ", err)
-		http.Error(w, "unable downvote", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable downvote", "unable downvote: ", err)
 		return
 	}
 
+	h.notifyMilestone(r, p.AuthorID, p.ID, "", p.UpvotesCount-p.DownvotesCount)
+	publishPost(h.Publisher, p, &event.Event{
+		Type:   event.TypePostVoted,
+		PostID: p.ID,
+		Data: &event.VoteData{
+			Score:          p.UpvotesCount - p.DownvotesCount,
+			UpvotesCount:   p.UpvotesCount,
+			DownvotesCount: p.DownvotesCount,
+		},
+	})
+
 	posts := make([]*post.Post, 0, 1)
 	posts = append(posts, p)
-	resp, err := h.createResponse(posts)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil || len(resp) != 1 {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at vote: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at vote: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp[0])
-
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at vote: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
-		return
-	}
-
-	h.Logger.WithFields(logrus.Fields{
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusOK, resp[0], "vote")
 }
 
 func (h *PostHandler) Unvote(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetSessionFromContext(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusUnauthorized,
-		}).Info()
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
 		return
 	}
 
@@ -754,171 +620,79 @@ func (h *PostHandler) Unvote(w http.ResponseWriter, r *http.Request) {
 
 	err = h.PostRepo.Unvote(postID, sess.UserID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at vote: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
-
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusBadRequest,
-		}).Info()
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "vote")
 		return
 	}
 
 	viewsUpdate := 0
 	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable unvote: ", err)
-		http.Error(w, "unable unvote", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable unvote", "unable unvote: ", err)
 		return
 	}
 
+	h.notifyMilestone(r, p.AuthorID, p.ID, "", p.UpvotesCount-p.DownvotesCount)
+	publishPost(h.Publisher, p, &event.Event{
+		Type:   event.TypePostVoted,
+		PostID: p.ID,
+		Data: &event.VoteData{
+			Score:          p.UpvotesCount - p.DownvotesCount,
+			UpvotesCount:   p.UpvotesCount,
+			DownvotesCount: p.DownvotesCount,
+		},
+	})
+
 	posts := make([]*post.Post, 0, 1)
 	posts = append(posts, p)
-	resp, err := h.createResponse(posts)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil || len(resp) != 1 {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at unvote: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at unvote: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp[0])
-
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at vote: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
-		return
-	}
-
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusOK, resp[0], "vote")
 }
 
 func (h *PostHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetSessionFromContext(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusUnauthorized,
-		}).Info()
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
 		return
 	}
 
 	vars := mux.Vars(r)
 	postID := vars["id"]
 
-	err = h.PostRepo.Delete(postID, sess.UserID)
+	// the post is read first to know which listings to tell about the removal
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == nil {
+		err = h.PostRepo.Delete(postID, sess.UserID)
+	}
 	if err == post.ErrNotExist || err == post.ErrNoAccess {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at delete post: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
-
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusBadRequest,
-		}).Info()
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "delete post")
 		return
 	}
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable delete post: ", err)
-		http.Error(w, "unable delete post", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable delete post", "unable delete post: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "success",
+	logIndexError(h.Logger, r, h.Searcher.RemoveByPost(postID))
+	publishPost(h.Publisher, p, &event.Event{
+		Type:   event.TypePostDeleted,
+		PostID: postID,
 	})
 
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at add comment: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
-		return
-	}
-
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendMessage(w, r, h.Logger, http.StatusOK, "success", "add comment")
 }
 
 func (h *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetSessionFromContext(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusUnauthorized,
-		}).Info()
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
 		return
 	}
 
@@ -928,111 +702,44 @@ func (h *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 
 	err = h.CommentRepo.Delete(commentID, sess.UserID)
 	if err == comment.ErrNotExist || err == comment.ErrNoAccess {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at delete comment: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "delete comment")
 		return
 	}
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at delete comment: ", err)
-		http.Error(w, "unable delete comment", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable delete comment", "unable send json to client at delete comment: ", err)
 		return
 	}
 
 	err = h.PostRepo.DeleteComment(postID, commentID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-
-		err = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": err.Error(),
-		})
-		if err != nil {
-			h.Logger.WithFields(logrus.Fields{
-				"method":      r.Method,
-				"remote_addr": r.RemoteAddr,
-				"url":         r.URL.Path,
-				"status_code": http.StatusInternalServerError,
-			}).Error("unable send json to client at delete comment: ", err)
-			http.Error(w, "unable send json", http.StatusInternalServerError)
-			return
-		}
-
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusBadRequest,
-		}).Info()
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "delete comment")
 		return
 	}
 
+	logIndexError(h.Logger, r, h.Searcher.Remove(search.TypeComment, commentID))
+
 	viewsUpdate := 0
 	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable delete comment: ", err)
-		http.Error(w, "unable delete comment", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable delete comment", "unable delete comment: ", err)
 		return
 	}
 
+	publishPost(h.Publisher, p, &event.Event{
+		Type:      event.TypeCommentDeleted,
+		PostID:    postID,
+		CommentID: commentID,
+	})
+
 	posts := make([]*post.Post, 0, 1)
 	posts = append(posts, p)
-	resp, err := h.createResponse(posts)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil || len(resp) != 1 {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at delete comment: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at delete comment: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp[0])
-
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at add comment: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
-		return
-	}
-
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusOK, resp[0], "add comment")
 }
 
 func (h *PostHandler) GetByUsername(w http.ResponseWriter, r *http.Request) {
@@ -1041,59 +748,32 @@ func (h *PostHandler) GetByUsername(w http.ResponseWriter, r *http.Request) {
 
 	u, err := h.UserRepo.GetByUsername(username)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable get user from db: ", err)
-		http.Error(w, "unable get user from db", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable get user from db", "unable get user from db: ", err)
 		return
 	}
 
-	posts, err := h.PostRepo.GetByAuthor(u.ID)
-	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable get posts from memory repository: ", err)
-
-		http.Error(w, "unable get posts from server", http.StatusInternalServerError)
+	opts, paginated, err := parsePageOptions(r)
+	if paginated {
+		var page *post.Page
+		if err == nil {
+			page, err = h.PostRepo.GetPageByAuthor(u.ID, opts)
+		}
+
+		h.sendPage(w, r, page, err)
 		return
 	}
 
-	resp, err := h.createResponse(posts)
+	posts, err := h.PostRepo.GetByAuthor(u.ID)
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable create response to client at unvote: ", err)
-		http.Error(w, "unable create response", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable get posts from server", "unable get posts from memory repository: ", err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, viewerID(r))
 	if err != nil {
-		h.Logger.WithFields(logrus.Fields{
-			"method":      r.Method,
-			"remote_addr": r.RemoteAddr,
-			"url":         r.URL.Path,
-			"status_code": http.StatusInternalServerError,
-		}).Error("unable send json to client at get posts by username: ", err)
-		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at unvote: ", err)
 		return
 	}
 
-	h.Logger.WithFields(logrus.Fields{
-		"method":      r.Method,
-		"remote_addr": r.RemoteAddr,
-		"url":         r.URL.Path,
-		"status_code": http.StatusOK,
-	}).Info()
+	sendJSON(w, r, h.Logger, http.StatusOK, resp, "get posts by username")
 }
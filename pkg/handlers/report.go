@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"io/ioutil"
+	"net/http"
+)
+
+type ReportHandler struct {
+	ReportRepo community.ReportRepo
+	PostRepo   post.PostRepo
+	Logger     *logrus.Entry
+}
+
+type ReportRequest struct {
+	Reason string `json:"reason"`
+}
+
+func NewReportHandler(rr community.ReportRepo, pr post.PostRepo, log *logrus.Entry) *ReportHandler {
+	return &ReportHandler{
+		ReportRepo: rr,
+		PostRepo:   pr,
+		Logger:     log,
+	}
+}
+
+func (h *ReportHandler) report(w http.ResponseWriter, r *http.Request, targetType string) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at report: ", err)
+		}
+	}(r, h.Logger)
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable read body at report: ", err)
+		http.Error(w, "unable read body", http.StatusInternalServerError)
+		return
+	}
+
+	req := &ReportRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable unmarshal json from client at report: ", err)
+		http.Error(w, "can't unmarshal request from json", http.StatusInternalServerError)
+		return
+	}
+
+	reason, err := community.NormalizeReason(req.Reason)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at report: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at report: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable get post at report: ", err)
+		http.Error(w, "unable get post by id", http.StatusInternalServerError)
+		return
+	}
+
+	target := community.Target{
+		Type:      targetType,
+		ID:        p.ID,
+		PostID:    p.ID,
+		Community: p.Category,
+	}
+	if targetType == community.TargetComment {
+		target.ID = vars["comment_id"]
+
+		hasComment := false
+		for _, id := range p.CommentIDs {
+			if id == target.ID {
+				hasComment = true
+				break
+			}
+		}
+		if !hasComment {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": comment.ErrNotExist.Error(),
+			})
+			if err != nil {
+				h.Logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusInternalServerError,
+				}).Error("unable send json to client at report: ", err)
+				http.Error(w, "unable send json", http.StatusInternalServerError)
+				return
+			}
+
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusBadRequest,
+			}).Info()
+			return
+		}
+	}
+
+	err = h.ReportRepo.Add(target, sess.UserID, reason)
+	if err == community.ErrAlreadyReported {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at report: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable add report to repository: ", err)
+		http.Error(w, "unable add report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "success",
+	})
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at report: ", err)
+		http.Error(w, "unable send json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusCreated,
+	}).Info()
+}
+
+func (h *ReportHandler) ReportPost(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, community.TargetPost)
+}
+
+func (h *ReportHandler) ReportComment(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r, community.TargetComment)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// requestLog is the entry a request is logged with once its status is known.
+func requestLog(logger *logrus.Entry, r *http.Request, status int) *logrus.Entry {
+	return logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": status,
+	})
+}
+
+// sendJSON answers with v and logs the request, at names the handler in the
+// log if v can't be encoded. The status is already sent by then, so nothing
+// else is written to the client.
+func sendJSON(w http.ResponseWriter, r *http.Request, logger *logrus.Entry, status int, v interface{}, at string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		requestLog(logger, r, http.StatusInternalServerError).Error("unable send json to client at "+at+": ", err)
+		return
+	}
+
+	requestLog(logger, r, status).Info()
+}
+
+// sendMessage answers with the {"message": ...} body the frontend shows to
+// the user.
+func sendMessage(w http.ResponseWriter, r *http.Request, logger *logrus.Entry, status int, message string, at string) {
+	sendJSON(w, r, logger, status, map[string]interface{}{
+		"message": message,
+	}, at)
+}
+
+// serverError logs args as the error and answers 500 with text.
+func serverError(w http.ResponseWriter, r *http.Request, logger *logrus.Entry, text string, args ...interface{}) {
+	requestLog(logger, r, http.StatusInternalServerError).Error(args...)
+	http.Error(w, text, http.StatusInternalServerError)
+}
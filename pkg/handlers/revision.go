@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"io/ioutil"
+	"net/http"
+)
+
+var (
+	errEmptyTitle = errors.New("title must not be empty")
+	errEmptyURL   = errors.New("link post must have url")
+)
+
+type PostEditRequest struct {
+	Title *string `json:"title"`
+	Text  *string `json:"text"`
+	URL   *string `json:"url"`
+}
+
+func (h *PostHandler) Edit(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at edit post: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable read body", "unable read body at edit post: ", err)
+		return
+	}
+
+	req := &PostEditRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		serverError(w, r, h.Logger, "can't unmarshal request from json", "unable unmarshal json from client at edit post: ", err)
+		return
+	}
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "edit post")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable get post at edit post: ", err)
+		return
+	}
+
+	title, text, url := p.Title, p.Text, p.URL
+	if req.Title != nil {
+		title = *req.Title
+	}
+	if req.Text != nil {
+		text = *req.Text
+	}
+	if req.URL != nil {
+		url = *req.URL
+	}
+
+	err = nil
+	if title == "" {
+		err = errEmptyTitle
+	} else if p.Type == "link" && url == "" {
+		err = errEmptyURL
+	}
+	if err != nil {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "edit post")
+		return
+	}
+
+	err = h.PostRepo.Update(postID, sess.UserID, title, text, url)
+	if err == post.ErrNotExist || err == post.ErrNoEditAccess {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "edit post")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable edit post", "unable edit post: ", err)
+		return
+	}
+
+	p, err = h.PostRepo.GetByID(postID, viewsUpdate)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable edit post: ", err)
+		return
+	}
+
+	logIndexError(h.Logger, r, h.Searcher.Index(postDocument(p)))
+
+	posts := make([]*post.Post, 0, 1)
+	posts = append(posts, p)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, sess.UserID)
+	if err != nil || len(resp) != 1 {
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at edit post: ", err)
+		return
+	}
+
+	sendJSON(w, r, h.Logger, http.StatusOK, resp[0], "edit post")
+}
+
+func (h *PostHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID := vars["id"]
+
+	revisions, err := h.PostRepo.GetRevisions(postID)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get revisions")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get revisions", "unable get post revisions from repository: ", err)
+		return
+	}
+
+	sendJSON(w, r, h.Logger, http.StatusOK, revisions, "get revisions")
+}
+
+func (h *PostHandler) EditComment(w http.ResponseWriter, r *http.Request) {
+	sess, err := session.GetSessionFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		requestLog(h.Logger, r, http.StatusUnauthorized).Info()
+		return
+	}
+
+	vars := mux.Vars(r)
+	postID := vars["id"]
+	commentID := vars["comment_id"]
+
+	defer func(r *http.Request, logger *logrus.Entry) {
+		err := r.Body.Close()
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable request`s body close at edit comment: ", err)
+		}
+	}(r, h.Logger)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		serverError(w, r, h.Logger, "unable read body", "unable read body at edit comment: ", err)
+		return
+	}
+
+	req := &CommentRequest{}
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		serverError(w, r, h.Logger, "can't unmarshal request from json", "unable unmarshal json from client at edit comment: ", err)
+		return
+	}
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "edit comment")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable get post at edit comment: ", err)
+		return
+	}
+
+	hasComment := false
+	for _, id := range p.CommentIDs {
+		if id == commentID {
+			hasComment = true
+			break
+		}
+	}
+	if !hasComment {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, comment.ErrNotExist.Error(), "edit comment")
+		return
+	}
+
+	err = h.CommentRepo.Update(commentID, sess.UserID, req.Body)
+	if err == comment.ErrNotExist || err == comment.ErrInvalidID || err == comment.ErrNoEditAccess {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "edit comment")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable edit comment", "unable edit comment: ", err)
+		return
+	}
+
+	logIndexError(h.Logger, r, h.Searcher.Index(commentDocument(p, commentID, sess.UserID, req.Body)))
+
+	posts := make([]*post.Post, 0, 1)
+	posts = append(posts, p)
+	resp, err := h.createResponse(posts, DefaultCommentDepth, sess.UserID)
+	if err != nil || len(resp) != 1 {
+		serverError(w, r, h.Logger, "unable create response", "unable create response to client at edit comment: ", err)
+		return
+	}
+
+	sendJSON(w, r, h.Logger, http.StatusOK, resp[0], "edit comment")
+}
+
+func (h *PostHandler) GetCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID := vars["id"]
+	commentID := vars["comment_id"]
+
+	viewsUpdate := 0
+	p, err := h.PostRepo.GetByID(postID, viewsUpdate)
+	if err == post.ErrNotExist || err == post.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get comment revisions")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get post by id", "unable get post at get comment revisions: ", err)
+		return
+	}
+
+	hasComment := false
+	for _, id := range p.CommentIDs {
+		if id == commentID {
+			hasComment = true
+			break
+		}
+	}
+	if !hasComment {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, comment.ErrNotExist.Error(), "get comment revisions")
+		return
+	}
+
+	revisions, err := h.CommentRepo.GetRevisions(commentID)
+	if err == comment.ErrNotExist || err == comment.ErrInvalidID {
+		sendMessage(w, r, h.Logger, http.StatusBadRequest, err.Error(), "get comment revisions")
+		return
+	}
+	if err != nil {
+		serverError(w, r, h.Logger, "unable get revisions", "unable get comment revisions from repository: ", err)
+		return
+	}
+
+	sendJSON(w, r, h.Logger, http.StatusOK, revisions, "get comment revisions")
+}
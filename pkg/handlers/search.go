@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/search"
+	"github.com/vlasdash/redditclone/internal/user"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type SearchHandler struct {
+	Searcher search.Searcher
+	UserRepo user.UserRepo
+	Logger   *logrus.Entry
+}
+
+func NewSearchHandler(s search.Searcher, ur user.UserRepo, log *logrus.Entry) *SearchHandler {
+	return &SearchHandler{
+		Searcher: s,
+		UserRepo: ur,
+		Logger:   log,
+	}
+}
+
+func (h *SearchHandler) parseQuery(r *http.Request) (q search.Query, err error) {
+	values := r.URL.Query()
+	q.Text = values.Get("q")
+
+	q.Type, err = search.ParseType(values.Get("type"))
+	if err != nil {
+		return q, err
+	}
+
+	if category := values.Get("category"); category != "" {
+		q.Category, err = community.NormalizeName(category)
+		if err != nil {
+			return q, err
+		}
+	}
+
+	period, err := post.ParsePeriod(values.Get("t"))
+	if err != nil {
+		return q, err
+	}
+	q.Since = period.Since(time.Now())
+
+	if limit := values.Get("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil || q.Limit <= 0 {
+			return q, errInvalidLimit
+		}
+	}
+
+	if author := values.Get("author"); author != "" {
+		u, err := h.UserRepo.GetByUsername(author)
+		if err != nil {
+			return q, err
+		}
+		q.AuthorID = u.ID
+	}
+
+	return q, nil
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q, err := h.parseQuery(r)
+	if err == search.ErrInvalidType || err == post.ErrInvalidPeriod || err == errInvalidLimit ||
+		err == community.ErrInvalidName || err == user.ErrNoExist {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at search: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable parse search query: ", err)
+		http.Error(w, "unable search", http.StatusInternalServerError)
+		return
+	}
+
+	results, err := h.Searcher.Search(q)
+	if err == search.ErrEmptyQuery {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at search: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable search: ", err)
+		http.Error(w, "unable search", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(results)
+	if err != nil {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable send json to client at search: ", err)
+		http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+		return
+	}
+
+	h.Logger.WithFields(logrus.Fields{
+		"method":      r.Method,
+		"remote_addr": r.RemoteAddr,
+		"url":         r.URL.Path,
+		"status_code": http.StatusOK,
+	}).Info()
+}
+
+func postDocument(p *post.Post) *search.Document {
+	createDate, err := time.Parse(time.RFC3339, p.CreateDate)
+	if err != nil {
+		createDate = time.Now()
+	}
+
+	return &search.Document{
+		Type:       search.TypePost,
+		ID:         p.ID,
+		PostID:     p.ID,
+		Category:   p.Category,
+		AuthorID:   p.AuthorID,
+		Title:      p.Title,
+		Text:       p.Text,
+		URL:        p.URL,
+		CreateDate: createDate,
+	}
+}
+
+func commentDocument(p *post.Post, commentID string, authorID uint, body string) *search.Document {
+	return &search.Document{
+		Type:     search.TypeComment,
+		ID:       commentID,
+		PostID:   p.ID,
+		Category: p.Category,
+		AuthorID: authorID,
+		Text:     body,
+	}
+}
+
+// search is secondary to the write itself, so index failures are only logged
+func logIndexError(logger *logrus.Entry, r *http.Request, err error) {
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+		}).Error("unable update search index: ", err)
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"io"
+	"net/http"
+	"time"
+)
+
+const keepAliveInterval = 30 * time.Second
+
+var (
+	errNoTopics           = errors.New("nothing to stream, specify post, community, all or notifications")
+	errStreamUnauthorized = errors.New("notifications stream requires authorization")
+)
+
+type StreamHandler struct {
+	Hub    *event.Hub
+	Logger *logrus.Entry
+}
+
+func NewStreamHandler(hub *event.Hub, log *logrus.Entry) *StreamHandler {
+	return &StreamHandler{
+		Hub:    hub,
+		Logger: log,
+	}
+}
+
+// parseTopics reads ?post=id&community=name&all=true&notifications=true,
+// post and community may be repeated.
+func parseTopics(r *http.Request) ([]string, error) {
+	values := r.URL.Query()
+	topics := make([]string, 0)
+	for _, id := range values["post"] {
+		if id != "" {
+			topics = append(topics, event.PostTopic(id))
+		}
+	}
+	for _, name := range values["community"] {
+		name, err := community.NormalizeName(name)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, event.CommunityTopic(name))
+	}
+	if values.Get("all") == "true" {
+		topics = append(topics, event.TopicAll)
+	}
+	if values.Get("notifications") == "true" {
+		sess, err := session.GetSessionFromContext(r.Context())
+		if err != nil {
+			return nil, errStreamUnauthorized
+		}
+		topics = append(topics, event.UserTopic(sess.UserID))
+	}
+
+	if len(topics) == 0 {
+		return nil, errNoTopics
+	}
+
+	return topics, nil
+}
+
+func writeEvent(w io.Writer, e *event.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+
+	return err
+}
+
+// Stream pushes the events of the requested topics as server-sent events
+// until the client goes away.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	topics, err := parseTopics(r)
+	if err == errStreamUnauthorized {
+		w.WriteHeader(http.StatusUnauthorized)
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusUnauthorized,
+		}).Info()
+		return
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": err.Error(),
+		})
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusInternalServerError,
+			}).Error("unable send json to client at stream: ", err)
+			http.Error(w, "unable send json", http.StatusInternalServerError)
+			return
+		}
+
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusBadRequest,
+		}).Info()
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.Logger.WithFields(logrus.Fields{
+			"method":      r.Method,
+			"remote_addr": r.RemoteAddr,
+			"url":         r.URL.Path,
+			"status_code": http.StatusInternalServerError,
+		}).Error("unable stream, response writer can`t flush")
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sub := h.Hub.Subscribe(topics...)
+	defer h.Hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusOK,
+			}).Info()
+			return
+		case <-ticker.C:
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+		case e := <-sub.C:
+			err = writeEvent(w, e)
+		}
+		if err != nil {
+			h.Logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error("unable write event to client at stream: ", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// publishPost sends a post level event to the post and to the listings it
+// appears in.
+func publishPost(pub event.Publisher, p *post.Post, e *event.Event) {
+	pub.Publish(e, event.PostTopic(p.ID), event.CommunityTopic(p.Category), event.TopicAll)
+}
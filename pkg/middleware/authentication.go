@@ -2,11 +2,16 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/user"
 	"net/http"
 )
 
+var ErrPermissionDenied = errors.New("permission denied")
+
 type Authentication struct {
 	manager *session.Manager
 	logger  *logrus.Entry
@@ -19,6 +24,42 @@ func NewAuthenticationMiddleware(sm *session.Manager, l *logrus.Entry) *Authenti
 	}
 }
 
+// scopedHandler marks a route api keys and OAuth clients may call
+type scopedHandler struct {
+	scope session.Scope
+	next  http.Handler
+}
+
+func (h *scopedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.next.ServeHTTP(w, r)
+}
+
+// RequireScope opens the route for api keys and OAuth clients granted the
+// scope, routes without it stay available to logged in users only.
+func (a *Authentication) RequireScope(scope session.Scope, next http.Handler) http.Handler {
+	return &scopedHandler{
+		scope: scope,
+		next:  next,
+	}
+}
+
+func routeAllows(sess *session.Session, r *http.Request) bool {
+	if !sess.Scoped() {
+		return true
+	}
+
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+	h, ok := route.GetHandler().(*scopedHandler)
+	if !ok {
+		return false
+	}
+
+	return sess.Allows(h.scope)
+}
+
 func (a *Authentication) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		accessToken := r.Header.Get("Authorization")
@@ -26,7 +67,8 @@ func (a *Authentication) Authenticate(next http.Handler) http.Handler {
 		sess, err := a.manager.Create(accessToken)
 		if err != nil {
 			statusCode := 0
-			if err == session.ErrEmptyPayload || err == session.ErrEmptyUserInfo || err == session.ErrBadToken {
+			if err == session.ErrEmptyPayload || err == session.ErrEmptyUserInfo || err == session.ErrBadToken ||
+				err == session.ErrTokenExpired || err == session.ErrTokenRevoked {
 				statusCode = http.StatusUnauthorized
 			} else if err == session.ErrBadSigningMethod {
 				statusCode = http.StatusBadRequest
@@ -41,6 +83,7 @@ func (a *Authentication) Authenticate(next http.Handler) http.Handler {
 				return
 			}
 
+			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(statusCode)
 			err = json.NewEncoder(w).Encode(map[string]interface{}{
 				"message": err.Error(),
@@ -67,6 +110,31 @@ func (a *Authentication) Authenticate(next http.Handler) http.Handler {
 		}
 
 		isExist, err := a.manager.HasUserExist(sess)
+		if err == session.ErrUserBanned {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": err.Error(),
+			})
+			if err != nil {
+				a.logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusInternalServerError,
+				}).Error("unable send json to client: ", err)
+				http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+				return
+			}
+
+			a.logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusForbidden,
+			}).Info()
+			return
+		}
 		if err != nil {
 			a.logger.WithFields(logrus.Fields{
 				"method":      r.Method,
@@ -78,6 +146,7 @@ func (a *Authentication) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 		if !isExist {
+			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusUnauthorized)
 			err = json.NewEncoder(w).Encode(map[string]interface{}{
 				"message": "you did not register",
@@ -99,9 +168,116 @@ func (a *Authentication) Authenticate(next http.Handler) http.Handler {
 				"url":         r.URL.Path,
 				"status_code": http.StatusUnauthorized,
 			}).Info()
+			return
+		}
+
+		if !routeAllows(sess, r) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": session.ErrScopeNotAllowed.Error(),
+			})
+			if err != nil {
+				a.logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusInternalServerError,
+				}).Error("unable send json to client: ", err)
+				http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+				return
+			}
+
+			a.logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+				"status_code": http.StatusForbidden,
+			}).Info()
+			return
 		}
 
 		ctx := session.CreateContextWithSession(r.Context(), sess)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func (a *Authentication) Identify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accessToken := r.Header.Get("Authorization")
+		if accessToken == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// public pages are still served when the token is bad, just anonymously
+		sess, err := a.manager.Create(accessToken)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		isExist, err := a.manager.HasUserExist(sess)
+		if err != nil && err != session.ErrUserBanned {
+			a.logger.WithFields(logrus.Fields{
+				"method":      r.Method,
+				"remote_addr": r.RemoteAddr,
+				"url":         r.URL.Path,
+			}).Error(err.Error())
+		}
+		if err != nil || !isExist || !sess.Allows(session.ScopeRead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := session.CreateContextWithSession(r.Context(), sess)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequirePermission is layered after Authenticate on the routes of site staff.
+func (a *Authentication) RequirePermission(p user.Permission) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess, err := session.GetSessionFromContext(r.Context())
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				a.logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusUnauthorized,
+				}).Info()
+				return
+			}
+
+			if !sess.Role.Can(p) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				err = json.NewEncoder(w).Encode(map[string]interface{}{
+					"message": ErrPermissionDenied.Error(),
+				})
+				if err != nil {
+					a.logger.WithFields(logrus.Fields{
+						"method":      r.Method,
+						"remote_addr": r.RemoteAddr,
+						"url":         r.URL.Path,
+						"status_code": http.StatusInternalServerError,
+					}).Error("unable send json to client: ", err)
+					http.Error(w, "can't encode answer to json", http.StatusInternalServerError)
+					return
+				}
+
+				a.logger.WithFields(logrus.Fields{
+					"method":      r.Method,
+					"remote_addr": r.RemoteAddr,
+					"url":         r.URL.Path,
+					"status_code": http.StatusForbidden,
+				}).Info()
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -3,12 +3,26 @@ package middleware
 import (
 	"encoding/json"
 	"github.com/sirupsen/logrus"
+	"mime"
 	"net/http"
 )
 
+// formPaths are called by OAuth clients, RFC 6749 makes them send forms
+var formPaths = map[string]bool{
+	"/api/oauth/token":  true,
+	"/api/oauth/revoke": true,
+}
+
 func CheckContentType(logger *logrus.Entry, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		contentType := r.Header.Get("Content-Type")
+		if formPaths[r.URL.Path] {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err == nil && mediaType == "application/x-www-form-urlencoded" {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
 		if contentType != "application/json" && contentType != "" {
 			w.WriteHeader(http.StatusBadRequest)
 			w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,244 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"github.com/vlasdash/redditclone/pkg/middleware"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminRoutesPermissions(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	authentication := middleware.NewAuthenticationMiddleware(session.NewManager(sessionRepo, userRepo, nil, nil), contextLogger)
+
+	accounts := []*user.User{
+		{ID: 1, Username: "user", Role: user.RoleUser},
+		{ID: 2, Username: "moderator", Role: user.RoleModerator},
+		{ID: 3, Username: "admin", Role: user.RoleAdmin},
+		{ID: 4, Username: "banned", Role: user.RoleAdmin, Banned: true},
+	}
+	for _, u := range accounts {
+		sessionRepo.EXPECT().Get("Bearer "+u.Username).Return(&session.Session{UserID: u.ID, Username: u.Username}, nil).AnyTimes()
+		userRepo.EXPECT().GetByID(u.ID).Return(u, nil).AnyTimes()
+	}
+	sessionRepo.EXPECT().Get("Bearer deleted").Return(&session.Session{UserID: 5, Username: "deleted"}, nil).AnyTimes()
+	userRepo.EXPECT().GetByID(uint(5)).Return(nil, user.ErrNoExist).AnyTimes()
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	r := mux.NewRouter()
+	s := r.PathPrefix("/api").Subrouter()
+	s.Use(authentication.Authenticate)
+	adminUsers := s.PathPrefix("/admin/users").Subrouter()
+	adminUsers.HandleFunc("", ok).Methods("GET")
+	adminUsers.Use(authentication.RequirePermission(user.PermManageUsers))
+	adminContent := s.PathPrefix("/admin/post").Subrouter()
+	adminContent.HandleFunc("/{id}/remove", ok).Methods("POST")
+	adminContent.Use(authentication.RequirePermission(user.PermRemoveContent))
+
+	tests := []struct {
+		Token  string
+		Method string
+		URL    string
+		Status int
+	}{
+		{Token: "user", Method: "GET", URL: "/api/admin/users", Status: http.StatusForbidden},
+		{Token: "user", Method: "POST", URL: "/api/admin/post/1/remove", Status: http.StatusForbidden},
+		{Token: "moderator", Method: "GET", URL: "/api/admin/users", Status: http.StatusForbidden},
+		{Token: "moderator", Method: "POST", URL: "/api/admin/post/1/remove", Status: http.StatusOK},
+		{Token: "admin", Method: "GET", URL: "/api/admin/users", Status: http.StatusOK},
+		{Token: "admin", Method: "POST", URL: "/api/admin/post/1/remove", Status: http.StatusOK},
+		{Token: "banned", Method: "GET", URL: "/api/admin/users", Status: http.StatusForbidden},
+		{Token: "deleted", Method: "GET", URL: "/api/admin/users", Status: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.Method, test.URL, nil)
+		req.Header.Set("Authorization", "Bearer "+test.Token)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if w.Code != test.Status {
+			t.Errorf("%s %s %s: expected resp status %d, got %d", test.Token, test.Method, test.URL, test.Status, w.Code)
+		}
+		if test.Status != http.StatusOK && w.Result().Header.Get("Content-Type") != "application/json" {
+			t.Errorf("%s %s %s: expected json content type, got %q", test.Token, test.Method, test.URL, w.Result().Header.Get("Content-Type"))
+		}
+	}
+}
+
+func TestAdminGetUsers(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewAdminHandler(userRepo, contextLogger)
+
+	userRepo.EXPECT().GetAll().Return([]*user.User{
+		{ID: 1, Username: "admin", Password: "hash", Role: user.RoleAdmin},
+		{ID: 2, Username: "spammer", Password: "hash", Role: user.RoleUser, Banned: true},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/admin/users", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetUsers(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if bytes.Contains(body, []byte("hash")) {
+		t.Errorf("password hash leaked: %s", body)
+	}
+	result := make([]*handlers.AdminUserResponse, 0)
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if len(result) != 2 || result[0].Role != user.RoleAdmin || !result[1].Banned {
+		t.Errorf("wrong response: %s", body)
+	}
+}
+
+func TestAdminBan(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewAdminHandler(userRepo, contextLogger)
+
+	userRepo.EXPECT().GetByID(uint(2)).Return(&user.User{ID: 2, Username: "spammer", Role: user.RoleUser}, nil).Times(2)
+	userRepo.EXPECT().GetByID(uint(3)).Return(&user.User{ID: 3, Username: "admin", Role: user.RoleAdmin}, nil)
+	userRepo.EXPECT().GetByID(uint(9)).Return(nil, user.ErrNoExist)
+	userRepo.EXPECT().SetBanned(uint(2), true).Return(nil)
+	userRepo.EXPECT().SetBanned(uint(2), false).Return(nil)
+
+	tests := []struct {
+		ID      string
+		Handler http.HandlerFunc
+		Status  int
+	}{
+		{ID: "2", Handler: handler.Ban, Status: http.StatusOK},
+		{ID: "2", Handler: handler.Unban, Status: http.StatusOK},
+		{ID: "3", Handler: handler.Ban, Status: http.StatusBadRequest},
+		{ID: "9", Handler: handler.Ban, Status: http.StatusBadRequest},
+		{ID: "abc", Handler: handler.Ban, Status: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/api/admin/users/"+test.ID+"/ban", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": test.ID})
+		w := httptest.NewRecorder()
+
+		test.Handler(w, req)
+
+		if w.Code != test.Status {
+			t.Errorf("user %s: expected resp status %d, got %d", test.ID, test.Status, w.Code)
+		}
+	}
+}
+
+func TestAdminSetRole(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewAdminHandler(userRepo, contextLogger)
+
+	userRepo.EXPECT().GetByID(uint(1)).Return(&user.User{ID: 1, Username: "admin", Role: user.RoleAdmin}, nil)
+	userRepo.EXPECT().GetByID(uint(2)).Return(&user.User{ID: 2, Username: "username", Role: user.RoleUser}, nil)
+	userRepo.EXPECT().SetRole(uint(2), user.RoleModerator).Return(nil)
+
+	tests := []struct {
+		ID     string
+		Role   string
+		Status int
+	}{
+		{ID: "2", Role: "moderator", Status: http.StatusOK},
+		{ID: "2", Role: "owner", Status: http.StatusBadRequest},
+		{ID: "1", Role: "user", Status: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		body, _ := json.Marshal(&handlers.AdminRoleRequest{Role: test.Role})
+		req := httptest.NewRequest("PUT", "/api/admin/users/"+test.ID+"/role", bytes.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": test.ID})
+		ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "admin", Token: "token", Role: user.RoleAdmin})
+		req = req.WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		handler.SetRole(w, req)
+
+		if w.Code != test.Status {
+			t.Errorf("user %s role %s: expected resp status %d, got %d", test.ID, test.Role, test.Status, w.Code)
+		}
+	}
+}
+
+func TestLoginBannedUser(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	u := &user.User{ID: 1, Username: "username", Password: "hash", Banned: true}
+	userRepo.EXPECT().GetByUsername("username").Return(u, nil)
+	hasher.EXPECT().IsPassword("hash", "password").Return(true)
+
+	body, _ := json.Marshal(&handlers.AuthorizationRequest{Username: "username", Password: "password"})
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Login(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected resp status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"github.com/vlasdash/redditclone/pkg/middleware"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateAPIKeyCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	apiKeyRepo := mock.NewMockAPIKeyRepo(controller)
+	handler := handlers.NewAPIKeyHandler(apiKeyRepo, contextLogger)
+
+	scopes := []session.Scope{session.ScopeRead, session.ScopeVote}
+	key := &session.APIKey{ID: 1, UserID: 1, Username: "username", Name: "bot", Scopes: scopes, CreateDate: "2022-10-10T10:10:10Z"}
+	apiKeyRepo.EXPECT().Add(uint(1), "username", "bot", scopes).Return(key, "rck_secret", nil)
+
+	body, _ := json.Marshal(&handlers.APIKeyRequest{Name: " bot ", Scopes: []string{"read", "vote"}})
+	req := httptest.NewRequest("POST", "/api/keys", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected resp status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	result := struct {
+		Key   handlers.APIKeyResponse `json:"key"`
+		Token string                  `json:"token"`
+	}{}
+	err := json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if result.Token != "rck_secret" || result.Key.ID != 1 || len(result.Key.Scopes) != 2 {
+		t.Errorf("wrong response: %+v", result)
+	}
+}
+
+func TestCreateAPIKeyBadScope(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	apiKeyRepo := mock.NewMockAPIKeyRepo(controller)
+	handler := handlers.NewAPIKeyHandler(apiKeyRepo, contextLogger)
+
+	body, _ := json.Marshal(&handlers.APIKeyRequest{Name: "bot", Scopes: []string{"admin"}})
+	req := httptest.NewRequest("POST", "/api/keys", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestDeleteAPIKeyNotExist(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	apiKeyRepo := mock.NewMockAPIKeyRepo(controller)
+	handler := handlers.NewAPIKeyHandler(apiKeyRepo, contextLogger)
+
+	apiKeyRepo.EXPECT().Delete(uint(1), uint(5)).Return(session.ErrNoAPIKey)
+
+	req := httptest.NewRequest("DELETE", "/api/keys/5", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestAuthenticateAPIKeyScopes(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	apiKeyRepo := session.NewMemoryAPIKeyRepo()
+	authentication := middleware.NewAuthenticationMiddleware(session.NewManager(sessionRepo, userRepo, apiKeyRepo, nil), contextLogger)
+
+	_, token, err := apiKeyRepo.Add(1, "username", "bot", []session.Scope{session.ScopeVote})
+	if err != nil {
+		t.Fatalf("unable add key: %v", err)
+	}
+	userRepo.EXPECT().GetByID(uint(1)).Return(&user.User{ID: 1, Username: "username"}, nil).AnyTimes()
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	r := mux.NewRouter()
+	s := r.PathPrefix("/api").Subrouter()
+	s.Handle("/post/{id}/upvote", authentication.RequireScope(session.ScopeVote, http.HandlerFunc(ok))).Methods("GET")
+	s.Handle("/posts", authentication.RequireScope(session.ScopePost, http.HandlerFunc(ok))).Methods("POST")
+	s.HandleFunc("/me/password", ok).Methods("POST")
+	s.Use(authentication.Authenticate)
+
+	tests := []struct {
+		Method string
+		URL    string
+		Status int
+	}{
+		{Method: "GET", URL: "/api/post/1/upvote", Status: http.StatusOK},
+		{Method: "POST", URL: "/api/posts", Status: http.StatusForbidden},
+		{Method: "POST", URL: "/api/me/password", Status: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.Method, test.URL, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		if w.Code != test.Status {
+			t.Errorf("%s %s: expected resp status %d, got %d", test.Method, test.URL, test.Status, w.Code)
+		}
+		if test.Status != http.StatusOK && w.Result().Header.Get("Content-Type") != "application/json" {
+			t.Errorf("%s %s: expected json content type, got %q", test.Method, test.URL, w.Result().Header.Get("Content-Type"))
+		}
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
 	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/twofactor"
 	"github.com/vlasdash/redditclone/internal/user"
 	"github.com/vlasdash/redditclone/pkg/handlers"
 	"io/ioutil"
@@ -58,12 +60,17 @@ func TestLoginCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(test.User, nil)
-	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID).Return(test.Token, nil)
+	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID, gomock.Any()).Return(test.Token, nil)
+	refreshRepo.EXPECT().Add(test.User.Username, test.User.ID).Return("refreshToken", nil)
 	hasher.EXPECT().IsPassword(test.User.Password, test.Request.Password).Return(true)
+	twoFactorRepo.EXPECT().Get(test.User.ID).Return(nil, twofactor.ErrNotEnrolled)
 
 	b := bytes.NewBufferString("")
 	err := json.NewEncoder(b).Encode(test.Request)
@@ -116,8 +123,11 @@ func TestLoginAuthorizationError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	// тестирование неправильного логина пользователя
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(nil, user.ErrNoExist)
@@ -205,12 +215,16 @@ func TestLoginSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(test.User, nil)
 	hasher.EXPECT().IsPassword(test.User.Password, test.Request.Password).Return(true)
-	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID).Return("", fmt.Errorf("something went wrong"))
+	twoFactorRepo.EXPECT().Get(test.User.ID).Return(nil, twofactor.ErrNotEnrolled)
+	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID, gomock.Any()).Return("", fmt.Errorf("something went wrong"))
 
 	b := bytes.NewBufferString("")
 	err := json.NewEncoder(b).Encode(test.Request)
@@ -252,8 +266,11 @@ func TestLoginReadBodyError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	req := httptest.NewRequest("POST", "/api/login", errAuthReader{})
 	req.Header.Add("Content-Type", "application/json")
@@ -288,8 +305,11 @@ func TestLoginUnmarshalError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	b := bytes.NewBufferString("bad body")
 	req := httptest.NewRequest("POST", "/api/login", b)
@@ -339,13 +359,17 @@ func TestRegisterCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(nil, user.ErrNoExist)
 	userRepo.EXPECT().Create(test.User.Username, test.User.Password).Return(test.User.ID, nil)
 	hasher.EXPECT().GetHashPassword(test.Request.Password).Return(test.User.Password, nil)
-	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID).Return(test.Token, nil)
+	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID, gomock.Any()).Return(test.Token, nil)
+	refreshRepo.EXPECT().Add(test.User.Username, test.User.ID).Return("refreshToken", nil)
 
 	b := bytes.NewBufferString("")
 	err := json.NewEncoder(b).Encode(test.Request)
@@ -408,8 +432,11 @@ func TestRegisterAlreadyExistError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(test.User, nil)
 
@@ -458,8 +485,11 @@ func TestRegisterReadBodyError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	req := httptest.NewRequest("POST", "/api/register", errAuthReader{})
 	req.Header.Add("Content-Type", "application/json")
@@ -495,8 +525,11 @@ func TestRegisterUnmarshalError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	b := bytes.NewBufferString("bad body")
 	req := httptest.NewRequest("POST", "/api/register", b)
@@ -545,8 +578,11 @@ func TestRegisterHashError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(nil, user.ErrNoExist)
 	hasher.EXPECT().GetHashPassword(test.Request.Password).Return("", fmt.Errorf("something went wrong"))
@@ -602,8 +638,11 @@ func TestRegisterCreateError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(nil, user.ErrNoExist)
 	hasher.EXPECT().GetHashPassword(test.Request.Password).Return(test.User.Password, nil)
@@ -660,13 +699,16 @@ func TestRegisterAddSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
 	hasher := mock.NewMockPasswordHasher(controller)
-	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
 
 	userRepo.EXPECT().GetByUsername(test.Request.Username).Return(nil, user.ErrNoExist)
 	hasher.EXPECT().GetHashPassword(test.Request.Password).Return(test.User.Password, nil)
 	userRepo.EXPECT().Create(test.User.Username, test.User.Password).Return(test.User.ID, nil)
-	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID).Return("", fmt.Errorf("something went wrong"))
+	sessionRepo.EXPECT().Add(test.User.Username, test.User.ID, gomock.Any()).Return("", fmt.Errorf("something went wrong"))
 
 	b := bytes.NewBufferString("")
 	err := json.NewEncoder(b).Encode(test.Request)
@@ -695,3 +737,405 @@ func TestRegisterAddSessionError(t *testing.T) {
 		t.Errorf("expected error message %s, got %s", expectedMessage, body)
 	}
 }
+
+func TestLoginSavesClient(t *testing.T) {
+	u := &user.User{
+		ID:       1,
+		Username: "username",
+		Password: "password",
+	}
+	expectedClient := session.Client{
+		UserAgent: "firefox",
+		IP:        "192.0.2.1",
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	userRepo.EXPECT().GetByUsername(u.Username).Return(u, nil)
+	hasher.EXPECT().IsPassword(u.Password, u.Password).Return(true)
+	twoFactorRepo.EXPECT().Get(u.ID).Return(nil, twofactor.ErrNotEnrolled)
+	sessionRepo.EXPECT().Add(u.Username, u.ID, expectedClient).Return("token", nil)
+	refreshRepo.EXPECT().Add(u.Username, u.ID).Return("refreshToken", nil)
+
+	b := bytes.NewBufferString(`{"username": "username", "password": "password"}`)
+	req := httptest.NewRequest("POST", "/api/login", b)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("User-Agent", expectedClient.UserAgent)
+	w := httptest.NewRecorder()
+
+	handler.Login(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestLogoutCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	sessionRepo.EXPECT().Delete("Bearer token").Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/logout", nil)
+	req.Header.Add("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	handler.Logout(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestLogoutRevokedError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	sessionRepo.EXPECT().Delete("Bearer token").Return(session.ErrTokenRevoked)
+
+	req := httptest.NewRequest("POST", "/api/logout", nil)
+	req.Header.Add("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	handler.Logout(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected resp status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestLogoutAllCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	sessionRepo.EXPECT().DeleteAll(uint(1)).Return(nil)
+	refreshRepo.EXPECT().DeleteAll(uint(1)).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/logout/all", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.LogoutAll(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestGetSessionsCorrect(t *testing.T) {
+	sessions := []*session.Session{
+		{
+			UserID:         1,
+			Username:       "username",
+			Token:          "first",
+			ExpirationDate: "1700000000",
+			CreateDate:     "2022-10-10T10:10:10Z",
+			UserAgent:      "firefox",
+			IP:             "127.0.0.1",
+		},
+		{
+			UserID:         1,
+			Username:       "username",
+			Token:          "second",
+			ExpirationDate: "1700000001",
+			CreateDate:     "2022-10-11T10:10:10Z",
+			UserAgent:      "curl",
+			IP:             "127.0.0.2",
+		},
+	}
+	expected := []*handlers.SessionResponse{
+		{
+			CreateDate:     sessions[0].CreateDate,
+			ExpirationDate: sessions[0].ExpirationDate,
+			UserAgent:      sessions[0].UserAgent,
+			IP:             sessions[0].IP,
+			Current:        false,
+		},
+		{
+			CreateDate:     sessions[1].CreateDate,
+			ExpirationDate: sessions[1].ExpirationDate,
+			UserAgent:      sessions[1].UserAgent,
+			IP:             sessions[1].IP,
+			Current:        true,
+		},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	sessionRepo.EXPECT().GetAll(uint(1)).Return(sessions, nil)
+
+	req := httptest.NewRequest("GET", "/api/sessions", nil)
+	ctx := session.CreateContextWithSession(req.Context(), sessions[1])
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.GetSessions(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	got := make([]*handlers.SessionResponse, 0)
+	err = json.Unmarshal(body, &got)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestRefreshCorrect(t *testing.T) {
+	rt := &session.RefreshToken{
+		FamilyID: "family",
+		UserID:   1,
+		Username: "username",
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	refreshRepo.EXPECT().Rotate("oldRefresh").Return(rt, "newRefresh", nil)
+	userRepo.EXPECT().GetByID(rt.UserID).Return(&user.User{ID: 1, Username: "username"}, nil)
+	sessionRepo.EXPECT().Add(rt.Username, rt.UserID, gomock.Any()).Return("newToken", nil)
+
+	b := bytes.NewBufferString(`{"refreshToken": "oldRefresh"}`)
+	req := httptest.NewRequest("POST", "/api/token/refresh", b)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Refresh(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	got := map[string]string{}
+	err = json.Unmarshal(body, &got)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	expected := map[string]string{
+		"token":        "newToken",
+		"refreshToken": "newRefresh",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestRefreshReusedError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	refreshRepo.EXPECT().Rotate("oldRefresh").Return(nil, "", session.ErrTokenReused)
+
+	b := bytes.NewBufferString(`{"refreshToken": "oldRefresh"}`)
+	req := httptest.NewRequest("POST", "/api/token/refresh", b)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.Refresh(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected resp status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestRefreshGoneUserError(t *testing.T) {
+	rt := &session.RefreshToken{
+		FamilyID: "family",
+		UserID:   1,
+		Username: "username",
+	}
+	tests := []struct {
+		Name   string
+		User   *user.User
+		Err    error
+		Status int
+	}{
+		{Name: "deleted", Err: user.ErrNoExist, Status: http.StatusUnauthorized},
+		{Name: "banned", User: &user.User{ID: 1, Username: "username", Banned: true}, Status: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		controller := gomock.NewController(t)
+
+		contextLogger := logrus.WithFields(logrus.Fields{
+			"logger": "LOGRUS",
+		})
+		contextLogger.Logger.Out = ioutil.Discard
+
+		userRepo := mock.NewMockUserRepo(controller)
+		sessionRepo := mock.NewMockSessionRepo(controller)
+		refreshRepo := mock.NewMockRefreshRepo(controller)
+		hasher := mock.NewMockPasswordHasher(controller)
+		twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+		challengeRepo := mock.NewMockChallengeRepo(controller)
+		handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+		refreshRepo.EXPECT().Rotate("oldRefresh").Return(rt, "newRefresh", nil)
+		userRepo.EXPECT().GetByID(rt.UserID).Return(test.User, test.Err)
+		refreshRepo.EXPECT().DeleteFamily("newRefresh").Return(nil)
+
+		b := bytes.NewBufferString(`{"refreshToken": "oldRefresh"}`)
+		req := httptest.NewRequest("POST", "/api/token/refresh", b)
+		req.Header.Add("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Refresh(w, req)
+
+		if w.Code != test.Status {
+			t.Errorf("%s: expected resp status %d, got %d", test.Name, test.Status, w.Code)
+		}
+		controller.Finish()
+	}
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	sessionRepo.EXPECT().Delete("Bearer token").Return(nil)
+	refreshRepo.EXPECT().DeleteFamily("refresh").Return(nil)
+
+	b := bytes.NewBufferString(`{"refreshToken": "refresh"}`)
+	req := httptest.NewRequest("POST", "/api/logout", b)
+	req.Header.Add("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	handler.Logout(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
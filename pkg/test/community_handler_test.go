@@ -0,0 +1,322 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetCommunitiesCorrect(t *testing.T) {
+	expected := []*community.Community{
+		{
+			Name:       "music",
+			CreateDate: "2022-10-10T10:10:10Z",
+			Settings: community.Settings{
+				Description: "music",
+				Rules:       []string{"be nice"},
+			},
+		},
+		{
+			Name:    "golang",
+			OwnerID: 1,
+			Settings: community.Settings{
+				AllowedTypes: []string{community.TypeText},
+				Restricted:   true,
+			},
+		},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	communityRepo.EXPECT().GetAll().Return(expected, nil)
+
+	req := httptest.NewRequest("GET", "/api/communities", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetList(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	communities := make([]*community.Community, 0)
+	err = json.Unmarshal(body, &communities)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(communities, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, communities)
+	}
+}
+
+func TestGetCommunityNotExistError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	communityRepo.EXPECT().GetByName("golang").Return(nil, community.ErrNotExist)
+
+	req := httptest.NewRequest("GET", "/api/community/GoLang", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "GoLang"})
+	w := httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestCreateCommunityCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	expected := &community.Community{
+		Name:    "golang",
+		OwnerID: 1,
+		Settings: community.Settings{
+			Description:  "gophers",
+			AllowedTypes: []string{community.TypeText},
+		},
+	}
+	communityRepo.EXPECT().Create(expected).Return(nil)
+
+	b := bytes.NewBufferString(`{"name": "golang", "description": "gophers", "allowedTypes": ["text"]}`)
+	req := httptest.NewRequest("POST", "/api/communities", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected resp status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestCreateCommunityInvalidError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	bodies := []string{
+		`{"name": "no spaces allowed"}`,
+		`{"name": "golang", "allowedTypes": ["video"]}`,
+	}
+	for _, b := range bodies {
+		req := httptest.NewRequest("POST", "/api/communities", bytes.NewBufferString(b))
+		ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+		req = req.WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected resp status %d for %s, got %d", http.StatusBadRequest, b, resp.StatusCode)
+		}
+	}
+}
+
+func TestCreateCommunityAlreadyExistError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	communityRepo.EXPECT().Create(gomock.Any()).Return(community.ErrAlreadyExist)
+
+	b := bytes.NewBufferString(`{"name": "music"}`)
+	req := httptest.NewRequest("POST", "/api/communities", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestUpdateCommunityNoAccessError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	communityRepo.EXPECT().UpdateSettings("golang", uint(2), community.Settings{Restricted: true}).Return(community.ErrNoAccess)
+
+	b := bytes.NewBufferString(`{"restricted": true}`)
+	req := httptest.NewRequest("PUT", "/api/community/golang", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "other"})
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"name": "golang"})
+	w := httptest.NewRecorder()
+
+	handler.UpdateSettings(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestSubscribeNotExistError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	communityRepo.EXPECT().GetByName("golang").Return(nil, community.ErrNotExist)
+
+	req := httptest.NewRequest("POST", "/api/community/golang/subscribe", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "golang"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Subscribe(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestSubscribeCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	communityRepo.EXPECT().GetByName("golang").Return(&community.Community{Name: "golang"}, nil)
+	subscriptionRepo.EXPECT().Subscribe(uint(1), "golang").Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/community/GoLang/subscribe", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "GoLang"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Subscribe(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestUnsubscribeNotSubscribedError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	handler := handlers.NewCommunityHandler(communityRepo, subscriptionRepo, contextLogger)
+
+	subscriptionRepo.EXPECT().Unsubscribe(uint(1), "golang").Return(community.ErrNotSubscribed)
+
+	req := httptest.NewRequest("DELETE", "/api/community/golang/subscribe", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "golang"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Unsubscribe(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJWKSVerifiesToken(t *testing.T) {
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	key, err := session.GenerateSigningKey("key-1")
+	if err != nil {
+		t.Fatalf("unable generate key: %v", err)
+	}
+	keys, err := session.NewKeySet([]*session.SigningKey{key}, 0)
+	if err != nil {
+		t.Fatalf("unable create key set: %v", err)
+	}
+	handler := handlers.NewJWKSHandler(keys, contextLogger)
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+	if resp.Header.Get("Cache-Control") == "" {
+		t.Errorf("expected Cache-Control header")
+	}
+	result := &session.JWKS{}
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if len(result.Keys) != 1 || result.Keys[0].ID != "key-1" || result.Keys[0].KeyType != "OKP" {
+		t.Fatalf("wrong response: %+v", result.Keys)
+	}
+
+	// another service only has the published key
+	x, err := base64.RawURLEncoding.DecodeString(result.Keys[0].X)
+	if err != nil {
+		t.Fatalf("unable decode public key: %v", err)
+	}
+	tokenStr, _, err := session.NewJWTGenerator(keys, 0).Generate("username", 1)
+	if err != nil {
+		t.Fatalf("unable generate token: %v", err)
+	}
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		return ed25519.PublicKey(x), nil
+	})
+	if err != nil || !token.Valid {
+		t.Errorf("token doesn't verify with published key: %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/limiter"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/twofactor"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newLoginLimiter() *limiter.Limiter {
+	return limiter.NewLimiter(limiter.NewMemoryRepo(), "login", limiter.LoginPolicies)
+}
+
+func newRegisterLimiter() *limiter.Limiter {
+	return limiter.NewLimiter(limiter.NewMemoryRepo(), "register", limiter.RegisterPolicies)
+}
+
+func newResetLimiter() *limiter.Limiter {
+	return limiter.NewLimiter(limiter.NewMemoryRepo(), "reset", limiter.ResetPolicies)
+}
+
+func TestLoginTooManyAttempts(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	free := limiter.LoginPolicies[limiter.KindUsername].Free
+	u := &user.User{ID: 1, Username: "username", Password: "hash"}
+	userRepo.EXPECT().GetByUsername("username").Return(u, nil).Times(free + 2)
+	hasher.EXPECT().IsPassword("hash", "guess").Return(false).Times(free + 1)
+	hasher.EXPECT().IsPassword("hash", "password").Return(true)
+	twoFactorRepo.EXPECT().Get(u.ID).Return(nil, twofactor.ErrNotEnrolled)
+	sessionRepo.EXPECT().Add(u.Username, u.ID, gomock.Any()).Return("token", nil)
+	refreshRepo.EXPECT().Add(u.Username, u.ID).Return("refreshToken", nil)
+
+	login := func(password string) *http.Response {
+		body, _ := json.Marshal(&handlers.AuthorizationRequest{Username: "username", Password: password})
+		req := httptest.NewRequest("POST", "/api/login", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		return w.Result()
+	}
+
+	// the attempt after the free ones is still checked, the next one waits
+	for i := 0; i <= free; i++ {
+		if resp := login("guess"); resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected resp status %d, got %d", i, http.StatusUnauthorized, resp.StatusCode)
+		}
+	}
+	resp := login("password")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected resp status %d, got %d", http.StatusTooManyRequests, resp.StatusCode)
+	}
+	retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || retryAfter < 1 {
+		t.Errorf("wrong Retry-After header %q", resp.Header.Get("Retry-After"))
+	}
+	handler.LoginLimiter.Policies = map[limiter.Kind]limiter.Policy{}
+	if resp = login("password"); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+	if a, _ := handler.LoginLimiter.Repo.Get("login:user:" + limiter.Username("username").ID); a.Count != 0 {
+		t.Errorf("expected username attempts reset after login, got %d", a.Count)
+	}
+}
+
+func TestRegisterTooManyAttempts(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	free := limiter.RegisterPolicies[limiter.KindIP].Free
+	userRepo.EXPECT().GetByUsername("taken").Return(&user.User{ID: 1, Username: "taken"}, nil).Times(free + 1)
+
+	for i := 0; i <= free; i++ {
+		body, _ := json.Marshal(&handlers.AuthorizationRequest{Username: "taken", Password: "password"})
+		req := httptest.NewRequest("POST", "/api/register", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.Register(w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("attempt %d: expected resp status %d, got %d", i, http.StatusUnprocessableEntity, w.Code)
+		}
+	}
+
+	body, _ := json.Marshal(&handlers.AuthorizationRequest{Username: "taken", Password: "password"})
+	req := httptest.NewRequest("POST", "/api/register", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Register(w, req)
+
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected resp status %d with Retry-After 60, got %d %q", http.StatusTooManyRequests, w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestTwoFactorFailuresLockLogin(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	// every round takes two attempts of the username, the password and the code
+	rounds := (limiter.LoginPolicies[limiter.KindUsername].Free + 1) / 2
+	u := &user.User{ID: 1, Username: "username", Password: "hash"}
+	settings := &twofactor.Settings{UserID: u.ID, Secret: twoFactorSecret, Enabled: true}
+	userRepo.EXPECT().GetByUsername(u.Username).Return(u, nil).Times(rounds)
+	hasher.EXPECT().IsPassword(u.Password, "password").Return(true).Times(rounds)
+	twoFactorRepo.EXPECT().Get(u.ID).Return(settings, nil).Times(2 * rounds)
+	challengeRepo.EXPECT().Add(u.ID, u.Username).Return("challenge", nil).Times(rounds)
+	challengeRepo.EXPECT().Get("challenge").Return(&twofactor.Challenge{UserID: u.ID, Username: u.Username}, nil).Times(rounds + 1)
+	twoFactorRepo.EXPECT().UseRecoveryCode(u.ID, "wrong-code").Return(twofactor.ErrInvalidCode).Times(rounds)
+	challengeRepo.EXPECT().Fail("challenge").Return(nil).Times(rounds)
+
+	login := func() int {
+		body, _ := json.Marshal(&handlers.AuthorizationRequest{Username: u.Username, Password: "password"})
+		req := httptest.NewRequest("POST", "/api/login", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		return w.Code
+	}
+	loginTwoFactor := func() int {
+		body, _ := json.Marshal(&handlers.TwoFactorLoginRequest{Challenge: "challenge", RecoveryCode: "wrong-code"})
+		req := httptest.NewRequest("POST", "/api/login/2fa", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.LoginTwoFactor(w, req)
+
+		return w.Code
+	}
+
+	for i := 0; i < rounds; i++ {
+		if code := login(); code != http.StatusOK {
+			t.Fatalf("round %d: expected resp status %d, got %d", i, http.StatusOK, code)
+		}
+		if code := loginTwoFactor(); code != http.StatusUnauthorized {
+			t.Fatalf("round %d: expected resp status %d, got %d", i, http.StatusUnauthorized, code)
+		}
+	}
+
+	// no new challenge and no more guesses at the old one
+	if code := login(); code != http.StatusTooManyRequests {
+		t.Errorf("expected resp status %d, got %d", http.StatusTooManyRequests, code)
+	}
+	if code := loginTwoFactor(); code != http.StatusTooManyRequests {
+		t.Errorf("expected resp status %d, got %d", http.StatusTooManyRequests, code)
+	}
+}
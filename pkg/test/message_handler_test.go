@@ -0,0 +1,280 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/message"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSendMessageCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	messageRepo := mock.NewMockMessageRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+
+	sender := &user.User{ID: 1, Username: "sender"}
+	recipient := &user.User{ID: 2, Username: "recipient"}
+	userRepo.EXPECT().GetByUsername("recipient").Return(recipient, nil)
+	messageRepo.EXPECT().Create(&message.Message{
+		SenderID:    1,
+		RecipientID: 2,
+		Subject:     "hello",
+		Body:        "how are you?",
+	}).DoAndReturn(func(m *message.Message) (uint, error) {
+		m.ID = 3
+		m.CreateDate = "2022-10-10T10:10:10Z"
+		return m.ID, nil
+	})
+	userRepo.EXPECT().GetByID(uint(1)).Return(sender, nil)
+	userRepo.EXPECT().GetByID(uint(2)).Return(recipient, nil)
+
+	b := bytes.NewBufferString(`{"to": "recipient", "subject": " hello ", "body": "how are you?"}`)
+	req := httptest.NewRequest("POST", "/api/messages", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "sender"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Send(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected resp status %d, got %d", http.StatusCreated, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	messages := make([]*handlers.MessageResponse, 0)
+	err = json.Unmarshal(body, &messages)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	expected := []*handlers.MessageResponse{
+		{
+			ID:         3,
+			Thread:     3,
+			From:       sender,
+			To:         recipient,
+			Subject:    "hello",
+			Body:       "how are you?",
+			CreateDate: "2022-10-10T10:10:10Z",
+		},
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, messages)
+	}
+}
+
+func TestSendMessageToSelfError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	messageRepo := mock.NewMockMessageRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+
+	userRepo.EXPECT().GetByUsername("sender").Return(&user.User{ID: 1, Username: "sender"}, nil)
+
+	b := bytes.NewBufferString(`{"to": "sender", "body": "note to self"}`)
+	req := httptest.NewRequest("POST", "/api/messages", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "sender"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Send(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestSendMessageEmptyBodyError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	messageRepo := mock.NewMockMessageRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+
+	b := bytes.NewBufferString(`{"to": "recipient", "body": "  "}`)
+	req := httptest.NewRequest("POST", "/api/messages", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "sender"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Send(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestSendMessageLongSubjectError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	messageRepo := mock.NewMockMessageRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+
+	// the subject is counted in characters, not bytes
+	body, _ := json.Marshal(&handlers.MessageRequest{
+		To:      "recipient",
+		Subject: strings.Repeat("ж", message.MaxSubjectLength+1),
+		Body:    "hello",
+	})
+	req := httptest.NewRequest("POST", "/api/messages", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "sender"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Send(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestReplyMessageCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	messageRepo := mock.NewMockMessageRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+
+	messageRepo.EXPECT().GetByID(uint(4), uint(2)).Return(&message.Message{
+		ID:          4,
+		ThreadID:    3,
+		SenderID:    1,
+		RecipientID: 2,
+		Subject:     "hello",
+		Body:        "second",
+	}, nil)
+	messageRepo.EXPECT().Create(&message.Message{
+		ThreadID:    3,
+		SenderID:    2,
+		RecipientID: 1,
+		Subject:     "hello",
+		Body:        "third",
+	}).Return(uint(5), nil)
+	userRepo.EXPECT().GetByID(uint(1)).Return(&user.User{ID: 1, Username: "sender"}, nil)
+	userRepo.EXPECT().GetByID(uint(2)).Return(&user.User{ID: 2, Username: "recipient"}, nil)
+
+	b := bytes.NewBufferString(`{"body": "third"}`)
+	req := httptest.NewRequest("POST", "/api/messages/4/reply", b)
+	req = mux.SetURLVars(req, map[string]string{"id": "4"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "recipient"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Reply(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected resp status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestMarkReadNoAccessError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	messageRepo := mock.NewMockMessageRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+
+	messageRepo.EXPECT().MarkRead(uint(3), uint(1)).Return(message.ErrNoAccess)
+
+	req := httptest.NewRequest("POST", "/api/messages/3/read", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "3"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "sender"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.MarkRead(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestDeleteMessageInvalidIDError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	messageRepo := mock.NewMockMessageRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewMessageHandler(messageRepo, userRepo, contextLogger)
+
+	req := httptest.NewRequest("DELETE", "/api/messages/abc", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "sender"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,360 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestLockPostCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	p := &post.Post{ID: "1", Category: "golang", AuthorID: 3}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	communityRepo.EXPECT().GetByName("golang").Return(&community.Community{Name: "golang", OwnerID: 1, Moderators: []uint{2}}, nil)
+	postRepo.EXPECT().SetLocked(p.ID, true).Return(nil)
+	modLogRepo.EXPECT().Add(&community.ModAction{
+		Community:   "golang",
+		ModeratorID: 2,
+		Action:      community.ActionLock,
+		TargetID:    p.ID,
+		PostID:      p.ID,
+		Reason:      "flame war",
+	}).Return(nil)
+
+	b := bytes.NewBufferString(`{"reason": "flame war"}`)
+	req := httptest.NewRequest("POST", "/api/mod/post/1/lock", b)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "moderator"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Lock(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestRemovePostNotModeratorError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	p := &post.Post{ID: "1", Category: "golang", AuthorID: 3}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	communityRepo.EXPECT().GetByName("golang").Return(&community.Community{Name: "golang", OwnerID: 1}, nil)
+
+	req := httptest.NewRequest("POST", "/api/mod/post/1/remove", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 3, Username: "author"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.RemovePost(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestAdminRemovePostCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	p := &post.Post{ID: "1", Category: "golang", AuthorID: 3}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	communityRepo.EXPECT().GetByName("golang").Return(&community.Community{Name: "golang", OwnerID: 1}, nil)
+	postRepo.EXPECT().Remove(p.ID).Return(nil)
+	reportRepo.EXPECT().Clear(community.TargetPost, p.ID).Return(nil)
+	searcher.EXPECT().RemoveByPost(p.ID).Return(nil)
+	modLogRepo.EXPECT().Add(gomock.Any()).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/post/1/remove", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 7, Username: "admin", Role: user.RoleAdmin})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.AdminRemovePost(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestBanCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	userRepo.EXPECT().GetByUsername("troll").Return(&user.User{ID: 5, Username: "troll"}, nil)
+	communityRepo.EXPECT().Ban("golang", uint(1), uint(5)).Return(nil)
+	modLogRepo.EXPECT().Add(gomock.Any()).Return(nil)
+
+	b := bytes.NewBufferString(`{"username": "troll", "reason": "spam"}`)
+	req := httptest.NewRequest("POST", "/api/community/golang/bans", b)
+	req = mux.SetURLVars(req, map[string]string{"name": "golang"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "owner"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Ban(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestUnbanNoAccessError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	userRepo.EXPECT().GetByUsername("troll").Return(&user.User{ID: 5, Username: "troll"}, nil)
+	communityRepo.EXPECT().Unban("golang", uint(4), uint(5)).Return(community.ErrNoAccess)
+
+	req := httptest.NewRequest("DELETE", "/api/community/golang/bans/troll", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "golang", "username": "troll"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 4, Username: "stranger"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Unban(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestGetModLogCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	expected := []*community.ModAction{
+		{
+			ID:          "2",
+			Community:   "golang",
+			ModeratorID: 1,
+			Action:      community.ActionBan,
+			TargetID:    "5",
+			Reason:      "spam",
+			CreateDate:  "2022-10-10T10:10:10Z",
+		},
+	}
+	communityRepo.EXPECT().GetByName("golang").Return(&community.Community{Name: "golang", OwnerID: 1}, nil)
+	userRepo.EXPECT().GetByUsername("owner").Return(&user.User{ID: 1, Username: "owner"}, nil)
+	modLogRepo.EXPECT().GetByCommunity("golang", community.ModLogFilter{
+		Action:      community.ActionBan,
+		ModeratorID: 1,
+		Limit:       10,
+	}).Return(expected, nil)
+
+	req := httptest.NewRequest("GET", "/api/community/golang/modlog?action=ban&moderator=owner&limit=10", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "golang"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "owner"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.GetModLog(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	actions := make([]*community.ModAction, 0)
+	err = json.Unmarshal(body, &actions)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, actions)
+	}
+}
+
+func TestApproveCommentCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	p := &post.Post{ID: "1", Category: "golang", CommentIDs: []string{"10"}}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	communityRepo.EXPECT().GetByName("golang").Return(&community.Community{Name: "golang", OwnerID: 1}, nil)
+	reportRepo.EXPECT().Clear(community.TargetComment, "10").Return(nil)
+	modLogRepo.EXPECT().Add(&community.ModAction{
+		Community:   "golang",
+		ModeratorID: 1,
+		Action:      community.ActionApproveComment,
+		TargetID:    "10",
+		PostID:      p.ID,
+	}).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/mod/post/1/10/approve", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID, "comment_id": "10"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "owner"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ApproveComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestGetReportsNotModeratorError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	modLogRepo := mock.NewMockModLogRepo(controller)
+	reportRepo := mock.NewMockReportRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	handler := handlers.NewModerationHandler(postRepo, commentRepo, communityRepo, modLogRepo, reportRepo, userRepo, searcher, event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	communityRepo.EXPECT().GetByName("golang").Return(&community.Community{Name: "golang", OwnerID: 1}, nil)
+
+	req := httptest.NewRequest("GET", "/api/community/golang/reports", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "golang"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.GetReports(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
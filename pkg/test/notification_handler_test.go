@@ -0,0 +1,238 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/notification"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetNotificationsCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewNotificationHandler(notificationRepo, userRepo, contextLogger)
+
+	actor := &user.User{ID: 2, Username: "actor"}
+	notificationRepo.EXPECT().GetByUser(uint(1), notification.Filter{UnreadOnly: true, Limit: 10}).Return([]*notification.Notification{
+		{
+			ID:         "3",
+			UserID:     1,
+			Type:       notification.TypeMention,
+			ActorID:    2,
+			PostID:     "post",
+			CommentID:  "comment",
+			CreateDate: "2022-10-10T10:10:10Z",
+		},
+		{
+			ID:         "2",
+			UserID:     1,
+			Type:       notification.TypePostReply,
+			ActorID:    2,
+			PostID:     "post",
+			CommentID:  "other",
+			CreateDate: "2022-10-10T10:10:09Z",
+		},
+		{
+			ID:         "1",
+			UserID:     1,
+			Type:       notification.TypeMilestone,
+			PostID:     "post",
+			Score:      10,
+			Key:        notification.MilestoneKey("post", "", 10),
+			CreateDate: "2022-10-10T10:10:08Z",
+		},
+	}, nil)
+	userRepo.EXPECT().GetByID(uint(2)).Return(actor, nil)
+
+	req := httptest.NewRequest("GET", "/api/notifications?unread=true&limit=10", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "user"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.GetAll(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	notifications := make([]*handlers.NotificationResponse, 0)
+	err = json.Unmarshal(body, &notifications)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	expected := []*handlers.NotificationResponse{
+		{
+			ID:         "3",
+			Type:       notification.TypeMention,
+			Actor:      actor,
+			PostID:     "post",
+			CommentID:  "comment",
+			CreateDate: "2022-10-10T10:10:10Z",
+		},
+		{
+			ID:         "2",
+			Type:       notification.TypePostReply,
+			Actor:      actor,
+			PostID:     "post",
+			CommentID:  "other",
+			CreateDate: "2022-10-10T10:10:09Z",
+		},
+		{
+			ID:         "1",
+			Type:       notification.TypeMilestone,
+			PostID:     "post",
+			Score:      10,
+			CreateDate: "2022-10-10T10:10:08Z",
+		},
+	}
+	if !reflect.DeepEqual(notifications, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, notifications)
+	}
+}
+
+func TestGetNotificationsLimitError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewNotificationHandler(notificationRepo, userRepo, contextLogger)
+
+	req := httptest.NewRequest("GET", "/api/notifications?limit=-1", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "user"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.GetAll(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestCountUnreadNotificationsCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewNotificationHandler(notificationRepo, userRepo, contextLogger)
+
+	notificationRepo.EXPECT().CountUnread(uint(1)).Return(4, nil)
+
+	req := httptest.NewRequest("GET", "/api/notifications/unread", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "user"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.CountUnread(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	count := make(map[string]int)
+	err := json.NewDecoder(resp.Body).Decode(&count)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+	if count["count"] != 4 {
+		t.Errorf("expected 4 unread notifications, got %d", count["count"])
+	}
+}
+
+func TestMarkNotificationReadNotExistError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewNotificationHandler(notificationRepo, userRepo, contextLogger)
+
+	notificationRepo.EXPECT().MarkRead("5", uint(1)).Return(notification.ErrNotExist)
+
+	req := httptest.NewRequest("POST", "/api/notifications/5/read", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "user"})
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"id": "5"})
+	w := httptest.NewRecorder()
+
+	handler.MarkRead(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestMarkAllNotificationsReadCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewNotificationHandler(notificationRepo, userRepo, contextLogger)
+
+	notificationRepo.EXPECT().MarkAllRead(uint(1)).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/notifications/read", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "user"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.MarkAllRead(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
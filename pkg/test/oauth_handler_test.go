@@ -0,0 +1,228 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/oauth"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	pkceVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	pkceChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	redirectURI   = "https://app.example.com/callback"
+)
+
+func authorizeQuery(clientID string, scope string) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", clientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", scope)
+	q.Set("state", "xyz")
+	q.Set("code_challenge", pkceChallenge)
+	q.Set("code_challenge_method", oauth.S256)
+
+	return q.Encode()
+}
+
+func tokenRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest("POST", "/api/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req
+}
+
+func TestOAuthAuthorizationCodeFlow(t *testing.T) {
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	clientRepo := oauth.NewMemoryClientRepo()
+	tokenRepo := oauth.NewMemoryTokenRepo(time.Hour, time.Hour)
+	handler := handlers.NewOAuthHandler(clientRepo, oauth.NewMemoryCodeRepo(time.Minute), tokenRepo, contextLogger)
+
+	client, _, err := clientRepo.Add(2, "bot", []string{redirectURI}, true)
+	if err != nil {
+		t.Fatalf("unable add client: %v", err)
+	}
+
+	body, _ := json.Marshal(&handlers.AuthorizeRequest{Approve: true})
+	req := httptest.NewRequest("POST", "/api/oauth/authorize?"+authorizeQuery(client.ID, "read vote"), bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Authorize(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, w.Code)
+	}
+	result := map[string]string{}
+	if err = json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	redirect, err := url.Parse(result["redirect"])
+	if err != nil || !strings.HasPrefix(result["redirect"], redirectURI+"?") {
+		t.Fatalf("wrong redirect: %s", result["redirect"])
+	}
+	if redirect.Query().Get("state") != "xyz" || redirect.Query().Get("code") == "" {
+		t.Fatalf("wrong redirect query: %s", redirect.RawQuery)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("client_id", client.ID)
+	form.Set("code", redirect.Query().Get("code"))
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", pkceVerifier)
+	w = httptest.NewRecorder()
+
+	handler.Token(w, tokenRequest(form))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w.Result().Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected json content type, got %q", w.Result().Header.Get("Content-Type"))
+	}
+	tokens := &handlers.TokenResponse{}
+	if err = json.NewDecoder(w.Body).Decode(tokens); err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.Scope != "read vote" || tokens.RefreshToken == "" {
+		t.Errorf("wrong tokens: %+v", tokens)
+	}
+
+	manager := session.NewManager(nil, nil, nil, tokenRepo)
+	sess, err := manager.Create("Bearer " + tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("access token isn't accepted: %v", err)
+	}
+	if sess.UserID != 1 || sess.ClientID != client.ID || !sess.Allows(session.ScopeVote) || sess.Allows(session.ScopePost) {
+		t.Errorf("wrong session: %+v", sess)
+	}
+
+	// the code works once
+	w = httptest.NewRecorder()
+	handler.Token(w, tokenRequest(form))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_grant") {
+		t.Errorf("code exchanged twice: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOAuthTokenWrongVerifier(t *testing.T) {
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	clientRepo := oauth.NewMemoryClientRepo()
+	codeRepo := oauth.NewMemoryCodeRepo(time.Minute)
+	handler := handlers.NewOAuthHandler(clientRepo, codeRepo, oauth.NewMemoryTokenRepo(time.Hour, time.Hour), contextLogger)
+
+	client, secret, err := clientRepo.Add(2, "bot", []string{redirectURI}, false)
+	if err != nil {
+		t.Fatalf("unable add client: %v", err)
+	}
+	code, err := codeRepo.Add(&oauth.Code{
+		ClientID:    client.ID,
+		UserID:      1,
+		Username:    "username",
+		RedirectURI: redirectURI,
+		Scopes:      []session.Scope{session.ScopeRead},
+		Challenge:   pkceChallenge,
+	})
+	if err != nil {
+		t.Fatalf("unable add code: %v", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", strings.Repeat("a", 43))
+
+	req := tokenRequest(form)
+	req.SetBasicAuth(client.ID, "wrong")
+	w := httptest.NewRecorder()
+	handler.Token(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected resp status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req = tokenRequest(form)
+	req.SetBasicAuth(client.ID, secret)
+	w = httptest.NewRecorder()
+	handler.Token(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid_grant") {
+		t.Errorf("wrong verifier accepted: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOAuthAuthorizeUnknownRedirect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	clientRepo := mock.NewMockClientRepo(controller)
+	codeRepo := mock.NewMockCodeRepo(controller)
+	tokenRepo := mock.NewMockTokenRepo(controller)
+	handler := handlers.NewOAuthHandler(clientRepo, codeRepo, tokenRepo, contextLogger)
+
+	clientRepo.EXPECT().Get("client").Return(&oauth.Client{ID: "client", Name: "bot", RedirectURIs: []string{"https://other.example.com/cb"}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/oauth/authorize?"+authorizeQuery("client", "read"), nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.GetAuthorize(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestRegisterOAuthClientBadRedirect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	clientRepo := mock.NewMockClientRepo(controller)
+	codeRepo := mock.NewMockCodeRepo(controller)
+	tokenRepo := mock.NewMockTokenRepo(controller)
+	handler := handlers.NewOAuthHandler(clientRepo, codeRepo, tokenRepo, contextLogger)
+
+	body, _ := json.Marshal(&handlers.ClientRequest{Name: "bot", RedirectURIs: []string{"http://app.example.com/cb"}})
+	req := httptest.NewRequest("POST", "/api/oauth/clients", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.RegisterClient(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
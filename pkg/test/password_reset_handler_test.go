@@ -0,0 +1,264 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/limiter"
+	"github.com/vlasdash/redditclone/internal/mail"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const resetURL = "http://localhost/reset?token="
+
+// chanMailer hands the sent messages over to the test, reset emails are sent
+// in the background.
+type chanMailer struct {
+	msgs chan *mail.Message
+	err  error
+}
+
+func newChanMailer(err error) *chanMailer {
+	return &chanMailer{
+		msgs: make(chan *mail.Message, 1),
+		err:  err,
+	}
+}
+
+func (m *chanMailer) Send(msg *mail.Message) error {
+	m.msgs <- msg
+	return m.err
+}
+
+func (m *chanMailer) wait(t *testing.T) *mail.Message {
+	select {
+	case msg := <-m.msgs:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no email sent")
+		return nil
+	}
+}
+
+func TestForgotPasswordCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	resetRepo := mock.NewMockResetRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	mailer := newChanMailer(nil)
+	handler := handlers.NewPasswordResetHandler(userRepo, resetRepo, sessionRepo, refreshRepo, hasher, mailer, resetURL, contextLogger, newResetLimiter())
+
+	u := &user.User{ID: 1, Username: "username", Email: "user@example.com"}
+	userRepo.EXPECT().GetByEmail(u.Email).Return(u, nil)
+	resetRepo.EXPECT().Add(u.ID).Return("secret", nil)
+
+	body, _ := json.Marshal(&handlers.ForgotPasswordRequest{Email: u.Email})
+	req := httptest.NewRequest("POST", "/api/password/forgot", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Forgot(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	msg := mailer.wait(t)
+	if msg.To != u.Email || !strings.Contains(msg.Body, resetURL+"secret") {
+		t.Errorf("wrong email sent: %+v", msg)
+	}
+}
+
+func TestForgotPasswordMailerFailure(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	// own logger, the failure is logged after the response is written
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	contextLogger := logrus.NewEntry(logger)
+
+	userRepo := mock.NewMockUserRepo(controller)
+	resetRepo := mock.NewMockResetRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	mailer := newChanMailer(fmt.Errorf("smtp is down"))
+	handler := handlers.NewPasswordResetHandler(userRepo, resetRepo, sessionRepo, refreshRepo, hasher, mailer, resetURL, contextLogger, newResetLimiter())
+
+	u := &user.User{ID: 1, Username: "username", Email: "user@example.com"}
+	userRepo.EXPECT().GetByEmail(u.Email).Return(u, nil)
+	resetRepo.EXPECT().Add(u.ID).Return("secret", nil)
+
+	body, _ := json.Marshal(&handlers.ForgotPasswordRequest{Email: u.Email})
+	req := httptest.NewRequest("POST", "/api/password/forgot", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Forgot(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	mailer.wait(t)
+}
+
+func TestForgotPasswordUnknownEmail(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	resetRepo := mock.NewMockResetRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	mailbox := &bytes.Buffer{}
+	handler := handlers.NewPasswordResetHandler(userRepo, resetRepo, sessionRepo, refreshRepo, hasher, mail.NewLogMailer(mailbox), resetURL, contextLogger, newResetLimiter())
+
+	userRepo.EXPECT().GetByEmail("ghost@example.com").Return(nil, user.ErrNoExist)
+
+	body, _ := json.Marshal(&handlers.ForgotPasswordRequest{Email: "ghost@example.com"})
+	req := httptest.NewRequest("POST", "/api/password/forgot", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Forgot(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if mailbox.Len() != 0 {
+		t.Errorf("expected no email, got %q", mailbox.String())
+	}
+}
+
+func TestForgotPasswordTooManyRequests(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	resetRepo := mock.NewMockResetRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	mailer := newChanMailer(nil)
+	handler := handlers.NewPasswordResetHandler(userRepo, resetRepo, sessionRepo, refreshRepo, hasher, mailer, resetURL, contextLogger, newResetLimiter())
+
+	free := limiter.ResetPolicies[limiter.KindEmail].Free
+	u := &user.User{ID: 1, Username: "username", Email: "user@example.com"}
+	userRepo.EXPECT().GetByEmail(u.Email).Return(u, nil).Times(free + 1)
+	resetRepo.EXPECT().Add(u.ID).Return("secret", nil).Times(free + 1)
+
+	forgot := func(email string) int {
+		body, _ := json.Marshal(&handlers.ForgotPasswordRequest{Email: email})
+		req := httptest.NewRequest("POST", "/api/password/forgot", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.Forgot(w, req)
+
+		return w.Code
+	}
+
+	for i := 0; i <= free; i++ {
+		if code := forgot(u.Email); code != http.StatusOK {
+			t.Fatalf("request %d: expected resp status %d, got %d", i, http.StatusOK, code)
+		}
+		mailer.wait(t)
+	}
+	// the case of the address doesn't make it another key
+	if code := forgot("User@Example.com"); code != http.StatusTooManyRequests {
+		t.Errorf("expected resp status %d, got %d", http.StatusTooManyRequests, code)
+	}
+}
+
+func TestResetPasswordCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	resetRepo := mock.NewMockResetRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewPasswordResetHandler(userRepo, resetRepo, sessionRepo, refreshRepo, hasher, mail.NewLogMailer(ioutil.Discard), resetURL, contextLogger, newResetLimiter())
+
+	resetRepo.EXPECT().Consume("secret").Return(uint(1), nil)
+	hasher.EXPECT().GetHashPassword("new").Return("hash", nil)
+	userRepo.EXPECT().UpdatePassword(uint(1), "hash").Return(nil)
+	sessionRepo.EXPECT().DeleteAll(uint(1)).Return(nil)
+	refreshRepo.EXPECT().DeleteAll(uint(1)).Return(nil)
+
+	body, _ := json.Marshal(&handlers.ResetPasswordRequest{Token: "secret", Password: "new"})
+	req := httptest.NewRequest("POST", "/api/password/reset", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Reset(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestResetPasswordBadToken(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	resetRepo := mock.NewMockResetRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewPasswordResetHandler(userRepo, resetRepo, sessionRepo, refreshRepo, hasher, mail.NewLogMailer(ioutil.Discard), resetURL, contextLogger, newResetLimiter())
+
+	resetRepo.EXPECT().Consume("used").Return(uint(0), user.ErrBadResetToken)
+
+	body, _ := json.Marshal(&handlers.ResetPasswordRequest{Token: "used", Password: "new"})
+	req := httptest.NewRequest("POST", "/api/password/reset", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Reset(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -10,7 +10,11 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/notification"
 	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/search"
 	"github.com/vlasdash/redditclone/internal/session"
 	"github.com/vlasdash/redditclone/internal/test/mock"
 	"github.com/vlasdash/redditclone/internal/user"
@@ -126,8 +130,13 @@ func TestGetListCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetAll().Return(test.Post, nil)
 	commentRepo.EXPECT().GetByID(test.Post[0].CommentIDs[0]).Return(test.Comment[0], nil)
@@ -173,8 +182,13 @@ func TestGetListPostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 	expectedErrMessage := "unable get posts from server"
 
 	postRepo.EXPECT().GetAll().Return(nil, fmt.Errorf("something went wrong"))
@@ -235,8 +249,13 @@ func TestGetListCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetAll().Return(test.Post, nil)
 	commentRepo.EXPECT().GetByID(test.Post[0].CommentIDs[0]).Return(nil, fmt.Errorf("something went wrong"))
@@ -263,6 +282,291 @@ func TestGetListCreateResponseError(t *testing.T) {
 	}
 }
 
+func TestGetListPageCorrect(t *testing.T) {
+	postID := primitive.NewObjectID()
+	test := TestPostCase{
+		Post: []*post.Post{
+			{
+				ID:             postID.Hex(),
+				Category:       "music",
+				CreateDate:     "10.09.2022",
+				Text:           "text",
+				Title:          "title",
+				Type:           "link",
+				Views:          0,
+				Votes:          make([]*post.Vote, 0),
+				CommentIDs:     make([]string, 0),
+				AuthorID:       1,
+				UpvotesCount:   0,
+				DownvotesCount: 0,
+			},
+		},
+		User: []*user.User{
+			{
+				ID:       1,
+				Username: "username",
+			},
+		},
+	}
+	expectedResponse := &handlers.PageResponse{
+		Posts: []*handlers.PostResponse{
+			{
+				ID:               test.Post[0].ID,
+				Category:         test.Post[0].Category,
+				CreateDate:       test.Post[0].CreateDate,
+				Text:             test.Post[0].Text,
+				URL:              test.Post[0].URL,
+				Title:            test.Post[0].Title,
+				Type:             test.Post[0].Type,
+				Score:            0,
+				UpvotePercentage: 0,
+				Views:            0,
+				Votes:            test.Post[0].Votes,
+				Comments:         make([]*handlers.CommentResponse, 0),
+				Author:           test.User[0],
+			},
+		},
+		Next: "next",
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	opts := post.PageOptions{
+		Limit:  1,
+		After:  "cursor",
+		Sort:   post.SortNew,
+		Period: post.PeriodAll,
+	}
+	postRepo.EXPECT().GetPage(opts).Return(&post.Page{Posts: test.Post, Next: "next"}, nil)
+	userRepo.EXPECT().GetByID(test.Post[0].AuthorID).Return(test.User[0], nil)
+
+	req := httptest.NewRequest("GET", "/api/posts/?limit=1&after=cursor", nil)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.GetList(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	pageResponse := &handlers.PageResponse{}
+	err = json.Unmarshal(body, pageResponse)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(pageResponse, expectedResponse) {
+		t.Errorf("wrong result, expected %#v, got %#v", expectedResponse, pageResponse)
+	}
+}
+
+func TestGetListPageInvalidLimit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+	expectedErrMessage := "limit must be a positive number"
+
+	req := httptest.NewRequest("GET", "/api/posts/?limit=-1", nil)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.GetList(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	if !bytes.Contains(body, []byte(expectedErrMessage)) {
+		t.Errorf("expected error message %s, got %s", expectedErrMessage, body)
+	}
+}
+
+func TestGetListPageInvalidCursor(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	opts := post.PageOptions{
+		Limit:  post.DefaultPageLimit,
+		After:  "cursor",
+		Sort:   post.SortNew,
+		Period: post.PeriodAll,
+	}
+	postRepo.EXPECT().GetPage(opts).Return(nil, post.ErrInvalidCursor)
+
+	req := httptest.NewRequest("GET", "/api/posts/?after=cursor", nil)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.GetList(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	if !bytes.Contains(body, []byte(post.ErrInvalidCursor.Error())) {
+		t.Errorf("expected error message %s, got %s", post.ErrInvalidCursor.Error(), body)
+	}
+}
+
+func TestGetListPageInvalidSort(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	for _, query := range []string{"sort=best", "sort=top&t=decade"} {
+		req := httptest.NewRequest("GET", "/api/posts/?"+query, nil)
+		req.Header.Add("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.GetList(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected resp status %d for %s, got %d", http.StatusBadRequest, query, resp.StatusCode)
+		}
+	}
+}
+
+func TestGetPostByCategoryTopCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	opts := post.PageOptions{
+		Limit:  post.DefaultPageLimit,
+		Sort:   post.SortTop,
+		Period: post.PeriodWeek,
+	}
+	postRepo.EXPECT().GetPageByCategory("music", opts).Return(&post.Page{Posts: make([]*post.Post, 0)}, nil)
+
+	req := httptest.NewRequest("GET", "/api/posts/music?sort=top&t=week", nil)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{
+		"category": "music",
+	}
+	req = mux.SetURLVars(req, vars)
+
+	handler.GetByCategory(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	pageResponse := &handlers.PageResponse{}
+	err = json.Unmarshal(body, pageResponse)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if len(pageResponse.Posts) != 0 || pageResponse.Next != "" {
+		t.Errorf("wrong result, expected empty page, got %#v", pageResponse)
+	}
+}
+
 func TestAddCorrect(t *testing.T) {
 	commentID := primitive.NewObjectID()
 	postID := primitive.NewObjectID()
@@ -339,8 +643,16 @@ func TestAddCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 
 	postRepo.EXPECT().Create(test.Post[0]).Return(test.Post[0].ID, nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -396,8 +708,13 @@ func TestAddSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	b := bytes.NewBufferString("bad body")
 	req := httptest.NewRequest("POST", "/api/posts/", b)
@@ -435,8 +752,13 @@ func TestAddUnmarshalError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	b := bytes.NewBufferString("bad body")
 	req := httptest.NewRequest("POST", "/api/posts/", b)
@@ -487,8 +809,13 @@ func TestAddReadBodyError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	req := httptest.NewRequest("POST", "/api/posts/", errPostReader{})
 	req.Header.Add("Content-Type", "application/json")
@@ -559,8 +886,15 @@ func TestAddCreateError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 
 	postRepo.EXPECT().Create(test.Post[0]).Return("", fmt.Errorf("something went wrong"))
 
@@ -639,8 +973,15 @@ func TestAddPostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 
 	postRepo.EXPECT().Create(test.Post[0]).Return(test.Post[0].ID, nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(nil, fmt.Errorf("something went wrong"))
@@ -730,8 +1071,16 @@ func TestAddCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 
 	postRepo.EXPECT().Create(test.Post[0]).Return(test.Post[0].ID, nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -841,8 +1190,13 @@ func TestGetPostCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 1).Return(test.Post[0], nil)
 	commentRepo.EXPECT().GetByID(test.Post[0].CommentIDs[0]).Return(test.Comment[0], nil)
@@ -914,8 +1268,13 @@ func TestGetPostNotFound(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 1).Return(nil, post.ErrNotExist)
 
@@ -983,8 +1342,13 @@ func TestGetPostPostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 1).Return(nil, fmt.Errorf("something went wrong"))
 
@@ -1069,8 +1433,13 @@ func TestGetPostCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 1).Return(test.Post[0], nil)
 	userRepo.EXPECT().GetByID(test.Post[0].AuthorID).Return(nil, fmt.Errorf("something went wrong"))
@@ -1155,8 +1524,13 @@ func TestGetPostByCategoryCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetByCategory(test.Post[0].Category).Return(test.Post, nil)
 	userRepo.EXPECT().GetByID(test.Post[0].AuthorID).Return(test.User[0], nil)
@@ -1249,8 +1623,13 @@ func TestGetPostByCategoryCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().GetByCategory(test.Post[0].Category).Return(test.Post, nil)
 	userRepo.EXPECT().GetByID(test.Post[0].AuthorID).Return(test.User[0], fmt.Errorf("something went wrong"))
@@ -1358,12 +1737,19 @@ func TestAddCommentCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
 	commentRepo.EXPECT().Add(test.User[0].ID, test.Comment[0].Body).Return(test.Comment[0].ID, nil)
 	postRepo.EXPECT().AddComment(test.Post[0].ID, test.Comment[0].ID).Return(nil)
-	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
+	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil).Times(2)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 	commentRepo.EXPECT().GetByID(test.Post[0].CommentIDs[0]).Return(test.Comment[0], nil)
 	userRepo.EXPECT().GetByID(test.Comment[0].AuthorID).Return(test.User[0], nil).Times(2)
 
@@ -1426,8 +1812,13 @@ func TestAddCommentSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	b := bytes.NewBufferString("body")
 	req := httptest.NewRequest("POST", fmt.Sprintf("/api/post/%s", primitive.NewObjectID()), b)
@@ -1465,8 +1856,13 @@ func TestAddCommentUnmarshalError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	b := bytes.NewBufferString("body")
 	req := httptest.NewRequest("POST", fmt.Sprintf("/api/post/%s", primitive.NewObjectID()), b)
@@ -1517,8 +1913,13 @@ func TestAddCommentReadBodyError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	req := httptest.NewRequest("POST", fmt.Sprintf("/api/post/%s", primitive.NewObjectID()), errPostReader{})
 	req.Header.Add("Content-Type", "application/json")
@@ -1598,9 +1999,16 @@ func TestAddCommentRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 	commentRepo.EXPECT().Add(test.User[0].ID, test.Comment[0].Body).Return("", fmt.Errorf("something went wrong"))
 
 	b := bytes.NewBufferString("")
@@ -1678,9 +2086,16 @@ func TestAddCommentPostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	postRepo.EXPECT().GetByID(postID.Hex(), 0).Return(&post.Post{ID: postID.Hex(), Category: "music"}, nil)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 	commentRepo.EXPECT().Add(test.User[0].ID, test.Comment[0].Body).Return(test.Comment[0].ID, nil)
 	postRepo.EXPECT().AddComment(postID.Hex(), test.Comment[0].ID).Return(post.ErrNotExist)
 
@@ -1784,12 +2199,22 @@ func TestAddCommentGetPostError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
 	commentRepo.EXPECT().Add(test.User[0].ID, test.Comment[0].Body).Return(test.Comment[0].ID, nil)
 	postRepo.EXPECT().AddComment(test.Post[0].ID, test.Comment[0].ID).Return(nil)
-	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(nil, fmt.Errorf("something went wrong"))
+	gomock.InOrder(
+		postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil),
+		postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(nil, fmt.Errorf("something went wrong")),
+	)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 
 	b := bytes.NewBufferString("")
 	commentReq := &handlers.CommentRequest{
@@ -1885,12 +2310,19 @@ func TestAddCommentCreateRequestError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
 	commentRepo.EXPECT().Add(test.User[0].ID, test.Comment[0].Body).Return(test.Comment[0].ID, nil)
 	postRepo.EXPECT().AddComment(test.Post[0].ID, test.Comment[0].ID).Return(nil)
-	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
+	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil).Times(2)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
 	userRepo.EXPECT().GetByID(test.Comment[0].AuthorID).Return(nil, fmt.Errorf("something went wrong"))
 
 	b := bytes.NewBufferString("")
@@ -1994,8 +2426,13 @@ func TestDownvoteCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Downvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -2051,8 +2488,13 @@ func TestDownvoteSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	req := httptest.NewRequest("GET", fmt.Sprintf("/post/%s/downvote", primitive.NewObjectID()), nil)
 	req.Header.Add("Content-Type", "application/json")
@@ -2113,8 +2555,13 @@ func TestDownvotePostNotFoundError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Downvote(test.Post[0].ID, test.User[0].ID).Return(post.ErrNotExist)
 
@@ -2200,8 +2647,13 @@ func TestDownvoteError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Downvote(test.Post[0].ID, test.User[0].ID).Return(fmt.Errorf("something went wrong"))
 
@@ -2281,8 +2733,13 @@ func TestDownvotePostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Downvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(nil, fmt.Errorf("something went wrong"))
@@ -2363,8 +2820,13 @@ func TestDownvoteCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Downvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -2462,8 +2924,13 @@ func TestUpvoteCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Upvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -2519,8 +2986,13 @@ func TestUpvoteSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	req := httptest.NewRequest("GET", fmt.Sprintf("/post/%s/upvote", primitive.NewObjectID()), nil)
 	req.Header.Add("Content-Type", "application/json")
@@ -2581,8 +3053,13 @@ func TestUpvotePostNotFoundError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Upvote(test.Post[0].ID, test.User[0].ID).Return(post.ErrNotExist)
 
@@ -2668,8 +3145,13 @@ func TestUpvoteError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Upvote(test.Post[0].ID, test.User[0].ID).Return(fmt.Errorf("something went wrong"))
 
@@ -2749,8 +3231,13 @@ func TestUpvotePostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Upvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(nil, fmt.Errorf("something went wrong"))
@@ -2831,8 +3318,13 @@ func TestUpvoteCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Upvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -2925,8 +3417,13 @@ func TestUnvoteCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Unvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -2982,8 +3479,13 @@ func TestUnvoteSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	req := httptest.NewRequest("GET", fmt.Sprintf("/post/%s/unvote", primitive.NewObjectID()), nil)
 	req.Header.Add("Content-Type", "application/json")
@@ -3039,8 +3541,13 @@ func TestUnvoteError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Unvote(test.Post[0].ID, test.User[0].ID).Return(post.ErrNotExist)
 
@@ -3121,8 +3628,13 @@ func TestUnvotePostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Unvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(nil, fmt.Errorf("something went wrong"))
@@ -3198,8 +3710,13 @@ func TestUnvoteCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	postRepo.EXPECT().Unvote(test.Post[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -3262,9 +3779,16 @@ func TestDeleteCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	searcher.EXPECT().RemoveByPost(gomock.Any()).Return(nil)
+	postRepo.EXPECT().GetByID(postID, 0).Return(&post.Post{ID: postID, Category: "music"}, nil)
 	postRepo.EXPECT().Delete(postID, test.User[0].ID).Return(nil)
 
 	req := httptest.NewRequest("DELETE", fmt.Sprintf("/post/%s", postID), nil)
@@ -3317,8 +3841,13 @@ func TestDeleteSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	req := httptest.NewRequest("DELETE", fmt.Sprintf("/post/%s", primitive.NewObjectID()), nil)
 	req.Header.Add("Content-Type", "application/json")
@@ -3358,9 +3887,15 @@ func TestDeleteError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	postRepo.EXPECT().GetByID(postID, 0).Return(&post.Post{ID: postID, Category: "music"}, nil)
 	postRepo.EXPECT().Delete(postID, test.User[0].ID).Return(post.ErrNotExist)
 
 	req := httptest.NewRequest("DELETE", fmt.Sprintf("/post/%s", postID), nil)
@@ -3424,9 +3959,15 @@ func TestDeletePostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	postRepo.EXPECT().GetByID(postID, 0).Return(&post.Post{ID: postID, Category: "music"}, nil)
 	postRepo.EXPECT().Delete(postID, test.User[0].ID).Return(fmt.Errorf("something went wrong"))
 
 	req := httptest.NewRequest("DELETE", fmt.Sprintf("/post/%s", postID), nil)
@@ -3538,9 +4079,15 @@ func TestDeleteCommentCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	searcher.EXPECT().Remove(search.TypeComment, gomock.Any()).Return(nil)
 	commentRepo.EXPECT().Delete(test.Comment[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().DeleteComment(test.Post[0].ID, test.Comment[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -3598,8 +4145,13 @@ func TestDeleteCommentSessionError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	req := httptest.NewRequest("DELETE", fmt.Sprintf("/post/%s/%s", primitive.NewObjectID(), primitive.NewObjectID()), nil)
 	req.Header.Add("Content-Type", "application/json")
@@ -3669,8 +4221,13 @@ func TestDeleteCommentNotFound(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	commentRepo.EXPECT().Delete(test.Comment[0].ID, test.User[0].ID).Return(comment.ErrNotExist)
 
@@ -3765,8 +4322,13 @@ func TestDeleteCommentError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	commentRepo.EXPECT().Delete(test.Comment[0].ID, test.User[0].ID).Return(fmt.Errorf("something went wrong"))
 
@@ -3858,8 +4420,13 @@ func TestDeleteCommentPostNotFound(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	commentRepo.EXPECT().Delete(test.Comment[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().DeleteComment(test.Post[0].ID, test.Comment[0].ID).Return(post.ErrNotExist)
@@ -3956,9 +4523,15 @@ func TestDeleteCommentGetError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	searcher.EXPECT().Remove(search.TypeComment, gomock.Any()).Return(nil)
 	commentRepo.EXPECT().Delete(test.Comment[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().DeleteComment(test.Post[0].ID, test.Comment[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(nil, fmt.Errorf("something went wrong"))
@@ -4050,9 +4623,15 @@ func TestDeleteCommentCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
+	searcher.EXPECT().Remove(search.TypeComment, gomock.Any()).Return(nil)
 	commentRepo.EXPECT().Delete(test.Comment[0].ID, test.User[0].ID).Return(nil)
 	postRepo.EXPECT().DeleteComment(test.Post[0].ID, test.Comment[0].ID).Return(nil)
 	postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil)
@@ -4147,8 +4726,13 @@ func TestGetPostByUsernameCorrect(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	userRepo.EXPECT().GetByUsername(test.User[0].Username).Return(test.User[0], nil)
 	postRepo.EXPECT().GetByAuthor(test.User[0].ID).Return(test.Post, nil)
@@ -4208,8 +4792,13 @@ func TestGetPostByUsernameUserRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	userRepo.EXPECT().GetByUsername(test.User[0].Username).Return(nil, fmt.Errorf("something went wrong"))
 
@@ -4261,8 +4850,13 @@ func TestGetPostByUsernamePostRepoError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	userRepo.EXPECT().GetByUsername(test.User[0].Username).Return(test.User[0], nil)
 	postRepo.EXPECT().GetByAuthor(test.User[0].ID).Return(nil, fmt.Errorf("something went wrong"))
@@ -4332,8 +4926,13 @@ func TestGetPostByUsernameCreateResponseError(t *testing.T) {
 
 	userRepo := mock.NewMockUserRepo(controller)
 	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
 	postRepo := mock.NewMockPostRepo(controller)
-	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
 
 	userRepo.EXPECT().GetByUsername(test.User[0].Username).Return(test.User[0], nil)
 	postRepo.EXPECT().GetByAuthor(test.User[0].ID).Return(test.Post, nil)
@@ -4365,3 +4964,1308 @@ func TestGetPostByUsernameCreateResponseError(t *testing.T) {
 		t.Errorf("expected error message %s, got %s", expectedErrMessage, body)
 	}
 }
+
+func TestAddReplyCorrect(t *testing.T) {
+	parentID := primitive.NewObjectID()
+	replyID := primitive.NewObjectID()
+	postID := primitive.NewObjectID()
+	test := TestPostCase{
+		Comment: []*comment.Comment{
+			{
+				ID:         parentID.Hex(),
+				AuthorID:   1,
+				CreateDate: "10.10.2022",
+				Body:       "body",
+			},
+			{
+				ID:         replyID.Hex(),
+				AuthorID:   1,
+				CreateDate: "10.11.2022",
+				Body:       "reply",
+				ParentID:   parentID.Hex(),
+			},
+		},
+		Post: []*post.Post{
+			{
+				ID:         postID.Hex(),
+				Category:   "music",
+				CreateDate: "10.09.2022",
+				Title:      "title",
+				Type:       "text",
+				Votes:      make([]*post.Vote, 0),
+				CommentIDs: []string{parentID.Hex()},
+				AuthorID:   1,
+			},
+		},
+		User: []*user.User{
+			{
+				ID:       1,
+				Username: "username",
+			},
+		},
+	}
+	updatedPost := *test.Post[0]
+	updatedPost.CommentIDs = []string{parentID.Hex(), replyID.Hex()}
+	expected := &handlers.PostResponse{
+		ID:         test.Post[0].ID,
+		Category:   test.Post[0].Category,
+		CreateDate: test.Post[0].CreateDate,
+		Title:      test.Post[0].Title,
+		Type:       test.Post[0].Type,
+		Votes:      test.Post[0].Votes,
+		Comments: []*handlers.CommentResponse{
+			{
+				ID:         test.Comment[0].ID,
+				Author:     test.User[0],
+				CreateDate: test.Comment[0].CreateDate,
+				Body:       test.Comment[0].Body,
+				Replies: []*handlers.CommentResponse{
+					{
+						ID:         test.Comment[1].ID,
+						Author:     test.User[0],
+						CreateDate: test.Comment[1].CreateDate,
+						Body:       test.Comment[1].Body,
+						ParentID:   test.Comment[0].ID,
+					},
+				},
+			},
+		},
+		Author: test.User[0],
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
+	gomock.InOrder(
+		postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(test.Post[0], nil),
+		postRepo.EXPECT().GetByID(test.Post[0].ID, 0).Return(&updatedPost, nil),
+	)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
+	commentRepo.EXPECT().AddReply(test.User[0].ID, parentID.Hex(), test.Comment[1].Body).Return(replyID.Hex(), nil)
+	postRepo.EXPECT().AddComment(test.Post[0].ID, replyID.Hex()).Return(nil)
+	commentRepo.EXPECT().GetByID(parentID.Hex()).Return(test.Comment[0], nil).Times(2)
+	commentRepo.EXPECT().GetByID(replyID.Hex()).Return(test.Comment[1], nil)
+	userRepo.EXPECT().GetByID(test.User[0].ID).Return(test.User[0], nil).Times(3)
+
+	b := bytes.NewBufferString("")
+	err := json.NewEncoder(b).Encode(&handlers.CommentRequest{Body: test.Comment[1].Body})
+	if err != nil {
+		t.Fatalf("unable encode json: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/post/%s/%s/reply", postID.Hex(), parentID.Hex()), b)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	sess := &session.Session{
+		UserID:   test.User[0].ID,
+		Username: test.User[0].Username,
+	}
+	ctx := session.CreateContextWithSession(req.Context(), sess)
+	req = req.WithContext(ctx)
+	vars := map[string]string{
+		"id":         postID.Hex(),
+		"comment_id": parentID.Hex(),
+	}
+	req = mux.SetURLVars(req, vars)
+
+	handler.AddReply(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected resp status %d, got %d", http.StatusCreated, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	postResponse := &handlers.PostResponse{}
+	err = json.Unmarshal(body, postResponse)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(postResponse, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, postResponse)
+	}
+}
+
+func TestAddReplyParentNotFound(t *testing.T) {
+	postID := primitive.NewObjectID()
+	p := &post.Post{
+		ID:         postID.Hex(),
+		CommentIDs: make([]string, 0),
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/post/%s/1/reply", p.ID), bytes.NewBufferString(`{"comment": "reply"}`))
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	vars := map[string]string{
+		"id":         p.ID,
+		"comment_id": "1",
+	}
+	req = mux.SetURLVars(req, vars)
+
+	handler.AddReply(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	if !bytes.Contains(body, []byte(comment.ErrNotExist.Error())) {
+		t.Errorf("expected error message %s, got %s", comment.ErrNotExist.Error(), body)
+	}
+}
+
+func TestGetCommentThreadDepthLimit(t *testing.T) {
+	u := &user.User{
+		ID:       1,
+		Username: "username",
+	}
+	comments := []*comment.Comment{
+		{ID: "1", AuthorID: u.ID, CreateDate: "1", Body: "root"},
+		{ID: "2", AuthorID: u.ID, CreateDate: "2", Body: "first", ParentID: "1"},
+		{ID: "3", AuthorID: u.ID, CreateDate: "3", Body: "second", ParentID: "2"},
+		{ID: "4", AuthorID: u.ID, CreateDate: "4", Body: "third", ParentID: "3"},
+	}
+	p := &post.Post{
+		ID:         "1",
+		CommentIDs: []string{"1", "2", "3", "4"},
+	}
+	expected := &handlers.CommentResponse{
+		ID:         "2",
+		Author:     u,
+		CreateDate: "2",
+		Body:       "first",
+		ParentID:   "1",
+		Replies: []*handlers.CommentResponse{
+			{
+				ID:         "3",
+				Author:     u,
+				CreateDate: "3",
+				Body:       "second",
+				ParentID:   "2",
+				More:       "3",
+			},
+		},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	for _, c := range comments {
+		commentRepo.EXPECT().GetByID(c.ID).Return(c, nil)
+	}
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil).Times(len(comments))
+
+	req := httptest.NewRequest("GET", "/api/post/1/comments/2?depth=1", nil)
+	w := httptest.NewRecorder()
+
+	vars := map[string]string{
+		"id":         p.ID,
+		"comment_id": "2",
+	}
+	req = mux.SetURLVars(req, vars)
+
+	handler.GetCommentThread(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	commentResponse := &handlers.CommentResponse{}
+	err = json.Unmarshal(body, commentResponse)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(commentResponse, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, commentResponse)
+	}
+}
+
+func TestUpvoteCommentCorrect(t *testing.T) {
+	u := &user.User{
+		ID:       1,
+		Username: "username",
+	}
+	p := &post.Post{
+		ID:         "1",
+		CommentIDs: []string{"1"},
+	}
+	c := &comment.Comment{
+		ID:         "1",
+		AuthorID:   u.ID,
+		CreateDate: "10.10.2022",
+		Body:       "body",
+		Votes: []*comment.Vote{
+			{
+				UserID: 2,
+				Value:  comment.Unlike,
+			},
+			{
+				UserID: u.ID,
+				Value:  comment.Like,
+			},
+		},
+		UpvotesCount:   1,
+		DownvotesCount: 1,
+	}
+	expected := &handlers.CommentResponse{
+		ID:         c.ID,
+		Author:     u,
+		CreateDate: c.CreateDate,
+		Body:       c.Body,
+		Score:      0,
+		Votes:      c.Votes,
+		UserVote:   comment.Like,
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	commentRepo.EXPECT().Upvote(c.ID, u.ID).Return(nil)
+	commentRepo.EXPECT().GetByID(c.ID).Return(c, nil)
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+
+	req := httptest.NewRequest("GET", "/api/post/1/1/upvote", nil)
+	w := httptest.NewRecorder()
+
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: u.ID, Username: u.Username})
+	req = req.WithContext(ctx)
+	vars := map[string]string{
+		"id":         p.ID,
+		"comment_id": c.ID,
+	}
+	req = mux.SetURLVars(req, vars)
+
+	handler.UpvoteComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	commentResponse := &handlers.CommentResponse{}
+	err = json.Unmarshal(body, commentResponse)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(commentResponse, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, commentResponse)
+	}
+}
+
+func TestUnvoteCommentNoVoteError(t *testing.T) {
+	p := &post.Post{
+		ID:         "1",
+		CommentIDs: []string{"1"},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	commentRepo.EXPECT().Unvote("1", uint(1)).Return(comment.ErrVoteNotExist)
+
+	req := httptest.NewRequest("GET", "/api/post/1/1/unvote", nil)
+	w := httptest.NewRecorder()
+
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	vars := map[string]string{
+		"id":         p.ID,
+		"comment_id": "1",
+	}
+	req = mux.SetURLVars(req, vars)
+
+	handler.UnvoteComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	if !bytes.Contains(body, []byte(comment.ErrVoteNotExist.Error())) {
+		t.Errorf("expected error message %s, got %s", comment.ErrVoteNotExist.Error(), body)
+	}
+}
+
+func TestDownvoteCommentSessionError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	req := httptest.NewRequest("GET", "/api/post/1/1/downvote", nil)
+	w := httptest.NewRecorder()
+
+	handler.DownvoteComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected resp status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestEditPostCorrect(t *testing.T) {
+	u := &user.User{
+		ID:       1,
+		Username: "username",
+	}
+	p := &post.Post{
+		ID:         "1",
+		AuthorID:   u.ID,
+		Title:      "title",
+		Text:       "text",
+		Type:       "text",
+		CommentIDs: []string{},
+		Votes:      []*post.Vote{},
+	}
+	edited := &post.Post{
+		ID:         p.ID,
+		AuthorID:   u.ID,
+		Title:      "new title",
+		Text:       p.Text,
+		Type:       p.Type,
+		CommentIDs: []string{},
+		Votes:      []*post.Vote{},
+		Edited:     "2022-10-10T10:10:10Z",
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
+	gomock.InOrder(
+		postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil),
+		postRepo.EXPECT().Update(p.ID, u.ID, edited.Title, p.Text, p.URL).Return(nil),
+		postRepo.EXPECT().GetByID(p.ID, 0).Return(edited, nil),
+	)
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+
+	reqBody := bytes.NewBufferString(`{"title": "new title"}`)
+	req := httptest.NewRequest("PUT", "/api/post/1", reqBody)
+	w := httptest.NewRecorder()
+
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: u.ID, Username: u.Username})
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+
+	handler.Edit(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	postResponse := &handlers.PostResponse{}
+	err = json.Unmarshal(body, postResponse)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if postResponse.Title != edited.Title || postResponse.Edited != edited.Edited {
+		t.Errorf("wrong result, expected title %q edited %q, got %#v", edited.Title, edited.Edited, postResponse)
+	}
+}
+
+func TestEditPostNoAccessError(t *testing.T) {
+	p := &post.Post{
+		ID:       "1",
+		AuthorID: 2,
+		Title:    "title",
+		Type:     "text",
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	postRepo.EXPECT().Update(p.ID, uint(1), "new title", "", "").Return(post.ErrNoEditAccess)
+
+	reqBody := bytes.NewBufferString(`{"title": "new title"}`)
+	req := httptest.NewRequest("PUT", "/api/post/1", reqBody)
+	w := httptest.NewRecorder()
+
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+
+	handler.Edit(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestEditPostEmptyTitleError(t *testing.T) {
+	p := &post.Post{
+		ID:       "1",
+		AuthorID: 1,
+		Title:    "title",
+		Type:     "text",
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+
+	reqBody := bytes.NewBufferString(`{"title": ""}`)
+	req := httptest.NewRequest("PUT", "/api/post/1", reqBody)
+	w := httptest.NewRecorder()
+
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+
+	handler.Edit(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestEditCommentCorrect(t *testing.T) {
+	u := &user.User{
+		ID:       1,
+		Username: "username",
+	}
+	p := &post.Post{
+		ID:         "1",
+		AuthorID:   u.ID,
+		CommentIDs: []string{"1"},
+		Votes:      []*post.Vote{},
+	}
+	c := &comment.Comment{
+		ID:       "1",
+		AuthorID: u.ID,
+		Body:     "new body",
+		Edited:   "2022-10-10T10:10:10Z",
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	commentRepo.EXPECT().Update(c.ID, u.ID, c.Body).Return(nil)
+	commentRepo.EXPECT().GetByID(c.ID).Return(c, nil)
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil).AnyTimes()
+
+	reqBody := bytes.NewBufferString(`{"comment": "new body"}`)
+	req := httptest.NewRequest("PUT", "/api/post/1/1", reqBody)
+	w := httptest.NewRecorder()
+
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: u.ID, Username: u.Username})
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID, "comment_id": c.ID})
+
+	handler.EditComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	postResponse := &handlers.PostResponse{}
+	err = json.Unmarshal(body, postResponse)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if len(postResponse.Comments) != 1 || postResponse.Comments[0].Edited != c.Edited {
+		t.Errorf("wrong result, expected edited comment, got %#v", postResponse.Comments)
+	}
+}
+
+func TestGetRevisionsCorrect(t *testing.T) {
+	expected := []*post.Revision{
+		{
+			Title:      "title",
+			Text:       "text",
+			CreateDate: "10.10.2022",
+		},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().GetRevisions("1").Return(expected, nil)
+
+	req := httptest.NewRequest("GET", "/api/post/1/revisions", nil)
+	w := httptest.NewRecorder()
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	handler.GetRevisions(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unable read body response: %v", err)
+	}
+
+	revisions := make([]*post.Revision, 0)
+	err = json.Unmarshal(body, &revisions)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	if !reflect.DeepEqual(revisions, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, revisions)
+	}
+}
+
+func TestAddCommunityError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	restricted := &community.Community{
+		Name:    "golang",
+		OwnerID: 2,
+		Settings: community.Settings{
+			Restricted: true,
+		},
+	}
+	textOnly := &community.Community{
+		Name: "books",
+		Settings: community.Settings{
+			AllowedTypes: []string{community.TypeText},
+		},
+	}
+	communityRepo.EXPECT().GetByName("unknown").Return(nil, community.ErrNotExist)
+	communityRepo.EXPECT().GetByName("golang").Return(restricted, nil)
+	communityRepo.EXPECT().GetByName("books").Return(textOnly, nil)
+
+	bodies := []string{
+		`{"category": "unknown", "type": "text", "title": "title", "text": "text"}`,
+		`{"category": "golang", "type": "text", "title": "title", "text": "text"}`,
+		`{"category": "books", "type": "link", "title": "title", "url": "http://example.com"}`,
+	}
+	for _, b := range bodies {
+		req := httptest.NewRequest("POST", "/api/posts", bytes.NewBufferString(b))
+		req.Header.Add("Content-Type", "application/json")
+		ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+		w := httptest.NewRecorder()
+
+		handler.Add(w, req.WithContext(ctx))
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected resp status %d for %s, got %d", http.StatusBadRequest, b, resp.StatusCode)
+		}
+	}
+}
+
+func TestAddCommentLockedError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	p := &post.Post{ID: "1", Category: "music", Locked: true}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+
+	b := bytes.NewBufferString(`{"comment": "body"}`)
+	req := httptest.NewRequest("POST", "/api/post/1", b)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.AddComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestFeedAnonymousCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	opts := post.PageOptions{Limit: post.DefaultPageLimit}
+	postRepo.EXPECT().GetPageByCategories(community.DefaultNames, opts).Return(&post.Page{Posts: make([]*post.Post, 0)}, nil)
+
+	req := httptest.NewRequest("GET", "/api/feed", nil)
+	w := httptest.NewRecorder()
+
+	handler.Feed(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestFeedSubscriptionsCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	opts := post.PageOptions{
+		Limit:  post.DefaultPageLimit,
+		Sort:   post.SortTop,
+		Period: post.PeriodAll,
+	}
+	subscriptionRepo.EXPECT().GetByUser(uint(1)).Return([]string{"golang", "news"}, nil)
+	postRepo.EXPECT().GetPageByCategories([]string{"golang", "news"}, opts).Return(&post.Page{Posts: make([]*post.Post, 0)}, nil)
+
+	req := httptest.NewRequest("GET", "/api/feed?sort=top", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Feed(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestAddCommentNotifications(t *testing.T) {
+	commentID := primitive.NewObjectID()
+	postID := primitive.NewObjectID()
+	author := &user.User{ID: 2, Username: "author"}
+	commenter := &user.User{ID: 1, Username: "username"}
+	c := &comment.Comment{
+		ID:         commentID.Hex(),
+		AuthorID:   commenter.ID,
+		CreateDate: "10.10.2022",
+		Body:       "thanks u/author, also /u/friend and u/ghost",
+	}
+	p := &post.Post{
+		ID:         postID.Hex(),
+		Category:   "music",
+		CreateDate: "10.09.2022",
+		Title:      "title",
+		Type:       "text",
+		Votes:      make([]*post.Vote, 0),
+		CommentIDs: []string{commentID.Hex()},
+		AuthorID:   author.ID,
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	searcher.EXPECT().Index(gomock.Any()).Return(nil)
+	commentRepo.EXPECT().Add(commenter.ID, c.Body).Return(c.ID, nil)
+	postRepo.EXPECT().AddComment(p.ID, c.ID).Return(nil)
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil).Times(2)
+	communityRepo.EXPECT().GetByName("music").Return(&community.Community{Name: "music"}, nil)
+	notificationRepo.EXPECT().Add(&notification.Notification{
+		UserID:    author.ID,
+		Type:      notification.TypePostReply,
+		ActorID:   commenter.ID,
+		PostID:    p.ID,
+		CommentID: c.ID,
+	}).Return(nil)
+	userRepo.EXPECT().GetByUsername("author").Return(author, nil)
+	userRepo.EXPECT().GetByUsername("friend").Return(&user.User{ID: 3, Username: "friend"}, nil)
+	userRepo.EXPECT().GetByUsername("ghost").Return(nil, user.ErrNoExist)
+	notificationRepo.EXPECT().Add(&notification.Notification{
+		UserID:    3,
+		Type:      notification.TypeMention,
+		ActorID:   commenter.ID,
+		PostID:    p.ID,
+		CommentID: c.ID,
+	}).Return(errors.New("db error"))
+	commentRepo.EXPECT().GetByID(c.ID).Return(c, nil)
+	userRepo.EXPECT().GetByID(commenter.ID).Return(commenter, nil)
+	userRepo.EXPECT().GetByID(author.ID).Return(author, nil)
+
+	b := bytes.NewBufferString("")
+	err := json.NewEncoder(b).Encode(&handlers.CommentRequest{Body: c.Body})
+	if err != nil {
+		t.Fatalf("unable encode json: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/post/%s", p.ID), b)
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	sess := &session.Session{
+		UserID:   commenter.ID,
+		Username: commenter.Username,
+	}
+	ctx := session.CreateContextWithSession(req.Context(), sess)
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{
+		"id": p.ID,
+	})
+
+	postEvents := hub.Subscribe(event.PostTopic(p.ID))
+	defer hub.Unsubscribe(postEvents)
+	userEvents := hub.Subscribe(event.UserTopic(author.ID), event.UserTopic(3))
+	defer hub.Unsubscribe(userEvents)
+
+	handler.AddComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected resp status %d, got %d", http.StatusCreated, resp.StatusCode)
+		return
+	}
+
+	select {
+	case e := <-postEvents.C:
+		if e.Type != event.TypeCommentAdded || e.CommentID != c.ID {
+			t.Errorf("wrong post event, got %#v", e)
+		}
+	default:
+		t.Errorf("expected comment event to be published")
+	}
+
+	// the failed mention must not be pushed
+	select {
+	case e := <-userEvents.C:
+		data, ok := e.Data.(*handlers.NotificationResponse)
+		if e.Type != event.TypeNotification || !ok || data.Type != notification.TypePostReply || data.Actor.Username != commenter.Username {
+			t.Errorf("wrong notification event, got %#v", e)
+		}
+	default:
+		t.Errorf("expected notification event to be published")
+	}
+	if len(userEvents.C) != 0 {
+		t.Errorf("expected a single notification event, got %d more", len(userEvents.C))
+	}
+}
+
+func TestUpvoteMilestoneNotification(t *testing.T) {
+	postID := primitive.NewObjectID()
+	author := &user.User{ID: 2, Username: "author"}
+	p := &post.Post{
+		ID:           postID.Hex(),
+		Category:     "music",
+		CreateDate:   "10.09.2022",
+		Title:        "title",
+		Type:         "text",
+		Votes:        make([]*post.Vote, 0),
+		CommentIDs:   make([]string, 0),
+		AuthorID:     author.ID,
+		UpvotesCount: 52,
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	postRepo.EXPECT().Upvote(p.ID, uint(1)).Return(nil)
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	notificationRepo.EXPECT().Add(&notification.Notification{
+		UserID: author.ID,
+		Type:   notification.TypeMilestone,
+		PostID: p.ID,
+		Score:  50,
+		Key:    notification.MilestoneKey(p.ID, "", 50),
+	}).Return(notification.ErrAlreadyExist)
+	userRepo.EXPECT().GetByID(author.ID).Return(author, nil)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/post/%s/upvote", p.ID), nil)
+	w := httptest.NewRecorder()
+
+	sess := &session.Session{
+		UserID:   1,
+		Username: "username",
+	}
+	ctx := session.CreateContextWithSession(req.Context(), sess)
+	req = req.WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{
+		"id": p.ID,
+	})
+
+	handler.Upvote(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestGetCommentsByUsernameCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	author := &user.User{ID: 1, Username: "username"}
+	c := &comment.Comment{ID: "c1", AuthorID: 1, Body: "body", UpvotesCount: 1}
+	opts := comment.PageOptions{Limit: 1, Sort: comment.SortTop}
+	userRepo.EXPECT().GetByUsername("username").Return(author, nil)
+	commentRepo.EXPECT().GetPageByAuthor(uint(1), opts).Return(&comment.Page{Comments: []*comment.Comment{c}, Next: "next"}, nil)
+	postRepo.EXPECT().GetByCommentIDs([]string{"c1"}).Return([]*post.Post{{ID: "p1", Title: "title", Category: "music", CommentIDs: []string{"c1"}}}, nil)
+	userRepo.EXPECT().GetByID(uint(1)).Return(&user.User{ID: 1, Username: "username"}, nil)
+
+	req := httptest.NewRequest("GET", "/api/user/username/comments?sort=top&limit=1", nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "username"})
+	w := httptest.NewRecorder()
+
+	handler.GetCommentsByUsername(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	got := &handlers.UserCommentsResponse{}
+	err := json.NewDecoder(resp.Body).Decode(got)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if len(got.Comments) != 1 || got.Next != "next" {
+		t.Fatalf("wrong response %#v", got)
+	}
+	expected := &handlers.PostLink{ID: "p1", Title: "title", Category: "music"}
+	if !reflect.DeepEqual(got.Comments[0].Post, expected) || got.Comments[0].Body != "body" {
+		t.Errorf("wrong comment %#v", got.Comments[0])
+	}
+}
+
+func TestGetCommentsByUsernameSortError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	userRepo.EXPECT().GetByUsername("username").Return(&user.User{ID: 1, Username: "username"}, nil)
+
+	req := httptest.NewRequest("GET", "/api/user/username/comments?sort=hot", nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "username"})
+	w := httptest.NewRecorder()
+
+	handler.GetCommentsByUsername(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestGetOverviewCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	communityRepo := mock.NewMockCommunityRepo(controller)
+	subscriptionRepo := mock.NewMockSubscriptionRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	notificationRepo := mock.NewMockNotificationRepo(controller)
+	hub := event.NewHub(event.DefaultBufferSize)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewPostHandler(postRepo, userRepo, commentRepo, contextLogger, communityRepo, subscriptionRepo, searcher, notificationRepo, hub)
+
+	posts := []*post.Post{
+		{ID: "p2", AuthorID: 1, Title: "newest", CreateDate: "2022-01-03T00:00:00Z", CommentIDs: make([]string, 0)},
+		{ID: "p1", AuthorID: 1, Title: "oldest", CreateDate: "2022-01-01T00:00:00Z", CommentIDs: make([]string, 0)},
+	}
+	comments := []*comment.Comment{
+		{ID: "c1", AuthorID: 1, Body: "body", CreateDate: "2022-01-02T00:00:00Z"},
+	}
+	userRepo.EXPECT().GetByUsername("username").Return(&user.User{ID: 1, Username: "username"}, nil)
+	postRepo.EXPECT().GetPageByAuthor(uint(1), post.PageOptions{Limit: 2, Sort: post.SortNew}).Return(&post.Page{Posts: posts}, nil)
+	commentRepo.EXPECT().GetPageByAuthor(uint(1), comment.PageOptions{Limit: 2, Sort: comment.SortNew}).Return(&comment.Page{Comments: comments}, nil)
+	postRepo.EXPECT().GetByCommentIDs([]string{"c1"}).Return(make([]*post.Post, 0), nil)
+	userRepo.EXPECT().GetByID(uint(1)).Return(&user.User{ID: 1, Username: "username"}, nil).Times(2)
+
+	req := httptest.NewRequest("GET", "/api/user/username/overview?limit=2", nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "username"})
+	w := httptest.NewRecorder()
+
+	handler.GetOverview(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	got := &handlers.OverviewResponse{}
+	err := json.NewDecoder(resp.Body).Decode(got)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if len(got.Items) != 2 || got.Next == "" {
+		t.Fatalf("wrong response %#v", got)
+	}
+	if got.Items[0].Kind != handlers.OverviewPost || got.Items[0].Post.ID != "p2" {
+		t.Errorf("wrong first item %#v", got.Items[0])
+	}
+	if got.Items[1].Kind != handlers.OverviewComment || got.Items[1].Comment.ID != "c1" {
+		t.Errorf("wrong second item %#v", got.Items[1])
+	}
+
+	postRepo.EXPECT().GetPageByAuthor(uint(1), gomock.Any()).DoAndReturn(func(id uint, opts post.PageOptions) (*post.Page, error) {
+		if opts.After != post.NextCursor(posts[0], post.SortNew) {
+			t.Errorf("wrong post cursor %q", opts.After)
+		}
+		return &post.Page{Posts: posts[1:]}, nil
+	})
+	commentRepo.EXPECT().GetPageByAuthor(uint(1), gomock.Any()).DoAndReturn(func(id uint, opts comment.PageOptions) (*comment.Page, error) {
+		if opts.After != comment.NextCursor(comments[0], comment.SortNew) {
+			t.Errorf("wrong comment cursor %q", opts.After)
+		}
+		return &comment.Page{Comments: make([]*comment.Comment, 0)}, nil
+	})
+	userRepo.EXPECT().GetByUsername("username").Return(&user.User{ID: 1, Username: "username"}, nil)
+	userRepo.EXPECT().GetByID(uint(1)).Return(&user.User{ID: 1, Username: "username"}, nil)
+
+	req = httptest.NewRequest("GET", "/api/user/username/overview?limit=2&after="+got.Next, nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "username"})
+	w = httptest.NewRecorder()
+
+	handler.GetOverview(w, req)
+
+	resp = w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	got = &handlers.OverviewResponse{}
+	err = json.NewDecoder(resp.Body).Decode(got)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if len(got.Items) != 1 || got.Items[0].Post.ID != "p1" || got.Next != "" {
+		t.Errorf("wrong response %#v", got)
+	}
+}
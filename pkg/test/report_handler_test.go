@@ -0,0 +1,145 @@
+package test
+
+import (
+	"bytes"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/community"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReportPostCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	reportRepo := mock.NewMockReportRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewReportHandler(reportRepo, postRepo, contextLogger)
+
+	p := &post.Post{ID: "1", Category: "golang"}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	reportRepo.EXPECT().Add(community.Target{
+		Type:      community.TargetPost,
+		ID:        p.ID,
+		PostID:    p.ID,
+		Community: "golang",
+	}, uint(2), "spam").Return(nil)
+
+	b := bytes.NewBufferString(`{"reason": "  spam "}`)
+	req := httptest.NewRequest("POST", "/api/post/1/report", b)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ReportPost(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected resp status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestReportPostAlreadyReportedError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	reportRepo := mock.NewMockReportRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewReportHandler(reportRepo, postRepo, contextLogger)
+
+	p := &post.Post{ID: "1", Category: "golang"}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+	reportRepo.EXPECT().Add(gomock.Any(), uint(2), "spam").Return(community.ErrAlreadyReported)
+
+	b := bytes.NewBufferString(`{"reason": "spam"}`)
+	req := httptest.NewRequest("POST", "/api/post/1/report", b)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ReportPost(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestReportPostEmptyReasonError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	reportRepo := mock.NewMockReportRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewReportHandler(reportRepo, postRepo, contextLogger)
+
+	b := bytes.NewBufferString(`{"reason": "   "}`)
+	req := httptest.NewRequest("POST", "/api/post/1/report", b)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ReportPost(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestReportCommentNotExistError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	reportRepo := mock.NewMockReportRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	handler := handlers.NewReportHandler(reportRepo, postRepo, contextLogger)
+
+	p := &post.Post{ID: "1", Category: "golang", CommentIDs: []string{"10"}}
+	postRepo.EXPECT().GetByID(p.ID, 0).Return(p, nil)
+
+	b := bytes.NewBufferString(`{"reason": "abuse"}`)
+	req := httptest.NewRequest("POST", "/api/post/1/11/report", b)
+	req = mux.SetURLVars(req, map[string]string{"id": p.ID, "comment_id": "11"})
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 2, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ReportComment(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
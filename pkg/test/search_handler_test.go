@@ -0,0 +1,97 @@
+package test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/search"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	searcher := mock.NewMockSearcher(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewSearchHandler(searcher, userRepo, contextLogger)
+
+	userRepo.EXPECT().GetByUsername("username").Return(&user.User{ID: 1, Username: "username"}, nil)
+	searcher.EXPECT().Search(search.Query{
+		Text:     "golang generics",
+		Type:     search.TypePost,
+		Category: "programming",
+		AuthorID: 1,
+		Limit:    10,
+	}).Return(make([]*search.Result, 0), nil)
+
+	req := httptest.NewRequest("GET", "/api/search?q=golang+generics&type=post&category=Programming&author=username&limit=10", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestSearchInvalidTypeError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	searcher := mock.NewMockSearcher(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewSearchHandler(searcher, userRepo, contextLogger)
+
+	req := httptest.NewRequest("GET", "/api/search?q=golang&type=user", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestSearchEmptyQueryError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	searcher := mock.NewMockSearcher(controller)
+	userRepo := mock.NewMockUserRepo(controller)
+	handler := handlers.NewSearchHandler(searcher, userRepo, contextLogger)
+
+	searcher.EXPECT().Search(search.Query{}).Return(nil, search.ErrEmptyQuery)
+
+	req := httptest.NewRequest("GET", "/api/search", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
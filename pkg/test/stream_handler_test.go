@@ -0,0 +1,117 @@
+package test
+
+import (
+	"bufio"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/event"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamCorrect(t *testing.T) {
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	hub := event.NewHub(event.DefaultBufferSize)
+	handler := handlers.NewStreamHandler(hub, contextLogger)
+	server := httptest.NewServer(http.HandlerFunc(handler.Stream))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/stream?post=1")
+	if err != nil {
+		t.Fatalf("unable open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("expected event stream content type, got %q", contentType)
+	}
+
+	// headers arrive only after the handler subscribed
+	hub.Publish(&event.Event{Type: event.TypePostVoted, PostID: "2"}, event.PostTopic("2"))
+	hub.Publish(&event.Event{
+		Type:   event.TypePostVoted,
+		PostID: "1",
+		Data:   &event.VoteData{Score: 2, UpvotesCount: 3, DownvotesCount: 1},
+	}, event.PostTopic("1"))
+
+	reader := bufio.NewReader(resp.Body)
+	lines := make([]string, 0, 2)
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unable read stream: %v", err)
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	expected := []string{
+		"event: post_voted",
+		`data: {"type":"post_voted","post":"1","data":{"score":2,"upvotes":3,"downvotes":1}}`,
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("wrong line %d, expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestStreamNoTopicsError(t *testing.T) {
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	handler := handlers.NewStreamHandler(event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	req := httptest.NewRequest("GET", "/api/stream", nil)
+	w := httptest.NewRecorder()
+
+	handler.Stream(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestStreamNotificationsUnauthorized(t *testing.T) {
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	handler := handlers.NewStreamHandler(event.NewHub(event.DefaultBufferSize), contextLogger)
+
+	req := httptest.NewRequest("GET", "/api/stream?all=true&notifications=true", nil)
+	w := httptest.NewRecorder()
+
+	handler.Stream(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected resp status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/api/stream?community=Bad!Name", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "user"})
+	req = req.WithContext(ctx)
+	w = httptest.NewRecorder()
+
+	handler.Stream(w, req)
+
+	resp = w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,255 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/twofactor"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const twoFactorSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestLoginTwoFactorRequired(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	u := &user.User{ID: 1, Username: "username", Password: "hash"}
+	userRepo.EXPECT().GetByUsername(u.Username).Return(u, nil)
+	hasher.EXPECT().IsPassword(u.Password, "password").Return(true)
+	twoFactorRepo.EXPECT().Get(u.ID).Return(&twofactor.Settings{UserID: u.ID, Secret: twoFactorSecret, Enabled: true}, nil)
+	challengeRepo.EXPECT().Add(u.ID, u.Username).Return("challenge", nil)
+
+	body, _ := json.Marshal(&handlers.AuthorizationRequest{Username: u.Username, Password: "password"})
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.Login(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	result := map[string]interface{}{}
+	err := json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if result["twoFactorRequired"] != true || result["challenge"] != "challenge" {
+		t.Errorf("wrong response: %v", result)
+	}
+	if _, ok := result["token"]; ok {
+		t.Errorf("token issued before the second factor")
+	}
+}
+
+func TestLoginTwoFactorCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	now := time.Now()
+	code, err := twofactor.Code(twoFactorSecret, twofactor.Step(now))
+	if err != nil {
+		t.Fatalf("unable compute code: %v", err)
+	}
+
+	challengeRepo.EXPECT().Get("challenge").Return(&twofactor.Challenge{UserID: 1, Username: "username"}, nil)
+	twoFactorRepo.EXPECT().Get(uint(1)).Return(&twofactor.Settings{UserID: 1, Secret: twoFactorSecret, Enabled: true}, nil)
+	twoFactorRepo.EXPECT().UseStep(uint(1), gomock.Any()).Return(nil)
+	challengeRepo.EXPECT().Delete("challenge").Return(nil)
+	sessionRepo.EXPECT().Add("username", uint(1), gomock.Any()).Return("token", nil)
+	refreshRepo.EXPECT().Add("username", uint(1)).Return("refreshToken", nil)
+
+	body, _ := json.Marshal(&handlers.TwoFactorLoginRequest{Challenge: "challenge", Code: code})
+	req := httptest.NewRequest("POST", "/api/login/2fa", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.LoginTwoFactor(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected json content type, got %q", resp.Header.Get("Content-Type"))
+	}
+	result := map[string]string{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if result["token"] != "token" || result["refreshToken"] != "refreshToken" {
+		t.Errorf("wrong response: %v", result)
+	}
+}
+
+func TestLoginTwoFactorInvalidCode(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	challengeRepo := mock.NewMockChallengeRepo(controller)
+	handler := handlers.NewAuthorizationHandler(userRepo, sessionRepo, contextLogger, hasher, refreshRepo, twoFactorRepo, challengeRepo, newLoginLimiter(), newRegisterLimiter())
+
+	challengeRepo.EXPECT().Get("challenge").Return(&twofactor.Challenge{UserID: 1, Username: "username"}, nil)
+	twoFactorRepo.EXPECT().Get(uint(1)).Return(&twofactor.Settings{UserID: 1, Secret: twoFactorSecret, Enabled: true}, nil)
+	twoFactorRepo.EXPECT().UseRecoveryCode(uint(1), "wrong-code").Return(twofactor.ErrInvalidCode)
+	challengeRepo.EXPECT().Fail("challenge").Return(nil)
+
+	body, _ := json.Marshal(&handlers.TwoFactorLoginRequest{Challenge: "challenge", RecoveryCode: "wrong-code"})
+	req := httptest.NewRequest("POST", "/api/login/2fa", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.LoginTwoFactor(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected resp status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestTwoFactorEnrollAndConfirm(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := twofactor.NewMemoryTwoFactorRepo()
+	handler := handlers.NewTwoFactorHandler(userRepo, twoFactorRepo, hasher, contextLogger)
+
+	req := httptest.NewRequest("POST", "/api/me/2fa/enroll", nil)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Enroll(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	enrollment := map[string]string{}
+	err := json.NewDecoder(resp.Body).Decode(&enrollment)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if enrollment["secret"] == "" || enrollment["uri"] != twofactor.URI("username", enrollment["secret"]) {
+		t.Fatalf("wrong response: %v", enrollment)
+	}
+
+	code, err := twofactor.Code(enrollment["secret"], twofactor.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("unable compute code: %v", err)
+	}
+	body, _ := json.Marshal(&handlers.TwoFactorCodeRequest{Code: code})
+	req = httptest.NewRequest("POST", "/api/me/2fa/confirm", bytes.NewReader(body))
+	req = req.WithContext(ctx)
+	w = httptest.NewRecorder()
+
+	handler.Confirm(w, req)
+
+	resp = w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	result := map[string][]string{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		t.Fatalf("unable decode response: %v", err)
+	}
+	if len(result["recoveryCodes"]) != twofactor.RecoveryCodeCount {
+		t.Errorf("wrong count of recovery codes: %d", len(result["recoveryCodes"]))
+	}
+
+	settings, err := twoFactorRepo.Get(1)
+	if err != nil {
+		t.Fatalf("unable get settings: %v", err)
+	}
+	if !settings.Enabled {
+		t.Errorf("two-factor wasn`t enabled")
+	}
+	if err = twoFactorRepo.UseRecoveryCode(1, result["recoveryCodes"][0]); err != nil {
+		t.Errorf("recovery code wasn`t saved: %v", err)
+	}
+}
+
+func TestTwoFactorDisableWrongPassword(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	handler := handlers.NewTwoFactorHandler(userRepo, twoFactorRepo, hasher, contextLogger)
+
+	u := &user.User{ID: 1, Username: "username", Password: "hash"}
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+	hasher.EXPECT().IsPassword(u.Password, "wrong").Return(false)
+
+	body, _ := json.Marshal(&handlers.TwoFactorDisableRequest{Password: "wrong"})
+	req := httptest.NewRequest("DELETE", "/api/me/2fa", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.Disable(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,384 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/vlasdash/redditclone/internal/comment"
+	"github.com/vlasdash/redditclone/internal/oauth"
+	"github.com/vlasdash/redditclone/internal/post"
+	"github.com/vlasdash/redditclone/internal/session"
+	"github.com/vlasdash/redditclone/internal/test/mock"
+	"github.com/vlasdash/redditclone/internal/twofactor"
+	"github.com/vlasdash/redditclone/internal/user"
+	"github.com/vlasdash/redditclone/pkg/handlers"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetProfileCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, nil, nil, nil, nil)
+
+	u := &user.User{ID: 1, Username: "username"}
+	userRepo.EXPECT().GetByUsername("username").Return(u, nil)
+	userRepo.EXPECT().GetProfile(u.ID).Return(&user.Profile{
+		UserID:     u.ID,
+		Bio:        "bio",
+		AvatarURL:  "https://example.com/a.png",
+		CreateDate: "2022-10-10T10:10:10Z",
+	}, nil)
+	postRepo.EXPECT().GetAuthorStats(u.ID).Return(&post.AuthorStats{Count: 2, Karma: 10}, nil)
+	commentRepo.EXPECT().GetAuthorStats(u.ID).Return(&comment.AuthorStats{Count: 5, Karma: -3}, nil)
+
+	req := httptest.NewRequest("GET", "/api/user/username/profile", nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "username"})
+	w := httptest.NewRecorder()
+
+	handler.GetProfile(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	profile := &handlers.ProfileResponse{}
+	err := json.NewDecoder(resp.Body).Decode(profile)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+
+	expected := &handlers.ProfileResponse{
+		ID:           1,
+		Username:     "username",
+		Bio:          "bio",
+		AvatarURL:    "https://example.com/a.png",
+		CreateDate:   "2022-10-10T10:10:10Z",
+		Karma:        7,
+		PostKarma:    10,
+		CommentKarma: -3,
+		PostCount:    2,
+		CommentCount: 5,
+	}
+	if !reflect.DeepEqual(profile, expected) {
+		t.Errorf("wrong result, expected %#v, got %#v", expected, profile)
+	}
+}
+
+func TestGetProfileUserNotFound(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, nil, nil, nil, nil)
+
+	userRepo.EXPECT().GetByUsername("ghost").Return(nil, user.ErrNoExist)
+
+	req := httptest.NewRequest("GET", "/api/user/ghost/profile", nil)
+	req = mux.SetURLVars(req, map[string]string{"username": "ghost"})
+	w := httptest.NewRecorder()
+
+	handler.GetProfile(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestUpdateProfileCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, nil, nil, nil, nil)
+
+	u := &user.User{ID: 1, Username: "username"}
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+	userRepo.EXPECT().GetProfile(u.ID).Return(&user.Profile{
+		UserID:     u.ID,
+		Bio:        "old bio",
+		AvatarURL:  "https://example.com/a.png",
+		CreateDate: "2022-10-10T10:10:10Z",
+	}, nil)
+	userRepo.EXPECT().UpdateProfile(&user.Profile{
+		UserID:     u.ID,
+		Bio:        "new bio",
+		AvatarURL:  "https://example.com/a.png",
+		CreateDate: "2022-10-10T10:10:10Z",
+	}).Return(nil)
+	postRepo.EXPECT().GetAuthorStats(u.ID).Return(&post.AuthorStats{}, nil)
+	commentRepo.EXPECT().GetAuthorStats(u.ID).Return(&comment.AuthorStats{}, nil)
+
+	b := bytes.NewBufferString(`{"bio": " new bio "}`)
+	req := httptest.NewRequest("PUT", "/api/me/profile", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: u.ID, Username: u.Username})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.UpdateProfile(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+		return
+	}
+
+	profile := &handlers.ProfileResponse{}
+	err := json.NewDecoder(resp.Body).Decode(profile)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+	if profile.Bio != "new bio" || profile.AvatarURL != "https://example.com/a.png" {
+		t.Errorf("wrong result, got %#v", profile)
+	}
+}
+
+func TestUpdateProfileInvalidAvatar(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, nil, nil, nil, nil)
+
+	u := &user.User{ID: 1, Username: "username"}
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+	userRepo.EXPECT().GetProfile(u.ID).Return(&user.Profile{UserID: u.ID}, nil)
+
+	b := bytes.NewBufferString(`{"avatarUrl": "javascript:alert(1)"}`)
+	req := httptest.NewRequest("PUT", "/api/me/profile", b)
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: u.ID, Username: u.Username})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.UpdateProfile(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestChangePasswordCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, nil, nil, nil, nil)
+
+	u := &user.User{ID: 1, Username: "username", Password: "old hash"}
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+	hasher.EXPECT().IsPassword("old hash", "old").Return(true)
+	hasher.EXPECT().GetHashPassword("new").Return("new hash", nil)
+	userRepo.EXPECT().UpdatePassword(u.ID, "new hash").Return(nil)
+	sessionRepo.EXPECT().DeleteOthers(u.ID, "token").Return(nil)
+	refreshRepo.EXPECT().DeleteAll(u.ID).Return(nil)
+	refreshRepo.EXPECT().Add(u.Username, u.ID).Return("refresh", nil)
+
+	body, _ := json.Marshal(&handlers.PasswordChangeRequest{OldPassword: "old", NewPassword: "new"})
+	req := httptest.NewRequest("POST", "/api/me/password", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ChangePassword(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	got := make(map[string]string)
+	err := json.NewDecoder(resp.Body).Decode(&got)
+	if err != nil {
+		t.Fatalf("unable unmarshal json: %v", err)
+	}
+	if got["refreshToken"] != "refresh" {
+		t.Errorf("wrong result, got %#v", got)
+	}
+}
+
+func TestChangePasswordWrongPassword(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, nil, nil, nil, nil)
+
+	u := &user.User{ID: 1, Username: "username", Password: "old hash"}
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+	hasher.EXPECT().IsPassword("old hash", "guess").Return(false)
+
+	body, _ := json.Marshal(&handlers.PasswordChangeRequest{OldPassword: "guess", NewPassword: "new"})
+	req := httptest.NewRequest("POST", "/api/me/password", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username", Token: "token"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.ChangePassword(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestDeleteAccountCorrect(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	apiKeyRepo := mock.NewMockAPIKeyRepo(controller)
+	twoFactorRepo := mock.NewMockTwoFactorRepo(controller)
+	clientRepo := mock.NewMockClientRepo(controller)
+	tokenRepo := mock.NewMockTokenRepo(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, apiKeyRepo, twoFactorRepo, clientRepo, tokenRepo)
+
+	u := &user.User{ID: 1, Username: "username", Password: "hash"}
+	userRepo.EXPECT().GetByID(u.ID).Return(u, nil)
+	hasher.EXPECT().IsPassword("hash", "password").Return(true)
+	postRepo.EXPECT().Anonymize(u.ID).Return(nil)
+	commentRepo.EXPECT().Anonymize(u.ID).Return(nil)
+	searcher.EXPECT().Anonymize(u.ID).Return(nil)
+	sessionRepo.EXPECT().DeleteAll(u.ID).Return(nil)
+	refreshRepo.EXPECT().DeleteAll(u.ID).Return(nil)
+	apiKeyRepo.EXPECT().DeleteAll(u.ID).Return(nil)
+	tokenRepo.EXPECT().DeleteByUser(u.ID).Return(nil)
+	clientRepo.EXPECT().GetByOwner(u.ID).Return([]*oauth.Client{{ID: "client"}}, nil)
+	clientRepo.EXPECT().Delete(u.ID, "client").Return(nil)
+	tokenRepo.EXPECT().DeleteByClient("client").Return(nil)
+	twoFactorRepo.EXPECT().Delete(u.ID).Return(twofactor.ErrNotEnrolled)
+	userRepo.EXPECT().Delete(u.ID).Return(nil)
+
+	body, _ := json.Marshal(&handlers.AccountDeleteRequest{Password: "password"})
+	req := httptest.NewRequest("DELETE", "/api/me", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.DeleteAccount(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected resp status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestUpdateEmailTaken(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	contextLogger := logrus.WithFields(logrus.Fields{
+		"logger": "LOGRUS",
+	})
+	contextLogger.Logger.Out = ioutil.Discard
+
+	userRepo := mock.NewMockUserRepo(controller)
+	postRepo := mock.NewMockPostRepo(controller)
+	commentRepo := mock.NewMockCommentRepo(controller)
+	searcher := mock.NewMockSearcher(controller)
+	sessionRepo := mock.NewMockSessionRepo(controller)
+	refreshRepo := mock.NewMockRefreshRepo(controller)
+	hasher := mock.NewMockPasswordHasher(controller)
+	handler := handlers.NewUserHandler(userRepo, postRepo, commentRepo, searcher, sessionRepo, refreshRepo, hasher, contextLogger, nil, nil, nil, nil)
+
+	userRepo.EXPECT().UpdateEmail(uint(1), "user@example.com").Return(user.ErrEmailTaken)
+
+	body, _ := json.Marshal(&handlers.EmailRequest{Email: " user@example.com "})
+	req := httptest.NewRequest("PUT", "/api/me/email", bytes.NewReader(body))
+	ctx := session.CreateContextWithSession(req.Context(), &session.Session{UserID: 1, Username: "username"})
+	req = req.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	handler.UpdateEmail(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected resp status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}